@@ -0,0 +1,144 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"social-network/backend/database/models"
+)
+
+// ErrSelfBlock is returned when a user tries to block or mute themselves.
+var ErrSelfBlock = errors.New("cannot block or mute yourself")
+
+// NotBlockedCondition filters out the users blocking or blocked by the viewer.
+// column holds the user ID to check, the viewer ID must be bound twice.
+func NotBlockedCondition(column string) string {
+	return `NOT EXISTS (
+		SELECT 1 FROM user_blocks ub
+		WHERE (ub.blocker_id = ? AND ub.blocked_id = ` + column + `)
+			OR (ub.blocker_id = ` + column + ` AND ub.blocked_id = ?)
+	)`
+}
+
+// NotMutedCondition filters out the users muted by the viewer.
+// column holds the user ID to check, the viewer ID must be bound once.
+func NotMutedCondition(column string) string {
+	return `NOT EXISTS (
+		SELECT 1 FROM user_mutes um WHERE um.muter_id = ? AND um.muted_id = ` + column + `
+	)`
+}
+
+// BlockService handles the blocks and mutes between users.
+type BlockService struct {
+	db *sql.DB
+}
+
+// NewBlockService creates a new BlockService.
+func NewBlockService(db *sql.DB) *BlockService {
+	return &BlockService{db: db}
+}
+
+// Block blocks a user and removes the follows, accepted or pending, both ways.
+func (s *BlockService) Block(blockerID, blockedID int64) error {
+	if blockerID == blockedID {
+		return ErrSelfBlock
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO user_blocks (blocker_id, blocked_id) VALUES (?, ?)
+	`, blockerID, blockedID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM followers
+		WHERE (follower_id = ? AND followed_id = ?) OR (follower_id = ? AND followed_id = ?)
+	`, blockerID, blockedID, blockedID, blockerID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Unblock removes a block. Removed follows are not restored.
+func (s *BlockService) Unblock(blockerID, blockedID int64) error {
+	_, err := s.db.Exec(`DELETE FROM user_blocks WHERE blocker_id = ? AND blocked_id = ?`, blockerID, blockedID)
+	return err
+}
+
+// IsBlocked reports whether either user blocked the other.
+func (s *BlockService) IsBlocked(userID, otherID int64) bool {
+	if userID == 0 || otherID == 0 || userID == otherID {
+		return false
+	}
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM user_blocks
+		WHERE (blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)
+	`, userID, otherID, otherID, userID).Scan(&count)
+	return err == nil && count > 0
+}
+
+// Mute hides the posts of a user from the muter's feed only.
+func (s *BlockService) Mute(muterID, mutedID int64) error {
+	if muterID == mutedID {
+		return ErrSelfBlock
+	}
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO user_mutes (muter_id, muted_id) VALUES (?, ?)`, muterID, mutedID)
+	return err
+}
+
+// Unmute removes a mute.
+func (s *BlockService) Unmute(muterID, mutedID int64) error {
+	_, err := s.db.Exec(`DELETE FROM user_mutes WHERE muter_id = ? AND muted_id = ?`, muterID, mutedID)
+	return err
+}
+
+// IsMuted reports whether the muter muted this user.
+func (s *BlockService) IsMuted(muterID, mutedID int64) bool {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM user_mutes WHERE muter_id = ? AND muted_id = ?
+	`, muterID, mutedID).Scan(&count)
+	return err == nil && count > 0
+}
+
+// ListBlocked lists the users blocked by a user, most recent first.
+func (s *BlockService) ListBlocked(blockerID int64) ([]*models.User, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, u.avatar_path
+		FROM user_blocks ub
+		JOIN users u ON u.id = ub.blocked_id
+		WHERE ub.blocker_id = ?
+		ORDER BY ub.created_at DESC, ub.id DESC
+	`, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAudienceUsers(rows)
+}
+
+// ListMuted lists the users muted by a user, most recent first.
+func (s *BlockService) ListMuted(muterID int64) ([]*models.User, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, u.avatar_path
+		FROM user_mutes um
+		JOIN users u ON u.id = um.muted_id
+		WHERE um.muter_id = ?
+		ORDER BY um.created_at DESC, um.id DESC
+	`, muterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAudienceUsers(rows)
+}
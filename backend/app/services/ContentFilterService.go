@@ -0,0 +1,164 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Kinds of content going through the filters, matching the report target types.
+const (
+	ContentPost         = TargetPost
+	ContentComment      = TargetComment
+	ContentMessage      = TargetMessage
+	ContentGroupMessage = TargetGroupMessage
+)
+
+// FilterAction is the decision of a filter, ordered by severity.
+type FilterAction int
+
+const (
+	FilterAllow FilterAction = iota
+	FilterMask
+	FilterFlag
+	FilterReject
+)
+
+// FilterInput is the content submitted by a user.
+type FilterInput struct {
+	UserID   int64
+	Kind     string
+	Content  string
+	EditedID int64 // ID of the edited content, 0 for a new one
+}
+
+// FilterResult is returned by a single filter. Content is only read when the
+// action is FilterMask.
+type FilterResult struct {
+	Action  FilterAction
+	Reason  string
+	Content string
+}
+
+// ContentFilter inspects content before it is written.
+type ContentFilter interface {
+	Name() string
+	Check(input *FilterInput) FilterResult
+}
+
+// ContentRejectedError is returned when a filter rejects the content.
+type ContentRejectedError struct {
+	Filter string
+	Reason string
+}
+
+func (e *ContentRejectedError) Error() string {
+	return fmt.Sprintf("content rejected by %s: %s", e.Filter, e.Reason)
+}
+
+// FilterOutcome is the result of the whole chain for an accepted content.
+type FilterOutcome struct {
+	Content string
+	Flagged bool
+	Reasons []string
+}
+
+// contentTables maps a content kind to its table and author column.
+var contentTables = map[string][2]string{
+	ContentPost:         {"posts", "user_id"},
+	ContentComment:      {"comments", "user_id"},
+	ContentMessage:      {"messages", "sender_id"},
+	ContentGroupMessage: {"group_messages", "user_id"},
+}
+
+// ContentFilterService runs every write path through a chain of filters.
+type ContentFilterService struct {
+	db      *sql.DB
+	filters []ContentFilter
+}
+
+// NewContentFilterService creates a new ContentFilterService running the
+// filters in the given order.
+func NewContentFilterService(db *sql.DB, filters ...ContentFilter) *ContentFilterService {
+	return &ContentFilterService{db: db, filters: filters}
+}
+
+// Use appends a filter at the end of the chain.
+func (s *ContentFilterService) Use(filter ContentFilter) {
+	s.filters = append(s.filters, filter)
+}
+
+// Run passes the content through the chain. Masking filters rewrite the
+// content seen by the next ones, the first rejection stops the chain and is
+// returned as a *ContentRejectedError.
+func (s *ContentFilterService) Run(userID int64, kind string, content string) (*FilterOutcome, error) {
+	return s.run(FilterInput{UserID: userID, Kind: kind, Content: content})
+}
+
+// RunEdit passes the new content of an existing one through the chain. The
+// edited content is not compared with itself and does not count as written.
+func (s *ContentFilterService) RunEdit(userID int64, kind string, id int64, content string) (*FilterOutcome, error) {
+	return s.run(FilterInput{UserID: userID, Kind: kind, Content: content, EditedID: id})
+}
+
+func (s *ContentFilterService) run(input FilterInput) (*FilterOutcome, error) {
+	outcome := &FilterOutcome{Content: input.Content}
+	if s == nil {
+		return outcome, nil
+	}
+
+	for _, filter := range s.filters {
+		input.Content = outcome.Content
+		result := filter.Check(&input)
+
+		switch result.Action {
+		case FilterReject:
+			return nil, &ContentRejectedError{Filter: filter.Name(), Reason: result.Reason}
+		case FilterFlag:
+			outcome.Flagged = true
+			outcome.Reasons = append(outcome.Reasons, filter.Name()+": "+result.Reason)
+		case FilterMask:
+			outcome.Content = result.Content
+		}
+	}
+
+	return outcome, nil
+}
+
+// FlagForReview puts a flagged content in the moderation queue once it has
+// been written. Automatic reports have no reporter.
+func (s *ContentFilterService) FlagForReview(kind string, targetID int64, outcome *FilterOutcome) {
+	if s == nil || outcome == nil || !outcome.Flagged {
+		return
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO reports (reporter_id, target_type, target_id, reason, details, status, created_at)
+		VALUES (NULL, ?, ?, 'content_filter', ?, 'pending', ?)
+	`, kind, targetID, truncate(strings.Join(outcome.Reasons, "; "), 1000), time.Now())
+	if err != nil {
+		log.Printf("Error flagging %s %d for review: %v", kind, targetID, err)
+	}
+}
+
+// countRecent counts the contents of a kind written by a user since a date,
+// leaving out the one with excludeID.
+func countRecent(db *sql.DB, kind string, userID int64, since time.Time, content *string, excludeID int64) (int, error) {
+	table, ok := contentTables[kind]
+	if !ok {
+		return 0, ErrInvalidTargetType
+	}
+
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = ? AND created_at > ? AND id != ?`, table[0], table[1])
+	args := []any{userID, since, excludeID}
+	if content != nil {
+		query += ` AND lower(trim(content)) = ?`
+		args = append(args, *content)
+	}
+
+	var count int
+	err := db.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
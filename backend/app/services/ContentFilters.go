@@ -0,0 +1,191 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseFilterAction reads an action from the configuration, masking by default.
+func ParseFilterAction(value string) FilterAction {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "reject":
+		return FilterReject
+	case "flag":
+		return FilterFlag
+	default:
+		return FilterMask
+	}
+}
+
+// --- Banned words ---
+
+// BannedWordFilter masks, flags or rejects content containing a banned word.
+type BannedWordFilter struct {
+	pattern *regexp.Regexp
+	action  FilterAction
+}
+
+// NewBannedWordFilter creates a filter matching whole words, case insensitive.
+// Empty entries are ignored. Word boundaries are checked on letters so
+// accented words are handled, which regexp's \b does not do.
+func NewBannedWordFilter(words []string, action FilterAction) *BannedWordFilter {
+	var quoted []string
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+
+	filter := &BannedWordFilter{action: action}
+	if len(quoted) > 0 {
+		filter.pattern = regexp.MustCompile(`(?i)(?:` + strings.Join(quoted, "|") + `)`)
+	}
+	return filter
+}
+
+func (f *BannedWordFilter) Name() string { return "banned_words" }
+
+func (f *BannedWordFilter) Check(input *FilterInput) FilterResult {
+	matches := f.wholeWords(input.Content)
+	if len(matches) == 0 {
+		return FilterResult{Action: FilterAllow}
+	}
+
+	if f.action != FilterMask {
+		return FilterResult{Action: f.action, Reason: "contains a banned word"}
+	}
+
+	var masked strings.Builder
+	last := 0
+	for _, match := range matches {
+		masked.WriteString(input.Content[last:match[0]])
+		masked.WriteString(strings.Repeat("*", utf8.RuneCountInString(input.Content[match[0]:match[1]])))
+		last = match[1]
+	}
+	masked.WriteString(input.Content[last:])
+
+	return FilterResult{Action: FilterMask, Reason: "banned word masked", Content: masked.String()}
+}
+
+// wholeWords returns the matches not glued to another letter or digit.
+func (f *BannedWordFilter) wholeWords(content string) [][]int {
+	if f.pattern == nil {
+		return nil
+	}
+
+	isWordRune := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+	var matches [][]int
+	for _, match := range f.pattern.FindAllStringIndex(content, -1) {
+		before, _ := utf8.DecodeLastRuneInString(content[:match[0]])
+		after, _ := utf8.DecodeRuneInString(content[match[1]:])
+		if match[0] > 0 && isWordRune(before) || match[1] < len(content) && isWordRune(after) {
+			continue
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// --- Link flooding ---
+
+// LinkFloodFilter rejects content carrying too many links.
+type LinkFloodFilter struct {
+	maxLinks int
+}
+
+// NewLinkFloodFilter creates a filter allowing at most maxLinks links.
+func NewLinkFloodFilter(maxLinks int) *LinkFloodFilter {
+	return &LinkFloodFilter{maxLinks: maxLinks}
+}
+
+func (f *LinkFloodFilter) Name() string { return "link_flood" }
+
+func (f *LinkFloodFilter) Check(input *FilterInput) FilterResult {
+	if links := len(urlRegex.FindAllString(input.Content, -1)); links > f.maxLinks {
+		return FilterResult{Action: FilterReject, Reason: fmt.Sprintf("too many links (%d, max %d)", links, f.maxLinks)}
+	}
+	return FilterResult{Action: FilterAllow}
+}
+
+// --- Duplicates ---
+
+// DuplicateFilter rejects a content already written by the same user recently.
+type DuplicateFilter struct {
+	db     *sql.DB
+	window time.Duration
+}
+
+// NewDuplicateFilter creates a filter looking back over the given window.
+func NewDuplicateFilter(db *sql.DB, window time.Duration) *DuplicateFilter {
+	return &DuplicateFilter{db: db, window: window}
+}
+
+func (f *DuplicateFilter) Name() string { return "duplicate" }
+
+func (f *DuplicateFilter) Check(input *FilterInput) FilterResult {
+	normalized := strings.ToLower(strings.TrimSpace(input.Content))
+	if normalized == "" {
+		return FilterResult{Action: FilterAllow}
+	}
+
+	count, err := countRecent(f.db, input.Kind, input.UserID, time.Now().Add(-f.window), &normalized, input.EditedID)
+	if err != nil {
+		log.Printf("Duplicate filter: %v", err)
+		return FilterResult{Action: FilterAllow}
+	}
+	if count > 0 {
+		return FilterResult{Action: FilterReject, Reason: "duplicate content"}
+	}
+	return FilterResult{Action: FilterAllow}
+}
+
+// --- Posting velocity ---
+
+// DefaultVelocityLimits is the number of contents of each kind a user can
+// write per minute.
+var DefaultVelocityLimits = map[string]int{
+	ContentPost:         5,
+	ContentComment:      15,
+	ContentMessage:      30,
+	ContentGroupMessage: 30,
+}
+
+// VelocityFilter rejects content once a user wrote too much in a short time.
+type VelocityFilter struct {
+	db     *sql.DB
+	window time.Duration
+	limits map[string]int
+}
+
+// NewVelocityFilter creates a filter allowing limits[kind] contents per window.
+// Kinds missing from limits are not limited.
+func NewVelocityFilter(db *sql.DB, window time.Duration, limits map[string]int) *VelocityFilter {
+	return &VelocityFilter{db: db, window: window, limits: limits}
+}
+
+func (f *VelocityFilter) Name() string { return "velocity" }
+
+func (f *VelocityFilter) Check(input *FilterInput) FilterResult {
+	// Une modification n'ajoute aucun contenu
+	limit, ok := f.limits[input.Kind]
+	if !ok || limit <= 0 || input.EditedID != 0 {
+		return FilterResult{Action: FilterAllow}
+	}
+
+	count, err := countRecent(f.db, input.Kind, input.UserID, time.Now().Add(-f.window), nil, 0)
+	if err != nil {
+		log.Printf("Velocity filter: %v", err)
+		return FilterResult{Action: FilterAllow}
+	}
+	if count >= limit {
+		return FilterResult{Action: FilterReject, Reason: "posting too fast, slow down"}
+	}
+	return FilterResult{Action: FilterAllow}
+}
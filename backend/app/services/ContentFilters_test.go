@@ -0,0 +1,177 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseFilterAction(t *testing.T) {
+	for value, want := range map[string]FilterAction{
+		"reject":  FilterReject,
+		" Flag ":  FilterFlag,
+		"mask":    FilterMask,
+		"":        FilterMask,
+		"unknown": FilterMask,
+	} {
+		if got := ParseFilterAction(value); got != want {
+			t.Errorf("ParseFilterAction(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestBannedWordFilter(t *testing.T) {
+	words := []string{"spam", " ", "écu", "a.b"}
+	for _, tc := range []struct {
+		action  FilterAction
+		content string
+		want    FilterAction
+		masked  string
+	}{
+		{FilterMask, "no problem here", FilterAllow, ""},
+		{FilterMask, "buy SPAM now", FilterMask, "buy **** now"},
+		{FilterMask, "spam, spam!", FilterMask, "****, ****!"},
+		{FilterMask, "un écu d'or", FilterMask, "un *** d'or"},
+		{FilterMask, "a.b but not axb", FilterMask, "*** but not axb"},
+		// Les mots ne sont cherchés qu'entiers, lettres accentuées comprises
+		{FilterMask, "spammer", FilterAllow, ""},
+		{FilterMask, "spam2", FilterAllow, ""},
+		{FilterMask, "écus", FilterAllow, ""},
+		{FilterMask, "décu", FilterAllow, ""},
+		{FilterFlag, "buy spam now", FilterFlag, ""},
+		{FilterReject, "buy spam now", FilterReject, ""},
+		{FilterReject, "spammer", FilterAllow, ""},
+	} {
+		result := NewBannedWordFilter(words, tc.action).Check(&FilterInput{Content: tc.content})
+		if result.Action != tc.want {
+			t.Errorf("%v on %q: action %v, want %v", tc.action, tc.content, result.Action, tc.want)
+		}
+		if tc.want == FilterMask && result.Content != tc.masked {
+			t.Errorf("%v on %q: masked %q, want %q", tc.action, tc.content, result.Content, tc.masked)
+		}
+	}
+
+	// Sans mot interdit, le filtre laisse tout passer
+	if result := NewBannedWordFilter([]string{"", " "}, FilterReject).Check(&FilterInput{Content: "anything"}); result.Action != FilterAllow {
+		t.Errorf("empty word list: action %v, want allow", result.Action)
+	}
+}
+
+func TestLinkFloodFilter(t *testing.T) {
+	for _, tc := range []struct {
+		max     int
+		content string
+		want    FilterAction
+	}{
+		{2, "no link", FilterAllow},
+		{2, "http://a.example https://b.example", FilterAllow},
+		{2, "http://a.example https://b.example http://c.example", FilterReject},
+		{2, "a.example www.b.example c.example", FilterAllow},
+		{0, "https://a.example", FilterReject},
+		{0, "plain text", FilterAllow},
+	} {
+		if got := NewLinkFloodFilter(tc.max).Check(&FilterInput{Content: tc.content}).Action; got != tc.want {
+			t.Errorf("max %d on %q: action %v, want %v", tc.max, tc.content, got, tc.want)
+		}
+	}
+}
+
+// insertTestPosts writes count posts of a user with the given content and age.
+func insertTestPosts(t *testing.T, db *sql.DB, userID int64, content string, age time.Duration, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		mustExec(t, db, `
+			INSERT INTO posts (user_id, content, privacy_type, created_at) VALUES (?, ?, 0, ?)
+		`, userID, content, time.Now().Add(-age))
+	}
+}
+
+func TestVelocityFilter(t *testing.T) {
+	limits := map[string]int{ContentPost: 3, ContentComment: 0}
+	for _, tc := range []struct {
+		name   string
+		recent int // posts of the user within the window
+		old    int // posts of the user before the window
+		others int // recent posts of another user
+		input  FilterInput
+		want   FilterAction
+	}{
+		{"under the limit", 2, 0, 0, FilterInput{Kind: ContentPost}, FilterAllow},
+		{"at the limit", 3, 0, 0, FilterInput{Kind: ContentPost}, FilterReject},
+		{"over the limit", 4, 0, 0, FilterInput{Kind: ContentPost}, FilterReject},
+		{"old posts not counted", 2, 5, 0, FilterInput{Kind: ContentPost}, FilterAllow},
+		{"other users not counted", 2, 0, 5, FilterInput{Kind: ContentPost}, FilterAllow},
+		{"edits not limited", 3, 0, 0, FilterInput{Kind: ContentPost, EditedID: 1}, FilterAllow},
+		{"zero limit disables", 0, 0, 0, FilterInput{Kind: ContentComment}, FilterAllow},
+		{"missing kind not limited", 0, 0, 0, FilterInput{Kind: ContentMessage}, FilterAllow},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newTestDB(t)
+			insertTestUser(t, db, 1, "writer")
+			insertTestUser(t, db, 2, "other")
+			insertTestPosts(t, db, 1, "recent", 10*time.Second, tc.recent)
+			insertTestPosts(t, db, 1, "old", 2*time.Minute, tc.old)
+			insertTestPosts(t, db, 2, "other", 10*time.Second, tc.others)
+
+			input := tc.input
+			input.UserID = 1
+			if got := NewVelocityFilter(db, time.Minute, limits).Check(&input).Action; got != tc.want {
+				t.Errorf("action %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateFilter(t *testing.T) {
+	db := newTestDB(t)
+	insertTestUser(t, db, 1, "writer")
+	insertTestUser(t, db, 2, "other")
+	insertTestPosts(t, db, 1, "Hello world", time.Minute, 1) // id 1
+	insertTestPosts(t, db, 1, "Old news", time.Hour, 1)      // id 2
+	insertTestPosts(t, db, 2, "Someone else", time.Minute, 1)
+
+	filter := NewDuplicateFilter(db, 10*time.Minute)
+	for _, tc := range []struct {
+		name  string
+		input FilterInput
+		want  FilterAction
+	}{
+		{"same content", FilterInput{UserID: 1, Content: "Hello world"}, FilterReject},
+		{"case and spaces ignored", FilterInput{UserID: 1, Content: "  hello WORLD "}, FilterReject},
+		{"other content", FilterInput{UserID: 1, Content: "Hello there"}, FilterAllow},
+		{"outside the window", FilterInput{UserID: 1, Content: "Old news"}, FilterAllow},
+		{"other user", FilterInput{UserID: 1, Content: "Someone else"}, FilterAllow},
+		{"edit of itself", FilterInput{UserID: 1, Content: "Hello world", EditedID: 1}, FilterAllow},
+		{"edit into another", FilterInput{UserID: 1, Content: "Hello world", EditedID: 2}, FilterReject},
+		{"empty content", FilterInput{UserID: 1, Content: "   "}, FilterAllow},
+	} {
+		tc.input.Kind = ContentPost
+		if got := filter.Check(&tc.input).Action; got != tc.want {
+			t.Errorf("%s: action %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestContentFilterChain(t *testing.T) {
+	s := NewContentFilterService(nil,
+		NewBannedWordFilter([]string{"spam"}, FilterMask),
+		NewBannedWordFilter([]string{"scam"}, FilterFlag),
+		NewLinkFloodFilter(1),
+	)
+
+	// Le masquage est vu par les filtres suivants, le signalement s'accumule
+	outcome, err := s.Run(1, ContentPost, "spam and scam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Content != "**** and scam" || !outcome.Flagged || len(outcome.Reasons) != 1 {
+		t.Errorf("outcome = %+v, want masked and flagged once", outcome)
+	}
+
+	_, err = s.Run(1, ContentPost, "http://a.example http://b.example")
+	var rejected *ContentRejectedError
+	if !errors.As(err, &rejected) || rejected.Filter != "link_flood" {
+		t.Errorf("error = %v, want a rejection by link_flood", err)
+	}
+}
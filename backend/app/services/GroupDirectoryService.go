@@ -0,0 +1,262 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"social-network/backend/database/models"
+)
+
+// Sort orders of the discovery directory.
+const (
+	DirectorySortActivity = "activity"
+	DirectorySortSize     = "size"
+	DirectorySortNewest   = "newest"
+)
+
+var ErrInvalidDirectorySort = errors.New("invalid sort, expected activity, size or newest")
+
+// directoryOrders maps the sort orders to their ORDER BY clause.
+var directoryOrders = map[string]string{
+	DirectorySortActivity: "last_activity DESC, g.id DESC",
+	DirectorySortSize:     "member_count DESC, last_activity DESC, g.id DESC",
+	DirectorySortNewest:   "g.created_at DESC, g.id DESC",
+}
+
+// directoryStats computes the member count and the last activity of the
+// groups. Activity is the latest post, message or event, as a Unix time.
+const directoryStats = `
+	WITH members AS (
+		SELECT group_id, COUNT(*) AS n FROM group_members WHERE accepted = 1 GROUP BY group_id
+	), activity AS (
+		SELECT group_id, MAX(CAST(strftime('%s', created_at) AS INTEGER)) AS at FROM (
+			SELECT group_id, created_at FROM posts WHERE group_id IS NOT NULL
+			UNION ALL SELECT group_id, created_at FROM group_messages
+			UNION ALL SELECT group_id, created_at FROM events
+		) GROUP BY group_id
+	)
+`
+
+// directoryColumns selects a GroupDirectoryEntry, the viewer ID being the
+// first argument. directoryJoins follows it, after any extra column.
+const directoryColumns = `
+	SELECT g.id, g.title, COALESCE(g.description, ''), g.visibility,
+		COALESCE(g.category, ''), COALESCE(g.cover_path, ''), COALESCE(m.n, 0) AS member_count,
+		MAX(CAST(strftime('%s', g.created_at) AS INTEGER), COALESCE(a.at, 0)) AS last_activity,
+		EXISTS (
+			SELECT 1 FROM group_members gm WHERE gm.group_id = g.id AND gm.user_id = ? AND gm.accepted = 1
+		)
+`
+
+const directoryJoins = `
+	FROM groups g
+	LEFT JOIN members m ON m.group_id = g.id
+	LEFT JOIN activity a ON a.group_id = g.id
+`
+
+// directoryCondition keeps the live groups the user may find: not deleted
+// nor archived, not secret unless they are a member, and not banning them.
+// It takes the user ID twice.
+const directoryCondition = `
+	g.deleted_at IS NULL AND g.archived_at IS NULL
+	AND (g.visibility != 'secret' OR EXISTS (
+		SELECT 1 FROM group_members gm WHERE gm.group_id = g.id AND gm.user_id = ? AND gm.accepted = 1
+	))
+	AND NOT EXISTS (SELECT 1 FROM group_bans gb WHERE gb.group_id = g.id AND gb.user_id = ?)
+`
+
+// DirectoryQuery filters and sorts a page of the discovery directory. Zero
+// values match every group.
+type DirectoryQuery struct {
+	Search   string
+	Category string
+	Tag      string
+	Sort     string // DirectorySortActivity by default
+	Limit    int
+	Offset   int
+}
+
+// GroupDirectoryService lists the groups users can discover and join.
+type GroupDirectoryService struct {
+	db     *sql.DB
+	Groups *GroupService
+}
+
+// NewGroupDirectoryService creates a new GroupDirectoryService.
+func NewGroupDirectoryService(db *sql.DB, gs *GroupService) *GroupDirectoryService {
+	return &GroupDirectoryService{db: db, Groups: gs}
+}
+
+func scanDirectoryEntry(scanner interface{ Scan(...any) error }, extra ...any) (*models.GroupDirectoryEntry, error) {
+	entry := &models.GroupDirectoryEntry{Tags: []string{}}
+	var lastActivity int64
+	dest := []any{
+		&entry.ID,
+		&entry.Title,
+		&entry.Description,
+		&entry.Visibility,
+		&entry.Category,
+		&entry.CoverPath,
+		&entry.MemberCount,
+		&lastActivity,
+		&entry.IsMember,
+	}
+	if err := scanner.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	entry.LastActivityAt = time.Unix(lastActivity, 0).UTC()
+	return entry, nil
+}
+
+// attachTags loads the tags of the listed groups.
+func (s *GroupDirectoryService) attachTags(entries []*models.GroupDirectoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.GroupDirectoryEntry, len(entries))
+	args := make([]any, 0, len(entries))
+	for _, entry := range entries {
+		byID[entry.ID] = entry
+		args = append(args, entry.ID)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT group_id, tag FROM group_tags
+		WHERE group_id IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+		ORDER BY tag
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupID int64
+		var tag string
+		if err := rows.Scan(&groupID, &tag); err != nil {
+			return err
+		}
+		byID[groupID].Tags = append(byID[groupID].Tags, tag)
+	}
+	return rows.Err()
+}
+
+// likeEscaper escapes the LIKE wildcards of a search, used with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns the LIKE pattern matching the search anywhere.
+func containsPattern(search string) string {
+	return "%" + likeEscaper.Replace(search) + "%"
+}
+
+// Discover returns a page of the groups the user can find, filtered by
+// title or description, category and tag.
+func (s *GroupDirectoryService) Discover(userID int64, query DirectoryQuery) (*models.GroupDirectoryPage, error) {
+	if query.Sort == "" {
+		query.Sort = DirectorySortActivity
+	}
+	order, ok := directoryOrders[query.Sort]
+	if !ok {
+		return nil, ErrInvalidDirectorySort
+	}
+
+	condition := directoryCondition
+	args := []any{userID, userID}
+	if query.Search != "" {
+		condition += ` AND (g.title LIKE ? ESCAPE '\' OR g.description LIKE ? ESCAPE '\')`
+		pattern := containsPattern(query.Search)
+		args = append(args, pattern, pattern)
+	}
+	if query.Category != "" {
+		condition += " AND g.category = ?"
+		args = append(args, query.Category)
+	}
+	if query.Tag != "" {
+		tags, err := NormalizeGroupTags([]string{query.Tag})
+		if err != nil {
+			return nil, err
+		}
+		condition += " AND EXISTS (SELECT 1 FROM group_tags t WHERE t.group_id = g.id AND t.tag = ?)"
+		args = append(args, tags[0])
+	}
+
+	page := &models.GroupDirectoryPage{Groups: []*models.GroupDirectoryEntry{}}
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM groups g WHERE `+condition, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(directoryStats+directoryColumns+directoryJoins+`
+		WHERE `+condition+`
+		ORDER BY `+order+`
+		LIMIT ? OFFSET ?
+	`, append(append([]any{userID}, args...), query.Limit, query.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanDirectoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Groups = append(page.Groups, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.attachTags(page.Groups); err != nil {
+		return nil, err
+	}
+
+	page.HasMore = int64(query.Offset+len(page.Groups)) < page.Total
+	return page, nil
+}
+
+// Recommend ranks the groups the user's friends, the users they follow and
+// who follow them back, belong to. Groups the user is already in and secret
+// groups are left out.
+func (s *GroupDirectoryService) Recommend(userID int64, limit int) ([]*models.GroupDirectoryEntry, error) {
+	rows, err := s.db.Query(directoryStats+`,
+		friends AS (
+			SELECT f1.followed_id AS user_id
+			FROM followers f1
+			JOIN followers f2 ON f2.follower_id = f1.followed_id AND f2.followed_id = f1.follower_id AND f2.accepted = 1
+			WHERE f1.follower_id = ? AND f1.accepted = 1
+		), friend_groups AS (
+			SELECT gm.group_id, COUNT(*) AS n
+			FROM group_members gm JOIN friends f ON f.user_id = gm.user_id
+			WHERE gm.accepted = 1
+			GROUP BY gm.group_id
+		)
+	`+directoryColumns+`, fg.n`+directoryJoins+`
+		JOIN friend_groups fg ON fg.group_id = g.id
+		WHERE `+directoryCondition+` AND g.visibility != 'secret'
+		AND NOT EXISTS (
+			SELECT 1 FROM group_members gm WHERE gm.group_id = g.id AND gm.user_id = ? AND gm.accepted = 1
+		)
+		ORDER BY fg.n DESC, member_count DESC, last_activity DESC, g.id DESC
+		LIMIT ?
+	`, userID, userID, userID, userID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.GroupDirectoryEntry{}
+	for rows.Next() {
+		var friendCount int64
+		entry, err := scanDirectoryEntry(rows, &friendCount)
+		if err != nil {
+			return nil, err
+		}
+		entry.FriendCount = friendCount
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, s.attachTags(entries)
+}
@@ -0,0 +1,1559 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"social-network/backend/database/models"
+)
+
+// Group member roles, from the most to the least privileged.
+const (
+	GroupRoleOwner     = "owner"
+	GroupRoleAdmin     = "admin"
+	GroupRoleModerator = "moderator"
+	GroupRoleMember    = "member"
+)
+
+// Group visibilities. Public groups can be joined and read by anyone, private
+// groups are listed but only readable by their members, secret groups are
+// hidden from everyone but their members and invitees.
+const (
+	GroupPublic  = "public"
+	GroupPrivate = "private"
+	GroupSecret  = "secret"
+)
+
+// GroupPermission is an action restricted to some group roles.
+type GroupPermission string
+
+// Group permissions.
+const (
+	PermInvite        GroupPermission = "invite"
+	PermApproveJoin   GroupPermission = "approve_join_requests"
+	PermRemoveMember  GroupPermission = "remove_members"
+	PermDeleteContent GroupPermission = "delete_content"
+	PermCreateEvent   GroupPermission = "create_events"
+	PermCancelEvent   GroupPermission = "cancel_events"
+	PermEditEvent     GroupPermission = "edit_events"
+	PermEditSettings  GroupPermission = "edit_settings"
+	PermManageRoles   GroupPermission = "manage_roles"
+	PermTransferOwner GroupPermission = "transfer_ownership"
+	PermManageLinks   GroupPermission = "manage_invite_links"
+	PermPinPosts      GroupPermission = "pin_posts"
+	PermAnnounce      GroupPermission = "post_announcements"
+	PermViewAudit     GroupPermission = "view_audit_log"
+	PermArchiveGroup  GroupPermission = "archive_group"
+	PermDeleteGroup   GroupPermission = "delete_group"
+)
+
+// GroupPermissions is the permission matrix of the group roles. Members can
+// always edit or delete their own posts, comments and events.
+var GroupPermissions = map[string]map[GroupPermission]bool{
+	GroupRoleOwner: {
+		PermInvite: true, PermApproveJoin: true, PermRemoveMember: true, PermDeleteContent: true,
+		PermCreateEvent: true, PermCancelEvent: true, PermEditSettings: true, PermManageRoles: true,
+		PermTransferOwner: true, PermManageLinks: true, PermPinPosts: true, PermAnnounce: true,
+		PermViewAudit: true, PermArchiveGroup: true, PermDeleteGroup: true, PermEditEvent: true,
+	},
+	GroupRoleAdmin: {
+		PermInvite: true, PermApproveJoin: true, PermRemoveMember: true, PermDeleteContent: true,
+		PermCreateEvent: true, PermCancelEvent: true, PermEditSettings: true, PermManageRoles: true,
+		PermManageLinks: true, PermPinPosts: true, PermAnnounce: true, PermViewAudit: true,
+		PermEditEvent: true,
+	},
+	GroupRoleModerator: {
+		PermInvite: true, PermApproveJoin: true, PermDeleteContent: true,
+		PermCreateEvent: true, PermCancelEvent: true,
+	},
+	GroupRoleMember: {
+		PermInvite: true, PermCreateEvent: true,
+	},
+}
+
+// groupRoleRanks orders the roles: a member can only act on lower ranks.
+var groupRoleRanks = map[string]int{
+	GroupRoleOwner:     4,
+	GroupRoleAdmin:     3,
+	GroupRoleModerator: 2,
+	GroupRoleMember:    1,
+}
+
+var (
+	ErrInvalidGroupRole = errors.New("invalid group role")
+	ErrNotGroupMember   = errors.New("user is not a member of the group")
+	ErrGroupForbidden   = errors.New("not allowed in this group")
+
+	ErrInvalidGroupVisibility = errors.New("invalid group visibility, expected public, private or secret")
+	ErrLastGroupOwner         = errors.New("the owner must transfer the group before leaving it")
+	ErrGroupBanned            = errors.New("user is banned from this group")
+	ErrTooManyPinnedPosts     = fmt.Errorf("a group cannot have more than %d pinned posts", MaxPinnedPosts)
+	ErrGroupArchived          = errors.New("this group is archived and read-only")
+	ErrGroupRestoreExpired    = errors.New("the restore window of this group has expired")
+	ErrInvalidGroupCategory   = errors.New("invalid group category")
+	ErrInvalidGroupTags       = fmt.Errorf("a group can have up to %d tags of %d letters, digits or dashes", MaxGroupTags, maxGroupTagLength)
+	ErrInvalidGroupQuestions  = fmt.Errorf("a group can ask up to %d questions of 1 to 300 characters", MaxGroupQuestions)
+	ErrMissingAnswers         = errors.New("every required question must be answered, in 1000 characters at most")
+	ErrGroupRulesNotAccepted  = errors.New("the current rules of the group must be accepted first")
+	ErrGroupRulesChanged      = errors.New("the rules of the group changed, read them again before accepting")
+)
+
+// MaxPinnedPosts is the number of posts a group can pin at once.
+const MaxPinnedPosts = 3
+
+// Group categories of the discovery directory.
+var GroupCategories = []string{
+	"art", "business", "education", "entertainment", "gaming", "health",
+	"music", "science", "sports", "technology", "travel", "other",
+}
+
+// MaxGroupTags is the number of tags a group can have.
+const MaxGroupTags = 10
+
+const maxGroupTagLength = 30
+
+// MaxGroupQuestions is the number of questions a group can ask to the users
+// requesting to join it.
+const MaxGroupQuestions = 5
+
+// GroupRestoreWindow is how long a deleted group can be restored before it is
+// purged with all its content.
+const GroupRestoreWindow = 30 * 24 * time.Hour
+
+// Actions recorded in the group audit log.
+const (
+	AuditGroupCreated         = "group_created"
+	AuditSettingsUpdated      = "settings_updated"
+	AuditRoleChanged          = "role_changed"
+	AuditOwnershipTransferred = "ownership_transferred"
+	AuditMemberInvited        = "member_invited"
+	AuditInvitationAccepted   = "invitation_accepted"
+	AuditInvitationDeclined   = "invitation_declined"
+	AuditJoinApproved         = "join_request_approved"
+	AuditJoinRejected         = "join_request_rejected"
+	AuditPostDeleted          = "post_deleted"
+	AuditCommentDeleted       = "comment_deleted"
+	AuditEventCreated         = "event_created"
+	AuditEventDeleted         = "event_deleted"
+	AuditEventUpdated         = "event_updated"
+	AuditEventCancelled       = "event_cancelled"
+	AuditMemberLeft           = "member_left"
+	AuditMemberRemoved        = "member_removed"
+	AuditMemberBanned         = "member_banned"
+	AuditMemberUnbanned       = "member_unbanned"
+	AuditLinkCreated          = "invite_link_created"
+	AuditLinkRevoked          = "invite_link_revoked"
+	AuditLinkJoined           = "member_joined_by_link"
+	AuditPostPinned           = "post_pinned"
+	AuditPostUnpinned         = "post_unpinned"
+	AuditPostAnnounced        = "post_announced"
+	AuditAnnouncementRemoved  = "announcement_removed"
+	AuditGroupArchived        = "group_archived"
+	AuditGroupUnarchived      = "group_unarchived"
+	AuditGroupDeleted         = "group_deleted"
+	AuditGroupRestored        = "group_restored"
+	AuditQuestionsUpdated     = "questions_updated"
+	AuditRulesUpdated         = "rules_updated"
+)
+
+// execer is either the database or a transaction.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// recordAudit appends an entry to the audit log of a group. A target ID of 0
+// means the action has no target. Dates are stored in UTC so that they
+// compare as strings.
+func recordAudit(db execer, groupID, actorID int64, action, targetType string, targetID int64, details string) error {
+	var target any
+	if targetID != 0 {
+		target = targetID
+	}
+	_, err := db.Exec(`
+		INSERT INTO group_audit_log (group_id, actor_id, action, target_type, target_id, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, groupID, actorID, action, targetType, target, details, time.Now().UTC())
+	return err
+}
+
+// RecordAudit appends an action performed outside of the service to the
+// audit log of the group, in the transaction of the action so that the log
+// misses none. The log is append-only.
+func RecordAudit(tx *sql.Tx, groupID, actorID int64, action, targetType string, targetID int64, details string) error {
+	return recordAudit(tx, groupID, actorID, action, targetType, targetID, details)
+}
+
+// ValidGroupVisibility reports whether the visibility is a known one.
+func ValidGroupVisibility(visibility string) bool {
+	return visibility == GroupPublic || visibility == GroupPrivate || visibility == GroupSecret
+}
+
+// ValidGroupCategory reports whether the category is a known one. Groups may
+// also have no category.
+func ValidGroupCategory(category string) bool {
+	if category == "" {
+		return true
+	}
+	for _, c := range GroupCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeGroupTags lowercases the tags, drops their leading '#' and the
+// duplicates, and checks them against the tag rules. The tags are returned in
+// alphabetical order.
+func NormalizeGroupTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len([]rune(tag)) > maxGroupTagLength {
+			return nil, ErrInvalidGroupTags
+		}
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' {
+				return nil, ErrInvalidGroupTags
+			}
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxGroupTags {
+		return nil, ErrInvalidGroupTags
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// GroupProfile is how a group is presented in the discovery directory.
+type GroupProfile struct {
+	Category  string   `json:"category"`
+	CoverPath string   `json:"cover_path"`
+	Tags      []string `json:"tags"`
+}
+
+// GroupService handles the roles and permissions of the group members.
+type GroupService struct {
+	db *sql.DB
+}
+
+// NewGroupService creates a new GroupService.
+func NewGroupService(db *sql.DB) *GroupService {
+	return &GroupService{db: db}
+}
+
+// GetRole returns the role of an accepted member, or "" for anyone else. The
+// members of a deleted group have no role left.
+func (s *GroupService) GetRole(groupID, userID int64) string {
+	var role string
+	err := s.db.QueryRow(`
+		SELECT gm.role FROM group_members gm
+		JOIN groups g ON g.id = gm.group_id AND g.deleted_at IS NULL
+		WHERE gm.group_id = ? AND gm.user_id = ? AND gm.accepted = 1
+	`, groupID, userID).Scan(&role)
+	if err != nil {
+		return ""
+	}
+	return role
+}
+
+// GetVisibility returns the visibility of a group, or "" if it does not exist
+// or was deleted.
+func (s *GroupService) GetVisibility(groupID int64) string {
+	var visibility string
+	err := s.db.QueryRow(`
+		SELECT visibility FROM groups WHERE id = ? AND deleted_at IS NULL
+	`, groupID).Scan(&visibility)
+	if err != nil {
+		return ""
+	}
+	return visibility
+}
+
+// IsArchived reports whether the group is archived, and so read-only.
+func (s *GroupService) IsArchived(groupID int64) bool {
+	var archived bool
+	err := s.db.QueryRow(`
+		SELECT archived_at IS NOT NULL FROM groups WHERE id = ?
+	`, groupID).Scan(&archived)
+	return err == nil && archived
+}
+
+// IsMember reports whether the user is an accepted member of the group.
+func (s *GroupService) IsMember(groupID, userID int64) bool {
+	return s.GetRole(groupID, userID) != ""
+}
+
+// CanSee reports whether the user may know the group exists.
+func (s *GroupService) CanSee(groupID, userID int64) bool {
+	switch s.GetVisibility(groupID) {
+	case "":
+		return false
+	case GroupSecret:
+		if s.IsMember(groupID, userID) {
+			return true
+		}
+		var count int
+		err := s.db.QueryRow(`
+			SELECT COUNT(*) FROM group_invitations WHERE group_id = ? AND invitee_id = ?
+		`, groupID, userID).Scan(&count)
+		return err == nil && count > 0
+	default:
+		return true
+	}
+}
+
+// CanRead reports whether the user may read the content of the group.
+func (s *GroupService) CanRead(groupID, userID int64) bool {
+	return s.GetVisibility(groupID) == GroupPublic || s.IsMember(groupID, userID)
+}
+
+// Can reports whether the user's role in the group grants the permission.
+func (s *GroupService) Can(groupID, userID int64, perm GroupPermission) bool {
+	return GroupPermissions[s.GetRole(groupID, userID)][perm]
+}
+
+// CanActOn reports whether the actor has the permission and outranks the
+// target, so moderators cannot remove admins for instance.
+func (s *GroupService) CanActOn(groupID, actorID, targetID int64, perm GroupPermission) bool {
+	actorRole := s.GetRole(groupID, actorID)
+	if !GroupPermissions[actorRole][perm] {
+		return false
+	}
+	return groupRoleRanks[actorRole] > groupRoleRanks[s.GetRole(groupID, targetID)]
+}
+
+// MembersWith lists the accepted members whose role grants the permission.
+func (s *GroupService) MembersWith(groupID int64, perm GroupPermission) ([]int64, error) {
+	roles := []any{groupID}
+	placeholders := ""
+	for role, perms := range GroupPermissions {
+		if perms[perm] {
+			roles = append(roles, role)
+			placeholders += ", ?"
+		}
+	}
+	if placeholders == "" {
+		return nil, nil
+	}
+
+	return s.queryUserIDs(`
+		SELECT user_id FROM group_members
+		WHERE group_id = ? AND accepted = 1 AND role IN (`+placeholders[2:]+`)
+	`, roles...)
+}
+
+// MemberIDs lists the accepted members of the group.
+func (s *GroupService) MemberIDs(groupID int64) ([]int64, error) {
+	return s.queryUserIDs(`
+		SELECT user_id FROM group_members WHERE group_id = ? AND accepted = 1
+	`, groupID)
+}
+
+func (s *GroupService) queryUserIDs(query string, args ...any) ([]int64, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// SetRole promotes or demotes a member. The actor must outrank both the
+// current and the new role of the target; ownership goes through
+// TransferOwnership.
+func (s *GroupService) SetRole(groupID, actorID, targetID int64, role string) error {
+	if role == GroupRoleOwner {
+		return ErrGroupForbidden
+	}
+	if _, ok := groupRoleRanks[role]; !ok {
+		return ErrInvalidGroupRole
+	}
+
+	targetRole := s.GetRole(groupID, targetID)
+	if targetRole == "" {
+		return ErrNotGroupMember
+	}
+	actorRole := s.GetRole(groupID, actorID)
+	if !GroupPermissions[actorRole][PermManageRoles] ||
+		groupRoleRanks[actorRole] <= groupRoleRanks[targetRole] ||
+		groupRoleRanks[actorRole] <= groupRoleRanks[role] {
+		return ErrGroupForbidden
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE group_members SET role = ? WHERE group_id = ? AND user_id = ?
+	`, role, groupID, targetID); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditRoleChanged, "user", targetID, targetRole+" -> "+role); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// TransferOwnership makes another member the owner of the group. The former
+// owner stays in the group as an admin.
+func (s *GroupService) TransferOwnership(groupID, ownerID, newOwnerID int64) error {
+	if !s.Can(groupID, ownerID, PermTransferOwner) {
+		return ErrGroupForbidden
+	}
+	if ownerID == newOwnerID {
+		return ErrInvalidGroupRole
+	}
+	if s.GetRole(groupID, newOwnerID) == "" {
+		return ErrNotGroupMember
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE group_members SET role = ? WHERE group_id = ? AND user_id = ?
+	`, GroupRoleAdmin, groupID, ownerID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE group_members SET role = ? WHERE group_id = ? AND user_id = ?
+	`, GroupRoleOwner, groupID, newOwnerID); err != nil {
+		return err
+	}
+
+	// creator_id reste le propriétaire courant, utilisé par le reste de l'application
+	if _, err := tx.Exec(`
+		UPDATE groups SET creator_id = ?, creator_name = (SELECT username FROM users WHERE id = ?), updated_at = ?
+		WHERE id = ?
+	`, newOwnerID, newOwnerID, time.Now(), groupID); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, groupID, ownerID, AuditOwnershipTransferred, "user", newOwnerID, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateGroup creates a group with its creator as owner and its directory
+// profile, and opens its audit log, all in one transaction. The ID of the
+// group is set on success.
+func (s *GroupService) CreateGroup(group *models.Group, profile GroupProfile) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO groups (creator_id, creator_name, title, description, visibility, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, group.CreatorID, group.CreatorName, group.Title, group.Description, group.Visibility, group.CreatedAt, group.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	groupID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO group_members (group_id, user_id, username, accepted, role, created_at)
+		VALUES (?, ?, ?, 1, ?, ?)
+	`, groupID, group.CreatorID, group.CreatorName, GroupRoleOwner, group.CreatedAt); err != nil {
+		return err
+	}
+	if err := setProfile(tx, groupID, profile); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, groupID, group.CreatorID, AuditGroupCreated, "group", groupID, ""); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	group.ID = groupID
+	return nil
+}
+
+// UpdateSettings changes the title, description and visibility of a group.
+// The audit log records which settings changed.
+func (s *GroupService) UpdateSettings(groupID, actorID int64, title, description, visibility string) error {
+	if !ValidGroupVisibility(visibility) {
+		return ErrInvalidGroupVisibility
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldTitle, oldDescription, oldVisibility string
+	if err := tx.QueryRow(`
+		SELECT title, COALESCE(description, ''), visibility FROM groups WHERE id = ?
+	`, groupID).Scan(&oldTitle, &oldDescription, &oldVisibility); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE groups SET title = ?, description = ?, visibility = ?, updated_at = ? WHERE id = ?
+	`, title, description, visibility, time.Now(), groupID); err != nil {
+		return err
+	}
+
+	var changes []string
+	if title != oldTitle {
+		changes = append(changes, "title")
+	}
+	if description != oldDescription {
+		changes = append(changes, "description")
+	}
+	if visibility != oldVisibility {
+		changes = append(changes, "visibility: "+oldVisibility+" -> "+visibility)
+	}
+	if len(changes) > 0 {
+		if err := recordAudit(tx, groupID, actorID, AuditSettingsUpdated, "group", groupID, strings.Join(changes, ", ")); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// setProfile validates and writes the directory profile of a group.
+func setProfile(tx *sql.Tx, groupID int64, profile GroupProfile) error {
+	if !ValidGroupCategory(profile.Category) {
+		return ErrInvalidGroupCategory
+	}
+	tags, err := NormalizeGroupTags(profile.Tags)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE groups SET category = NULLIF(?, ''), cover_path = NULLIF(?, ''), updated_at = ? WHERE id = ?
+	`, profile.Category, profile.CoverPath, time.Now(), groupID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM group_tags WHERE group_id = ?`, groupID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec(`INSERT INTO group_tags (group_id, tag) VALUES (?, ?)`, groupID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateProfile changes the category, cover and tags of a group. The audit
+// log records which of them changed.
+func (s *GroupService) UpdateProfile(groupID, actorID int64, profile GroupProfile) error {
+	if !s.Can(groupID, actorID, PermEditSettings) {
+		return ErrGroupForbidden
+	}
+	oldTags, err := s.Tags(groupID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldCategory, oldCover string
+	if err := tx.QueryRow(`
+		SELECT COALESCE(category, ''), COALESCE(cover_path, '') FROM groups WHERE id = ?
+	`, groupID).Scan(&oldCategory, &oldCover); err != nil {
+		return err
+	}
+	if err := setProfile(tx, groupID, profile); err != nil {
+		return err
+	}
+
+	var changes []string
+	if profile.Category != oldCategory {
+		changes = append(changes, "category: "+oldCategory+" -> "+profile.Category)
+	}
+	if profile.CoverPath != oldCover {
+		changes = append(changes, "cover")
+	}
+	if tags, _ := NormalizeGroupTags(profile.Tags); strings.Join(tags, ",") != strings.Join(oldTags, ",") {
+		changes = append(changes, "tags")
+	}
+	if len(changes) > 0 {
+		if err := recordAudit(tx, groupID, actorID, AuditSettingsUpdated, "group", groupID, strings.Join(changes, ", ")); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Tags returns the tags of a group, in alphabetical order.
+func (s *GroupService) Tags(groupID int64) ([]string, error) {
+	rows, err := s.db.Query(`SELECT tag FROM group_tags WHERE group_id = ? ORDER BY tag`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// Questions returns the membership questions of a group, in order.
+func (s *GroupService) Questions(groupID int64) ([]*models.GroupQuestion, error) {
+	rows, err := s.db.Query(`
+		SELECT id, group_id, position, question, required FROM group_questions
+		WHERE group_id = ? ORDER BY position, id
+	`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []*models.GroupQuestion{}
+	for rows.Next() {
+		q := &models.GroupQuestion{}
+		if err := rows.Scan(&q.ID, &q.GroupID, &q.Position, &q.Question, &q.Required); err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+// SetQuestions replaces the membership questions of a group. Pending join
+// requests keep the questions they answered.
+func (s *GroupService) SetQuestions(groupID, actorID int64, questions []*models.GroupQuestion) error {
+	if !s.Can(groupID, actorID, PermEditSettings) {
+		return ErrGroupForbidden
+	}
+	if len(questions) > MaxGroupQuestions {
+		return ErrInvalidGroupQuestions
+	}
+	for _, q := range questions {
+		q.Question = strings.TrimSpace(q.Question)
+		if n := len([]rune(q.Question)); n == 0 || n > 300 {
+			return ErrInvalidGroupQuestions
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM group_questions WHERE group_id = ?`, groupID); err != nil {
+		return err
+	}
+	for i, q := range questions {
+		if _, err := tx.Exec(`
+			INSERT INTO group_questions (group_id, position, question, required, created_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, groupID, i+1, q.Question, q.Required, time.Now()); err != nil {
+			return err
+		}
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditQuestionsUpdated, "group", groupID, fmt.Sprintf("%d questions", len(questions))); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CheckAnswers matches the answers of a join request, keyed by question ID,
+// with the questions of the group. Every required question must be answered
+// and unknown questions are ignored.
+func (s *GroupService) CheckAnswers(groupID int64, answers map[int64]string) ([]*models.JoinRequestAnswer, error) {
+	questions, err := s.Questions(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	checked := []*models.JoinRequestAnswer{}
+	for _, q := range questions {
+		answer := strings.TrimSpace(answers[q.ID])
+		if (q.Required && answer == "") || len([]rune(answer)) > 1000 {
+			return nil, ErrMissingAnswers
+		}
+		id := q.ID
+		checked = append(checked, &models.JoinRequestAnswer{QuestionID: &id, Question: q.Question, Answer: answer})
+	}
+	return checked, nil
+}
+
+// CreateJoinRequest stores a pending request of a user to join a group along
+// AcceptInvitation makes the invited user a member of the group, removes the
+// invitation with its notification and records it in the audit log, in one
+// transaction.
+func (s *GroupService) AcceptInvitation(groupID, userID int64, username string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO group_members (group_id, user_id, username, accepted, created_at)
+		SELECT ?, ?, ?, 1, ?
+		WHERE NOT EXISTS (SELECT 1 FROM group_members WHERE group_id = ? AND user_id = ?)
+	`, groupID, userID, username, time.Now(), groupID, userID); err != nil {
+		return err
+	}
+	if err := deleteInvitation(tx, groupID, userID); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, groupID, userID, AuditInvitationAccepted, "user", userID, ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeclineInvitation removes the invitation of the user with its notification,
+// and records it in the audit log when there was one.
+func (s *GroupService) DeclineInvitation(groupID, userID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var invited int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*) FROM group_invitations WHERE group_id = ? AND invitee_id = ?
+	`, groupID, userID).Scan(&invited); err != nil {
+		return err
+	}
+	if err := deleteInvitation(tx, groupID, userID); err != nil {
+		return err
+	}
+	if invited > 0 {
+		if err := recordAudit(tx, groupID, userID, AuditInvitationDeclined, "user", userID, ""); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func deleteInvitation(tx *sql.Tx, groupID, userID int64) error {
+	if _, err := tx.Exec(`
+		DELETE FROM group_invitations WHERE group_id = ? AND invitee_id = ?
+	`, groupID, userID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		DELETE FROM notifications WHERE user_id = ? AND reference_id = ? AND type = 'group_invitation'
+	`, userID, groupID)
+	return err
+}
+
+// with its checked answers, all or nothing.
+func (s *GroupService) CreateJoinRequest(groupID, requesterID int64, message string, answers []*models.JoinRequestAnswer) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO group_join_requests (group_id, requester_id, message, created_at)
+		VALUES (?, ?, ?, ?)
+	`, groupID, requesterID, message, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	requestID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, a := range answers {
+		if _, err := tx.Exec(`
+			INSERT INTO group_join_request_answers (request_id, question_id, question, answer)
+			VALUES (?, ?, ?, ?)
+		`, requestID, a.QuestionID, a.Question, a.Answer); err != nil {
+			return 0, err
+		}
+	}
+	return requestID, tx.Commit()
+}
+
+// Answers returns the answers of a join request, in the order of the questions.
+func (s *GroupService) Answers(requestID int64) ([]*models.JoinRequestAnswer, error) {
+	rows, err := s.db.Query(`
+		SELECT question_id, question, answer FROM group_join_request_answers
+		WHERE request_id = ? ORDER BY id
+	`, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	answers := []*models.JoinRequestAnswer{}
+	for rows.Next() {
+		a := &models.JoinRequestAnswer{}
+		var questionID sql.NullInt64
+		if err := rows.Scan(&questionID, &a.Question, &a.Answer); err != nil {
+			return nil, err
+		}
+		if questionID.Valid {
+			a.QuestionID = &questionID.Int64
+		}
+		answers = append(answers, a)
+	}
+	return answers, rows.Err()
+}
+
+// Rules returns the rules of a group and whether the user has to accept them.
+func (s *GroupService) Rules(groupID, userID int64) (*models.GroupRules, error) {
+	rules := &models.GroupRules{}
+	var updatedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT g.rules, g.rules_version, g.rules_updated_at, COALESCE(ra.version, 0)
+		FROM groups g
+		LEFT JOIN group_rules_acceptances ra ON ra.group_id = g.id AND ra.user_id = ?
+		WHERE g.id = ?
+	`, userID, groupID).Scan(&rules.Rules, &rules.Version, &updatedAt, &rules.AcceptedVersion)
+	if err != nil {
+		return nil, err
+	}
+	if updatedAt.Valid {
+		rules.UpdatedAt = &updatedAt.Time
+	}
+	rules.MustAccept = rules.Rules != "" && rules.AcceptedVersion < rules.Version
+	return rules, nil
+}
+
+// HasAcceptedRules reports whether the user accepted the current rules of the
+// group, or the group has none.
+func (s *GroupService) HasAcceptedRules(groupID, userID int64) bool {
+	rules, err := s.Rules(groupID, userID)
+	return err == nil && !rules.MustAccept
+}
+
+// SetRules changes the rules of a group and returns their version. Every
+// change makes a new version the members must accept again; the author of
+// the change accepts it right away.
+func (s *GroupService) SetRules(groupID, actorID int64, text string) (int, error) {
+	if !s.Can(groupID, actorID, PermEditSettings) {
+		return 0, ErrGroupForbidden
+	}
+	text = strings.TrimSpace(text)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var oldText string
+	var version int
+	if err := tx.QueryRow(`
+		SELECT rules, rules_version FROM groups WHERE id = ?
+	`, groupID).Scan(&oldText, &version); err != nil {
+		return 0, err
+	}
+	if text == oldText {
+		return version, nil
+	}
+
+	version++
+	if _, err := tx.Exec(`
+		UPDATE groups SET rules = ?, rules_version = ?, rules_updated_at = ? WHERE id = ?
+	`, text, version, time.Now(), groupID); err != nil {
+		return 0, err
+	}
+	if err := acceptRules(tx, groupID, actorID, version); err != nil {
+		return 0, err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditRulesUpdated, "group", groupID, fmt.Sprintf("version %d", version)); err != nil {
+		return 0, err
+	}
+	return version, tx.Commit()
+}
+
+func acceptRules(db execer, groupID, userID int64, version int) error {
+	_, err := db.Exec(`
+		INSERT INTO group_rules_acceptances (group_id, user_id, version, accepted_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (group_id, user_id) DO UPDATE SET version = excluded.version, accepted_at = excluded.accepted_at
+	`, groupID, userID, version, time.Now())
+	return err
+}
+
+// AcceptRules records that a member accepted a version of the rules. Only the
+// current version can be accepted.
+func (s *GroupService) AcceptRules(groupID, userID int64, version int) error {
+	if !s.IsMember(groupID, userID) {
+		return ErrNotGroupMember
+	}
+	rules, err := s.Rules(groupID, userID)
+	if err != nil {
+		return err
+	}
+	if version != rules.Version {
+		return ErrGroupRulesChanged
+	}
+	return acceptRules(s.db, groupID, userID, version)
+}
+
+// setArchived archives or unarchives a group and reports whether it changed.
+func (s *GroupService) setArchived(groupID, actorID int64, archived bool) (bool, error) {
+	if !s.Can(groupID, actorID, PermArchiveGroup) {
+		return false, ErrGroupForbidden
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var archivedAt any
+	action := AuditGroupUnarchived
+	if archived {
+		archivedAt, action = time.Now(), AuditGroupArchived
+	}
+	result, err := tx.Exec(`
+		UPDATE groups SET archived_at = ?, updated_at = ? WHERE id = ? AND (archived_at IS NULL) = ?
+	`, archivedAt, time.Now(), groupID, archived)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	if err := recordAudit(tx, groupID, actorID, action, "group", groupID, ""); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Archive makes a group read-only: its content stays visible but nothing can
+// be posted, sent or scheduled in it anymore.
+func (s *GroupService) Archive(groupID, actorID int64) (bool, error) {
+	return s.setArchived(groupID, actorID, true)
+}
+
+// Unarchive opens an archived group again.
+func (s *GroupService) Unarchive(groupID, actorID int64) (bool, error) {
+	return s.setArchived(groupID, actorID, false)
+}
+
+// Delete hides a group from everyone and returns the time until which its
+// owner can restore it. The group is purged once that time is past.
+func (s *GroupService) Delete(groupID, actorID int64) (time.Time, error) {
+	if !s.Can(groupID, actorID, PermDeleteGroup) {
+		return time.Time{}, ErrGroupForbidden
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE groups SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL
+	`, now, groupID); err != nil {
+		return time.Time{}, err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditGroupDeleted, "group", groupID, ""); err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(GroupRestoreWindow), tx.Commit()
+}
+
+// Restore brings back a deleted group, if it is still within its restore
+// window. Only its owner can restore it.
+func (s *GroupService) Restore(groupID, actorID int64) error {
+	var deletedAt time.Time
+	err := s.db.QueryRow(`
+		SELECT g.deleted_at FROM groups g
+		JOIN group_members gm ON gm.group_id = g.id AND gm.user_id = ? AND gm.accepted = 1 AND gm.role = ?
+		WHERE g.id = ? AND g.deleted_at IS NOT NULL
+	`, actorID, GroupRoleOwner, groupID).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotGroupMember
+	}
+	if err != nil {
+		return err
+	}
+	if time.Since(deletedAt) > GroupRestoreWindow {
+		return ErrGroupRestoreExpired
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE groups SET deleted_at = NULL WHERE id = ?`, groupID); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditGroupRestored, "group", groupID, ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListDeleted returns the deleted groups the user owns and can still restore.
+func (s *GroupService) ListDeleted(ownerID int64) ([]*models.DeletedGroup, error) {
+	rows, err := s.db.Query(`
+		SELECT g.id, g.title, g.visibility, g.deleted_at FROM groups g
+		JOIN group_members gm ON gm.group_id = g.id AND gm.user_id = ? AND gm.accepted = 1 AND gm.role = ?
+		WHERE g.deleted_at IS NOT NULL AND g.deleted_at > ?
+		ORDER BY g.deleted_at DESC
+	`, ownerID, GroupRoleOwner, time.Now().Add(-GroupRestoreWindow))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*models.DeletedGroup{}
+	for rows.Next() {
+		group := &models.DeletedGroup{}
+		if err := rows.Scan(&group.ID, &group.Title, &group.Visibility, &group.DeletedAt); err != nil {
+			return nil, err
+		}
+		group.RestoreUntil = group.DeletedAt.Add(GroupRestoreWindow)
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+// PurgeDeletedGroups erases the groups whose restore window has expired and
+// returns how many were erased. Posts, comments, messages, events and
+// invitations follow through the foreign keys; the notifications pointing
+// at the group are removed here.
+func (s *GroupService) PurgeDeletedGroups() (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	cutoff := time.Now().Add(-GroupRestoreWindow)
+	if _, err := tx.Exec(`
+		DELETE FROM notifications WHERE
+			(reference_type = 'group' AND reference_id IN (
+				SELECT id FROM groups WHERE deleted_at < ?))
+			OR (reference_type = 'group_join_request' AND reference_id IN (
+				SELECT jr.id FROM group_join_requests jr JOIN groups g ON g.id = jr.group_id WHERE g.deleted_at < ?))
+			OR (reference_type = 'post' AND reference_id IN (
+				SELECT p.id FROM posts p JOIN groups g ON g.id = p.group_id WHERE g.deleted_at < ?))
+			OR (reference_type = 'comment' AND reference_id IN (
+				SELECT c.id FROM comments c JOIN posts p ON p.id = c.post_id JOIN groups g ON g.id = p.group_id WHERE g.deleted_at < ?))
+	`, cutoff, cutoff, cutoff, cutoff); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM groups WHERE deleted_at < ?`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// removeMember deletes the membership of a user and what hangs on it: the
+// responses to the group events and the conversations tied to the group. The
+// seats the user held at scheduled events go to their waitlists, and the
+// promotions are returned.
+func removeMember(tx *sql.Tx, groupID, userID int64) ([]*WaitlistPromotion, error) {
+	rows, err := tx.Query(`
+		SELECT e.id, e.title FROM events e
+		JOIN event_responses er ON er.event_id = e.id
+		WHERE e.group_id = ? AND er.user_id = ? AND er.status = 'going' AND e.cancelled_at IS NULL
+	`, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var freed []*WaitlistPromotion
+	for rows.Next() {
+		var promotion WaitlistPromotion
+		if err := rows.Scan(&promotion.EventID, &promotion.EventTitle); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		freed = append(freed, &promotion)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	queries := []string{
+		`DELETE FROM group_members WHERE group_id = ? AND user_id = ?`,
+		`DELETE FROM event_responses
+		WHERE event_id IN (SELECT id FROM events WHERE group_id = ?) AND user_id = ?`,
+		`DELETE FROM conversation_members
+		WHERE conversation_id IN (SELECT DISTINCT conversation_id FROM messages WHERE group_id = ?) AND user_id = ?`,
+		`DELETE FROM typing_status
+		WHERE conversation_id IN (SELECT DISTINCT conversation_id FROM messages WHERE group_id = ?) AND user_id = ?`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, groupID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	var promotions []*WaitlistPromotion
+	for _, promotion := range freed {
+		if promotion.UserIDs, err = PromoteWaitlist(tx, promotion.EventID); err != nil {
+			return nil, err
+		}
+		if len(promotion.UserIDs) > 0 {
+			promotions = append(promotions, promotion)
+		}
+	}
+	return promotions, nil
+}
+
+// Leave removes the user from the group. The owner has to transfer the group
+// first so that it is never left without one. The users given the seats the
+// member held at events are returned.
+func (s *GroupService) Leave(groupID, userID int64) ([]*WaitlistPromotion, error) {
+	role := s.GetRole(groupID, userID)
+	if role == "" {
+		return nil, ErrNotGroupMember
+	}
+	if role == GroupRoleOwner {
+		return nil, ErrLastGroupOwner
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	promotions, err := removeMember(tx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(tx, groupID, userID, AuditMemberLeft, "user", userID, ""); err != nil {
+		return nil, err
+	}
+	return promotions, tx.Commit()
+}
+
+// RemoveMember kicks a member out of the group. The actor needs the
+// permission and must outrank the member. The users given the seats the
+// member held at events are returned.
+func (s *GroupService) RemoveMember(groupID, actorID, targetID int64, reason string) ([]*WaitlistPromotion, error) {
+	if s.GetRole(groupID, targetID) == "" {
+		return nil, ErrNotGroupMember
+	}
+	if !s.CanActOn(groupID, actorID, targetID, PermRemoveMember) {
+		return nil, ErrGroupForbidden
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	promotions, err := removeMember(tx, groupID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditMemberRemoved, "user", targetID, reason); err != nil {
+		return nil, err
+	}
+	return promotions, tx.Commit()
+}
+
+// Ban removes a user from the group, if they are a member, and prevents them
+// from joining again or being invited. Their pending invitations and join
+// requests are dropped. The users given the seats the member held at events
+// are returned.
+func (s *GroupService) Ban(groupID, actorID, targetID int64, reason string) ([]*WaitlistPromotion, error) {
+	if actorID == targetID {
+		return nil, ErrGroupForbidden
+	}
+	member := s.GetRole(groupID, targetID) != ""
+	if (member && !s.CanActOn(groupID, actorID, targetID, PermRemoveMember)) ||
+		(!member && !s.Can(groupID, actorID, PermRemoveMember)) {
+		return nil, ErrGroupForbidden
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO group_bans (group_id, user_id, banned_by, reason, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (group_id, user_id) DO UPDATE SET banned_by = excluded.banned_by, reason = excluded.reason
+	`, groupID, targetID, actorID, reason, time.Now()); err != nil {
+		return nil, err
+	}
+	var promotions []*WaitlistPromotion
+	if member {
+		if promotions, err = removeMember(tx, groupID, targetID); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM group_invitations WHERE group_id = ? AND invitee_id = ?
+	`, groupID, targetID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE group_join_requests SET status = 'rejected', decided_by = ?, decided_at = ?
+		WHERE group_id = ? AND requester_id = ? AND status = 'pending'
+	`, actorID, time.Now(), groupID, targetID); err != nil {
+		return nil, err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditMemberBanned, "user", targetID, reason); err != nil {
+		return nil, err
+	}
+	return promotions, tx.Commit()
+}
+
+// Unban lifts a ban and reports whether there was one.
+func (s *GroupService) Unban(groupID, actorID, targetID int64) (bool, error) {
+	if !s.Can(groupID, actorID, PermRemoveMember) {
+		return false, ErrGroupForbidden
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM group_bans WHERE group_id = ? AND user_id = ?`, groupID, targetID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditMemberUnbanned, "user", targetID, ""); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// IsBanned reports whether the user is banned from the group.
+func (s *GroupService) IsBanned(groupID, userID int64) bool {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM group_bans WHERE group_id = ? AND user_id = ?
+	`, groupID, userID).Scan(&count)
+	return err == nil && count > 0
+}
+
+// ListBans lists the users banned from the group, most recent first.
+func (s *GroupService) ListBans(groupID int64) ([]*models.GroupBan, error) {
+	rows, err := s.db.Query(`
+		SELECT gb.group_id, gb.user_id, u.username, COALESCE(u.avatar_path, ''), gb.banned_by, gb.reason, gb.created_at
+		FROM group_bans gb
+		JOIN users u ON u.id = gb.user_id
+		WHERE gb.group_id = ?
+		ORDER BY gb.created_at DESC, gb.id DESC
+	`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bans := []*models.GroupBan{}
+	for rows.Next() {
+		ban := &models.GroupBan{}
+		var bannedBy sql.NullInt64
+		if err := rows.Scan(&ban.GroupID, &ban.UserID, &ban.Username, &ban.AvatarPath, &bannedBy, &ban.Reason, &ban.CreatedAt); err != nil {
+			return nil, err
+		}
+		if bannedBy.Valid {
+			ban.BannedBy = &bannedBy.Int64
+		}
+		bans = append(bans, ban)
+	}
+	return bans, rows.Err()
+}
+
+// PinPost pins a post of the group on top of its posts, up to MaxPinnedPosts.
+// DeletePost removes a post of the group, by its author or a moderator, and
+// records it in the audit log.
+func (s *GroupService) DeletePost(groupID, actorID int64, post *models.Post) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM posts WHERE id = ? AND group_id = ?`, post.ID, groupID); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditPostDeleted, "post", post.ID, fmt.Sprintf("author %d", post.UserID)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteComment removes a comment of a group post, by its author or a
+// moderator, and records it in the audit log.
+func (s *GroupService) DeleteComment(groupID, actorID int64, comment *models.Comment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM comments WHERE id = ?`, comment.ID); err != nil {
+		return err
+	}
+	details := fmt.Sprintf("author %d, post %d", comment.UserID, comment.PostID)
+	if err := recordAudit(tx, groupID, actorID, AuditCommentDeleted, "comment", comment.ID, details); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Pinning a pinned post does nothing.
+func (s *GroupService) PinPost(groupID, actorID, postID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var pinned int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*) FROM posts WHERE group_id = ? AND pinned_at IS NOT NULL AND id != ?
+	`, groupID, postID).Scan(&pinned); err != nil {
+		return err
+	}
+	if pinned >= MaxPinnedPosts {
+		return ErrTooManyPinnedPosts
+	}
+
+	result, err := tx.Exec(`
+		UPDATE posts SET pinned_at = ? WHERE id = ? AND group_id = ? AND pinned_at IS NULL
+	`, time.Now(), postID, groupID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditPostPinned, "post", postID, ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UnpinPost puts a pinned post of the group back in its place.
+func (s *GroupService) UnpinPost(groupID, actorID, postID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE posts SET pinned_at = NULL WHERE id = ? AND group_id = ? AND pinned_at IS NOT NULL
+	`, postID, groupID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditPostUnpinned, "post", postID, ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetAnnouncement marks or unmarks a post of the group as an announcement,
+// and reports whether it changed.
+func (s *GroupService) SetAnnouncement(groupID, actorID, postID int64, announcement bool) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE posts SET is_announcement = ? WHERE id = ? AND group_id = ? AND is_announcement != ?
+	`, announcement, postID, groupID, announcement)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	action := AuditPostAnnounced
+	if !announcement {
+		action = AuditAnnouncementRemoved
+	}
+	if err := recordAudit(tx, groupID, actorID, action, "post", postID, ""); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Acknowledge records that the user has read an announcement.
+func (s *GroupService) Acknowledge(postID, userID int64) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO announcement_acknowledgements (post_id, user_id, acknowledged_at) VALUES (?, ?, ?)
+	`, postID, userID, time.Now())
+	return err
+}
+
+// Acknowledgements lists who has read an announcement, most recent first.
+func (s *GroupService) Acknowledgements(postID int64) ([]*models.AnnouncementAcknowledgement, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, COALESCE(u.avatar_path, ''), aa.acknowledged_at
+		FROM announcement_acknowledgements aa
+		JOIN users u ON u.id = aa.user_id
+		WHERE aa.post_id = ?
+		ORDER BY aa.acknowledged_at DESC, aa.id DESC
+	`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	acks := []*models.AnnouncementAcknowledgement{}
+	for rows.Next() {
+		ack := &models.AnnouncementAcknowledgement{}
+		if err := rows.Scan(&ack.UserID, &ack.Username, &ack.AvatarPath, &ack.AcknowledgedAt); err != nil {
+			return nil, err
+		}
+		acks = append(acks, ack)
+	}
+	return acks, rows.Err()
+}
+
+// AuditLogQuery filters a page of the audit log of a group. Zero values match
+// every entry.
+type AuditLogQuery struct {
+	Action     string
+	ActorID    int64
+	TargetType string
+	TargetID   int64
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditLog returns a page of the audit log of the group, most recent first.
+func (s *GroupService) AuditLog(groupID int64, query AuditLogQuery) (*models.GroupAuditPage, error) {
+	condition := "a.group_id = ?"
+	args := []any{groupID}
+	if query.Action != "" {
+		condition += " AND a.action = ?"
+		args = append(args, query.Action)
+	}
+	if query.ActorID != 0 {
+		condition += " AND a.actor_id = ?"
+		args = append(args, query.ActorID)
+	}
+	if query.TargetType != "" {
+		condition += " AND a.target_type = ?"
+		args = append(args, query.TargetType)
+	}
+	if query.TargetID != 0 {
+		condition += " AND a.target_id = ?"
+		args = append(args, query.TargetID)
+	}
+	if query.Since != nil {
+		condition += " AND a.created_at >= ?"
+		args = append(args, query.Since.UTC())
+	}
+	if query.Until != nil {
+		condition += " AND a.created_at < ?"
+		args = append(args, query.Until.UTC())
+	}
+
+	page := &models.GroupAuditPage{Entries: []*models.GroupAuditEntry{}}
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM group_audit_log a WHERE `+condition, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT a.id, a.group_id, a.actor_id, COALESCE(u.username, ''), a.action, a.target_type, a.target_id, a.details, a.created_at
+		FROM group_audit_log a
+		LEFT JOIN users u ON u.id = a.actor_id
+		WHERE `+condition+`
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT ? OFFSET ?
+	`, append(args, query.Limit, query.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := &models.GroupAuditEntry{}
+		var actorID, targetID sql.NullInt64
+		if err := rows.Scan(&entry.ID, &entry.GroupID, &actorID, &entry.ActorName, &entry.Action,
+			&entry.TargetType, &targetID, &entry.Details, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if actorID.Valid {
+			entry.ActorID = &actorID.Int64
+		}
+		if targetID.Valid {
+			entry.TargetID = &targetID.Int64
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page.HasMore = int64(query.Offset+len(page.Entries)) < page.Total
+	return page, nil
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"social-network/backend/database/models"
+)
+
+var (
+	ErrInviteLinkNotFound = errors.New("invite link not found")
+	ErrInviteLinkExpired  = errors.New("invite link expired, revoked or used up")
+	ErrAlreadyGroupMember = errors.New("user is already a member of the group")
+)
+
+// InviteLinkService handles the shareable invite links of the groups.
+type InviteLinkService struct {
+	db     *sql.DB
+	Groups *GroupService
+}
+
+// NewInviteLinkService creates a new InviteLinkService.
+func NewInviteLinkService(db *sql.DB, gs *GroupService) *InviteLinkService {
+	return &InviteLinkService{db: db, Groups: gs}
+}
+
+// newInviteToken returns a random URL-safe token.
+func newInviteToken() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+const inviteLinkColumns = `
+	SELECT id, group_id, token, created_by, role, max_uses, uses, expires_at, revoked_at, created_at
+	FROM group_invite_links
+`
+
+func scanInviteLink(scanner interface{ Scan(...any) error }) (*models.GroupInviteLink, error) {
+	link := &models.GroupInviteLink{}
+	var createdBy, maxUses sql.NullInt64
+	var expiresAt, revokedAt sql.NullTime
+	err := scanner.Scan(
+		&link.ID,
+		&link.GroupID,
+		&link.Token,
+		&createdBy,
+		&link.Role,
+		&maxUses,
+		&link.Uses,
+		&expiresAt,
+		&revokedAt,
+		&link.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		link.CreatedBy = &createdBy.Int64
+	}
+	if maxUses.Valid {
+		link.MaxUses = &maxUses.Int64
+	}
+	if expiresAt.Valid {
+		link.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	return link, nil
+}
+
+// usable reports whether a link can still be used to join.
+func usable(link *models.GroupInviteLink) bool {
+	return link.RevokedAt == nil &&
+		(link.ExpiresAt == nil || link.ExpiresAt.After(time.Now())) &&
+		(link.MaxUses == nil || link.Uses < *link.MaxUses)
+}
+
+// Create generates a link to the group. The role granted on join must be
+// below the role of the creator, so admins cannot mint other admins.
+func (s *InviteLinkService) Create(groupID, actorID int64, role string, maxUses *int64, expiresAt *time.Time) (*models.GroupInviteLink, error) {
+	if role == "" {
+		role = GroupRoleMember
+	}
+	if _, ok := groupRoleRanks[role]; !ok || role == GroupRoleOwner {
+		return nil, ErrInvalidGroupRole
+	}
+	if maxUses != nil && *maxUses <= 0 {
+		return nil, ErrInviteLinkExpired
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, ErrInviteLinkExpired
+	}
+	actorRole := s.Groups.GetRole(groupID, actorID)
+	if !GroupPermissions[actorRole][PermManageLinks] ||
+		(role != GroupRoleMember && groupRoleRanks[actorRole] <= groupRoleRanks[role]) {
+		return nil, ErrGroupForbidden
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO group_invite_links (group_id, token, created_by, role, max_uses, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, groupID, token, actorID, role, maxUses, expiresAt, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditLinkCreated, "invite_link", id, role); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return scanInviteLink(s.db.QueryRow(inviteLinkColumns+` WHERE id = ?`, id))
+}
+
+// List returns the links of a group, most recent first.
+func (s *InviteLinkService) List(groupID int64) ([]*models.GroupInviteLink, error) {
+	rows, err := s.db.Query(inviteLinkColumns+` WHERE group_id = ? ORDER BY created_at DESC, id DESC`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []*models.GroupInviteLink{}
+	for rows.Next() {
+		link, err := scanInviteLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// Revoke disables a link of the group and reports whether it was active.
+func (s *InviteLinkService) Revoke(groupID, actorID, linkID int64) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE group_invite_links SET revoked_at = ? WHERE id = ? AND group_id = ? AND revoked_at IS NULL
+	`, time.Now(), linkID, groupID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	if err := recordAudit(tx, groupID, actorID, AuditLinkRevoked, "invite_link", linkID, ""); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Uses lists the users who joined through a link of the group.
+func (s *InviteLinkService) Uses(groupID, linkID int64) ([]*models.GroupInviteLinkUse, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, COALESCE(u.avatar_path, ''), lu.joined_at
+		FROM group_invite_link_uses lu
+		JOIN group_invite_links l ON l.id = lu.link_id
+		JOIN users u ON u.id = lu.user_id
+		WHERE l.id = ? AND l.group_id = ?
+		ORDER BY lu.joined_at DESC, lu.id DESC
+	`, linkID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uses := []*models.GroupInviteLinkUse{}
+	for rows.Next() {
+		use := &models.GroupInviteLinkUse{}
+		if err := rows.Scan(&use.UserID, &use.Username, &use.AvatarPath, &use.JoinedAt); err != nil {
+			return nil, err
+		}
+		uses = append(uses, use)
+	}
+	return uses, rows.Err()
+}
+
+// linkByToken loads a link by its token.
+func (s *InviteLinkService) linkByToken(token string) (*models.GroupInviteLink, error) {
+	link, err := scanInviteLink(s.db.QueryRow(inviteLinkColumns+` WHERE token = ?`, token))
+	if err == sql.ErrNoRows {
+		return nil, ErrInviteLinkNotFound
+	}
+	return link, err
+}
+
+// usableLink loads a link by its token, if it can still be used.
+func (s *InviteLinkService) usableLink(token string) (*models.GroupInviteLink, error) {
+	link, err := s.linkByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if !usable(link) {
+		return nil, ErrInviteLinkExpired
+	}
+	return link, nil
+}
+
+// Preview describes the group a link leads to. The link is an invitation, so
+// secret groups are shown as well.
+func (s *InviteLinkService) Preview(token string, userID int64) (*models.GroupInvitePreview, error) {
+	link, err := s.usableLink(token)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.GroupInvitePreview{
+		GroupID:   link.GroupID,
+		Role:      link.Role,
+		ExpiresAt: link.ExpiresAt,
+		IsMember:  s.Groups.IsMember(link.GroupID, userID),
+	}
+	err = s.db.QueryRow(`
+		SELECT g.title, COALESCE(g.description, ''), g.visibility,
+			(SELECT COUNT(*) FROM group_members gm WHERE gm.group_id = g.id AND gm.accepted = 1)
+		FROM groups g WHERE g.id = ? AND g.deleted_at IS NULL
+	`, link.GroupID).Scan(&preview.Title, &preview.Description, &preview.Visibility, &preview.MemberCount)
+	if err == sql.ErrNoRows {
+		return nil, ErrInviteLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// Join adds the user to the group of the link with the role of the link, and
+// returns the group ID. Banned users cannot join.
+func (s *InviteLinkService) Join(token string, userID int64, username string) (int64, error) {
+	link, err := s.linkByToken(token)
+	if err != nil {
+		return 0, err
+	}
+	if s.Groups.GetVisibility(link.GroupID) == "" {
+		return 0, ErrInviteLinkNotFound
+	}
+	if s.Groups.IsMember(link.GroupID, userID) {
+		return 0, ErrAlreadyGroupMember
+	}
+	if !usable(link) {
+		return 0, ErrInviteLinkExpired
+	}
+	if s.Groups.IsBanned(link.GroupID, userID) {
+		return 0, ErrGroupBanned
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// La condition protège le nombre d'utilisations contre les accès concurrents
+	result, err := tx.Exec(`
+		UPDATE group_invite_links SET uses = uses + 1
+		WHERE id = ? AND revoked_at IS NULL AND (max_uses IS NULL OR uses < max_uses)
+	`, link.ID)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrInviteLinkExpired
+		}
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO group_members (group_id, user_id, username, accepted, role, created_at)
+		VALUES (?, ?, ?, 1, ?, ?)
+		ON CONFLICT (group_id, user_id) DO UPDATE SET accepted = 1, role = excluded.role
+	`, link.GroupID, userID, username, link.Role, time.Now()); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO group_invite_link_uses (link_id, user_id, joined_at) VALUES (?, ?, ?)
+	`, link.ID, userID, time.Now()); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM group_invitations WHERE group_id = ? AND invitee_id = ?
+	`, link.GroupID, userID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE group_join_requests SET status = 'withdrawn'
+		WHERE group_id = ? AND requester_id = ? AND status = 'pending'
+	`, link.GroupID, userID); err != nil {
+		return 0, err
+	}
+	if err := recordAudit(tx, link.GroupID, userID, AuditLinkJoined, "invite_link", link.ID, link.Role); err != nil {
+		return 0, err
+	}
+
+	return link.GroupID, tx.Commit()
+}
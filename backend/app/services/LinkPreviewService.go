@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"social-network/backend/database/models"
+)
+
+const (
+	// Maximum number of URLs unfurled for a single post or message
+	maxPreviewsPerContent = 3
+
+	// Only the beginning of the page is read, metadata lives in <head>
+	previewMaxBodySize = 512 * 1024
+
+	// Maximum number of redirects followed before giving up
+	previewMaxRedirects = 3
+
+	// A cached preview is fetched again after this delay
+	previewCacheTTL = 24 * time.Hour
+
+	// Maximum number of pages fetched at the same time
+	previewMaxConcurrentFetches = 4
+)
+
+// Time allowed to fetch a whole page, redirects included
+var previewFetchTimeout = 5 * time.Second
+
+var (
+	urlRegex     = regexp.MustCompile(`https?://[^\s<>"']+`)
+	metaTagRegex = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrRegex    = regexp.MustCompile(`(?is)([a-z:_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titleRegex   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+	errBlockedAddress = errors.New("link preview: address is not allowed")
+)
+
+// blockedNetworks lists the ranges a preview fetch must never reach, on top of
+// the loopback, private and link-local ranges known by the net package.
+var blockedNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"100.64.0.0/10",
+		"192.0.0.0/24",
+		"192.0.2.0/24",
+		"198.18.0.0/15",
+		"198.51.100.0/24",
+		"203.0.113.0/24",
+		"240.0.0.0/4",
+		"64:ff9b::/96",
+		"2001:db8::/32",
+	} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// LinkPreviewService detects URLs in user content and unfurls them.
+type LinkPreviewService struct {
+	db     *sql.DB
+	client *http.Client
+	slots  chan struct{}
+}
+
+// NewLinkPreviewService creates a new LinkPreviewService.
+func NewLinkPreviewService(db *sql.DB) *LinkPreviewService {
+	return &LinkPreviewService{
+		db:     db,
+		client: newPreviewClient(IsPublicIP),
+		slots:  make(chan struct{}, previewMaxConcurrentFetches),
+	}
+}
+
+// IsPublicIP reports whether a preview fetch may connect to the address.
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// newPreviewClient builds an HTTP client whose dialer refuses every address
+// rejected by allowed. The check runs on the resolved IP of each connection,
+// so redirects and DNS rebinding cannot reach internal services.
+func newPreviewClient(allowed func(net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: previewFetchTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !allowed(ip) {
+				return errBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: previewFetchTimeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   previewFetchTimeout,
+			ResponseHeaderTimeout: previewFetchTimeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > previewMaxRedirects {
+				return errors.New("link preview: too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return errors.New("link preview: unsupported redirect scheme")
+			}
+			return nil
+		},
+	}
+}
+
+// ExtractURLs returns the distinct http(s) URLs found in content.
+func ExtractURLs(content string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+
+	for _, match := range urlRegex.FindAllString(content, -1) {
+		match = strings.TrimRight(match, ".,;:!?)]}")
+		if seen[match] || len(match) > 2048 {
+			continue
+		}
+		if parsed, err := url.Parse(match); err != nil || parsed.Host == "" {
+			continue
+		}
+		seen[match] = true
+		urls = append(urls, match)
+		if len(urls) == maxPreviewsPerContent {
+			break
+		}
+	}
+
+	return urls
+}
+
+// Unfurl fetches and caches the previews of every URL found in content.
+// It blocks until all fetches are done, callers run it in a goroutine.
+func (s *LinkPreviewService) Unfurl(content string) []*models.LinkPreview {
+	for _, rawURL := range ExtractURLs(content) {
+		if s.isFresh(rawURL) {
+			continue
+		}
+
+		s.slots <- struct{}{}
+		preview, err := s.Fetch(rawURL)
+		<-s.slots
+
+		if err != nil {
+			log.Printf("Link preview failed for %s: %v", rawURL, err)
+			s.save(&models.LinkPreview{URL: rawURL, FetchedAt: time.Now()}, "failed")
+			continue
+		}
+		s.save(preview, "ok")
+	}
+
+	return s.GetPreviews(content)
+}
+
+// GetPreviews returns the cached previews of the URLs found in content.
+func (s *LinkPreviewService) GetPreviews(content string) []*models.LinkPreview {
+	var previews []*models.LinkPreview
+
+	for _, rawURL := range ExtractURLs(content) {
+		preview := &models.LinkPreview{}
+		err := s.db.QueryRow(`
+			SELECT id, url, title, description, image_url, site_name, fetched_at
+			FROM link_previews
+			WHERE url = ? AND status = 'ok'
+		`, rawURL).Scan(
+			&preview.ID,
+			&preview.URL,
+			&preview.Title,
+			&preview.Description,
+			&preview.ImageURL,
+			&preview.SiteName,
+			&preview.FetchedAt,
+		)
+		if err != nil {
+			continue
+		}
+		previews = append(previews, preview)
+	}
+
+	return previews
+}
+
+// Fetch downloads a page and reads its OpenGraph / Twitter card metadata.
+func (s *LinkPreviewService) Fetch(rawURL string) (*models.LinkPreview, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("link preview: unsupported scheme %q", parsed.Scheme)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), previewFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "SocialNetworkBot/1.0 (+link preview)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("link preview: unexpected status %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.Contains(contentType, "html") {
+		return nil, fmt.Errorf("link preview: unsupported content type %q", contentType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, previewMaxBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	preview := parsePreview(string(body), resp.Request.URL)
+	if preview.Title == "" && preview.Description == "" {
+		return nil, errors.New("link preview: no metadata found")
+	}
+	preview.URL = rawURL
+	preview.FetchedAt = time.Now()
+
+	return preview, nil
+}
+
+// parsePreview reads the metadata of an HTML page. OpenGraph values win over
+// Twitter card values, which win over the plain <title> and description.
+func parsePreview(page string, base *url.URL) *models.LinkPreview {
+	meta := make(map[string]string)
+	for _, tag := range metaTagRegex.FindAllString(page, -1) {
+		var key, content string
+		for _, attr := range attrRegex.FindAllStringSubmatch(tag, -1) {
+			value := attr[2] + attr[3] + attr[4]
+			switch strings.ToLower(attr[1]) {
+			case "property", "name":
+				key = strings.ToLower(value)
+			case "content":
+				content = value
+			}
+		}
+		if key != "" && content != "" {
+			if _, exists := meta[key]; !exists {
+				meta[key] = strings.TrimSpace(html.UnescapeString(content))
+			}
+		}
+	}
+
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if meta[key] != "" {
+				return meta[key]
+			}
+		}
+		return ""
+	}
+
+	preview := &models.LinkPreview{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		ImageURL:    first("og:image", "og:image:url", "twitter:image", "twitter:image:src"),
+		SiteName:    first("og:site_name"),
+	}
+
+	if preview.Title == "" {
+		if match := titleRegex.FindStringSubmatch(page); match != nil {
+			preview.Title = strings.TrimSpace(html.UnescapeString(match[1]))
+		}
+	}
+
+	if preview.ImageURL != "" && base != nil {
+		if image, err := base.Parse(preview.ImageURL); err == nil && (image.Scheme == "http" || image.Scheme == "https") {
+			preview.ImageURL = image.String()
+		} else {
+			preview.ImageURL = ""
+		}
+	}
+
+	preview.Title = truncate(preview.Title, 300)
+	preview.Description = truncate(preview.Description, 1000)
+	preview.SiteName = truncate(preview.SiteName, 100)
+	preview.ImageURL = truncate(preview.ImageURL, 2048)
+
+	return preview
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
+
+// isFresh tells if the URL was fetched recently, successfully or not.
+func (s *LinkPreviewService) isFresh(rawURL string) bool {
+	var fetchedAt time.Time
+	err := s.db.QueryRow(`SELECT fetched_at FROM link_previews WHERE url = ?`, rawURL).Scan(&fetchedAt)
+	if err != nil {
+		return false
+	}
+	return time.Since(fetchedAt) < previewCacheTTL
+}
+
+func (s *LinkPreviewService) save(preview *models.LinkPreview, status string) {
+	_, err := s.db.Exec(`
+		INSERT INTO link_previews (url, title, description, image_url, site_name, status, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			image_url = excluded.image_url,
+			site_name = excluded.site_name,
+			status = excluded.status,
+			fetched_at = excluded.fetched_at
+	`, preview.URL, preview.Title, preview.Description, preview.ImageURL, preview.SiteName, status, preview.FetchedAt)
+	if err != nil {
+		log.Printf("Error saving link preview for %s: %v", preview.URL, err)
+	}
+}
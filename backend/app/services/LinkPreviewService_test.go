@@ -0,0 +1,277 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const previewPage = `<html><head>
+<title>Plain title</title>
+<meta property="og:title" content="Open Graph title">
+<meta name="description" content="Plain description">
+<meta property="og:image" content="/images/cover.png">
+</head><body></body></html>`
+
+// newTestPreviewService returns a service whose client may only reach the
+// loopback address used by httptest servers.
+func newTestPreviewService() *LinkPreviewService {
+	return &LinkPreviewService{
+		client: newPreviewClient(func(ip net.IP) bool { return ip.Equal(net.IPv4(127, 0, 0, 1)) }),
+		slots:  make(chan struct{}, previewMaxConcurrentFetches),
+	}
+}
+
+func servePreview(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, body)
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for _, tc := range []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+	} {
+		if got := IsPublicIP(net.ParseIP(tc.ip)); got != tc.want {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", tc.ip, got, tc.want)
+		}
+	}
+}
+
+func TestFetchRefusesBlockedAddresses(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		servePreview(w, previewPage)
+	}))
+	defer server.Close()
+
+	s := &LinkPreviewService{client: newPreviewClient(IsPublicIP)}
+	if _, err := s.Fetch(server.URL); !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("Fetch on loopback: got %v, want %v", err, errBlockedAddress)
+	}
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	for _, host := range []string{"10.0.0.1", "192.168.0.1", "169.254.169.254", "[::1]", "[fe80::1]"} {
+		if _, err := s.Fetch("http://" + host + ":" + port + "/"); !errors.Is(err, errBlockedAddress) {
+			t.Errorf("Fetch on %s: got %v, want %v", host, err, errBlockedAddress)
+		}
+	}
+	if hits != 0 {
+		t.Errorf("server reached %d times, want 0", hits)
+	}
+}
+
+func TestFetchRefusesRedirectToBlockedAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, port, _ := net.SplitHostPort(r.Host)
+		http.Redirect(w, r, "http://127.0.0.2:"+port+"/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	if _, err := newTestPreviewService().Fetch(server.URL); !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("got %v, want %v", err, errBlockedAddress)
+	}
+}
+
+func TestFetchParsesPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		servePreview(w, previewPage)
+	}))
+	defer server.Close()
+
+	preview, err := newTestPreviewService().Fetch(server.URL + "/article")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if preview.URL != server.URL+"/article" {
+		t.Errorf("URL = %q", preview.URL)
+	}
+	if preview.Title != "Open Graph title" {
+		t.Errorf("Title = %q, want the og:title", preview.Title)
+	}
+	if preview.Description != "Plain description" {
+		t.Errorf("Description = %q, want the description fallback", preview.Description)
+	}
+	if preview.ImageURL != server.URL+"/images/cover.png" {
+		t.Errorf("ImageURL = %q, want it resolved against the page", preview.ImageURL)
+	}
+}
+
+func TestFetchRejectsUnusableResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"title":"nope"}`)
+		case "/empty":
+			servePreview(w, "<html><body>no metadata</body></html>")
+		}
+	}))
+	defer server.Close()
+
+	s := newTestPreviewService()
+	for _, path := range []string{"/missing", "/json", "/empty"} {
+		if _, err := s.Fetch(server.URL + path); err == nil {
+			t.Errorf("Fetch %s: expected an error", path)
+		}
+	}
+	if _, err := s.Fetch("ftp://example.com/file"); err == nil {
+		t.Error("Fetch ftp: expected an error")
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { previewFetchTimeout = timeout }(previewFetchTimeout)
+	previewFetchTimeout = 200 * time.Millisecond
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	started := time.Now()
+	if _, err := newTestPreviewService().Fetch(server.URL); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("Fetch took %v, want about %v", elapsed, previewFetchTimeout)
+	}
+}
+
+func TestFetchBodySizeLimit(t *testing.T) {
+	padding := strings.Repeat("x", previewMaxBodySize)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/late":
+			// Les métadonnées arrivent après la limite de lecture
+			servePreview(w, "<html><!--"+padding+"--><head><title>Too late</title></head></html>")
+		case "/early":
+			servePreview(w, "<html><head><title>In time</title></head><body>"+padding+padding+"</body></html>")
+		}
+	}))
+	defer server.Close()
+
+	s := newTestPreviewService()
+	if preview, err := s.Fetch(server.URL + "/late"); err == nil {
+		t.Errorf("Fetch /late: got %q, want metadata past the limit to be ignored", preview.Title)
+	}
+	preview, err := s.Fetch(server.URL + "/early")
+	if err != nil {
+		t.Fatalf("Fetch /early: %v", err)
+	}
+	if preview.Title != "In time" {
+		t.Errorf("Title = %q", preview.Title)
+	}
+}
+
+func TestFetchRedirectLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var hops int
+		fmt.Sscanf(r.URL.Path, "/hops/%d", &hops)
+		if hops > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hops/%d", hops-1), http.StatusFound)
+			return
+		}
+		servePreview(w, previewPage)
+	}))
+	defer server.Close()
+
+	s := newTestPreviewService()
+	if _, err := s.Fetch(fmt.Sprintf("%s/hops/%d", server.URL, previewMaxRedirects)); err != nil {
+		t.Errorf("Fetch with %d redirects: %v", previewMaxRedirects, err)
+	}
+	if _, err := s.Fetch(fmt.Sprintf("%s/hops/%d", server.URL, previewMaxRedirects+1)); err == nil {
+		t.Errorf("Fetch with %d redirects: expected an error", previewMaxRedirects+1)
+	}
+}
+
+func TestParsePreview(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post")
+
+	for _, tc := range []struct {
+		name string
+		page string
+		want [4]string // title, description, image, site name
+	}{
+		{
+			name: "open graph wins over twitter and plain tags",
+			page: `<title>Plain</title>
+				<meta name="twitter:title" content="Twitter">
+				<meta property="og:title" content="OG">
+				<meta name="description" content="Plain desc">
+				<meta name="twitter:description" content="Twitter desc">
+				<meta property="og:description" content="OG desc">
+				<meta name="twitter:image" content="https://cdn.example.com/t.png">
+				<meta property="og:image" content="https://cdn.example.com/og.png">
+				<meta property="og:site_name" content="Example">`,
+			want: [4]string{"OG", "OG desc", "https://cdn.example.com/og.png", "Example"},
+		},
+		{
+			name: "twitter card fallback",
+			page: `<title>Plain</title>
+				<meta name="twitter:title" content="Twitter">
+				<meta name="description" content="Plain desc">
+				<meta name="twitter:description" content="Twitter desc">
+				<meta name="twitter:image:src" content="t.png">`,
+			want: [4]string{"Twitter", "Twitter desc", "https://example.com/blog/t.png", ""},
+		},
+		{
+			name: "plain title and description fallback",
+			page: `<TITLE> Plain &amp; simple </TITLE><meta content='Plain desc' name='description'>`,
+			want: [4]string{"Plain & simple", "Plain desc", "", ""},
+		},
+		{
+			name: "first tag of a kind wins and entities are decoded",
+			page: `<meta property="og:title" content="Tom &amp; Jerry"><meta property="og:title" content="Second">`,
+			want: [4]string{"Tom & Jerry", "", "", ""},
+		},
+		{
+			name: "non http image is dropped",
+			page: `<meta property="og:title" content="OG"><meta property="og:image" content="javascript:alert(1)">`,
+			want: [4]string{"OG", "", "", ""},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			preview := parsePreview(tc.page, base)
+			got := [4]string{preview.Title, preview.Description, preview.ImageURL, preview.SiteName}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePreviewTruncates(t *testing.T) {
+	page := `<meta property="og:title" content="` + strings.Repeat("é", 400) + `">`
+	if title := parsePreview(page, nil).Title; len([]rune(title)) != 300 {
+		t.Errorf("title has %d runes, want 300", len([]rune(title)))
+	}
+}
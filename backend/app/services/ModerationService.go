@@ -0,0 +1,228 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"social-network/backend/database/models"
+)
+
+// User roles.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Report target types.
+const (
+	TargetPost         = "post"
+	TargetComment      = "comment"
+	TargetMessage      = "message"
+	TargetGroupMessage = "group_message"
+	TargetUser         = "user"
+)
+
+// ActiveUserCondition filters out suspended users in queries aliasing users as u.
+const ActiveUserCondition = `NOT (u.suspended = 1 AND (u.suspended_until IS NULL OR u.suspended_until > CURRENT_TIMESTAMP))`
+
+var (
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrInvalidTargetType = errors.New("invalid target type")
+)
+
+// ReportReasons lists the accepted report reasons.
+var ReportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate_speech":    true,
+	"violence":       true,
+	"nudity":         true,
+	"misinformation": true,
+	"other":          true,
+}
+
+// hideableTables maps the content target types to their table.
+var hideableTables = map[string]string{
+	TargetPost:         "posts",
+	TargetComment:      "comments",
+	TargetMessage:      "messages",
+	TargetGroupMessage: "group_messages",
+}
+
+// ModerationService handles roles, suspensions and hidden content.
+type ModerationService struct {
+	db *sql.DB
+}
+
+// NewModerationService creates a new ModerationService.
+func NewModerationService(db *sql.DB) *ModerationService {
+	return &ModerationService{db: db}
+}
+
+// IsValidTargetType reports whether a report can target this type.
+func IsValidTargetType(targetType string) bool {
+	_, ok := hideableTables[targetType]
+	return ok || targetType == TargetUser
+}
+
+// GetRole returns the role of a user, RoleUser when unknown.
+func (s *ModerationService) GetRole(userID int64) string {
+	var role string
+	if err := s.db.QueryRow(`SELECT role FROM users WHERE id = ?`, userID).Scan(&role); err != nil {
+		return RoleUser
+	}
+	return role
+}
+
+// IsModerator reports whether the user can act on the moderation queue.
+func (s *ModerationService) IsModerator(userID int64) bool {
+	role := s.GetRole(userID)
+	return role == RoleModerator || role == RoleAdmin
+}
+
+// IsAdmin reports whether the user can manage roles.
+func (s *ModerationService) IsAdmin(userID int64) bool {
+	return s.GetRole(userID) == RoleAdmin
+}
+
+// SetRole changes the role of a user.
+func (s *ModerationService) SetRole(userID int64, role string) error {
+	if role != RoleUser && role != RoleModerator && role != RoleAdmin {
+		return ErrInvalidRole
+	}
+	result, err := s.db.Exec(`UPDATE users SET role = ?, updated_at = ? WHERE id = ?`, role, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PromoteAdmin gives the admin role to the user owning this email.
+func (s *ModerationService) PromoteAdmin(email string) error {
+	result, err := s.db.Exec(`UPDATE users SET role = ? WHERE email = ?`, RoleAdmin, email)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// IsSuspended reports whether the user is currently suspended.
+func (s *ModerationService) IsSuspended(userID int64) bool {
+	var suspended bool
+	err := s.db.QueryRow(`
+		SELECT NOT (`+ActiveUserCondition+`)
+		FROM users u WHERE u.id = ?
+	`, userID).Scan(&suspended)
+	return err == nil && suspended
+}
+
+// Suspend suspends a user until the given date, or indefinitely when nil.
+func (s *ModerationService) Suspend(userID int64, until *time.Time) error {
+	if until != nil {
+		// Stocké en UTC pour être comparable à CURRENT_TIMESTAMP
+		utc := until.UTC()
+		until = &utc
+	}
+	result, err := s.db.Exec(`
+		UPDATE users SET suspended = 1, suspended_until = ?, updated_at = ? WHERE id = ?
+	`, until, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	// Les sessions ouvertes sont fermées
+	_, err = s.db.Exec(`DELETE FROM sessions WHERE user_id = ?`, userID)
+	return err
+}
+
+// IsSuspensionActive reports whether a loaded user is currently suspended.
+func IsSuspensionActive(user *models.User) bool {
+	return user.Suspended && (user.SuspendedUntil == nil || user.SuspendedUntil.After(time.Now()))
+}
+
+// Unsuspend lifts the suspension of a user.
+func (s *ModerationService) Unsuspend(userID int64) error {
+	result, err := s.db.Exec(`
+		UPDATE users SET suspended = 0, suspended_until = NULL, updated_at = ? WHERE id = ?
+	`, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetHidden hides or restores a piece of content.
+func (s *ModerationService) SetHidden(targetType string, targetID int64, hidden bool) error {
+	table, ok := hideableTables[targetType]
+	if !ok {
+		return ErrInvalidTargetType
+	}
+	result, err := s.db.Exec(fmt.Sprintf(`UPDATE %s SET is_hidden = ? WHERE id = ?`, table), hidden, targetID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetTarget returns a snapshot of the reported content or user.
+// sql.ErrNoRows is returned when the target does not exist.
+func (s *ModerationService) GetTarget(targetType string, targetID int64) (*models.ReportedTarget, error) {
+	target := &models.ReportedTarget{}
+
+	if targetType == TargetUser {
+		var suspended bool
+		err := s.db.QueryRow(`
+			SELECT u.id, COALESCE(u.username, ''), COALESCE(u.about_me, ''), NOT (`+ActiveUserCondition+`)
+			FROM users u WHERE u.id = ?
+		`, targetID).Scan(&target.AuthorID, &target.AuthorUsername, &target.Content, &suspended)
+		if err != nil {
+			return nil, err
+		}
+		target.Hidden = suspended
+		return target, nil
+	}
+
+	table, ok := hideableTables[targetType]
+	if !ok {
+		return nil, ErrInvalidTargetType
+	}
+	authorColumn := "user_id"
+	if targetType == TargetMessage {
+		authorColumn = "sender_id"
+	}
+
+	err := s.db.QueryRow(fmt.Sprintf(`
+		SELECT t.%s, COALESCE(u.username, ''), t.content, t.is_hidden
+		FROM %s t
+		JOIN users u ON u.id = t.%s
+		WHERE t.id = ?
+	`, authorColumn, table, authorColumn), targetID).Scan(
+		&target.AuthorID,
+		&target.AuthorUsername,
+		&target.Content,
+		&target.Hidden,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
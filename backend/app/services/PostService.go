@@ -2,17 +2,25 @@ package services
 
 import (
 	"database/sql"
+	"errors"
 	"social-network/backend/database/models"
+	"strings"
 )
 
+// ErrPostNotVisible is returned when the viewer is not in the post audience.
+var ErrPostNotVisible = errors.New("post not visible")
+
 // UserService is a service for managing users.
 type PostService struct {
-	db *sql.DB
+	db           *sql.DB
+	LinkPreviews *LinkPreviewService
+	Blocks       *BlockService
+	Groups       *GroupService
 }
 
 // NewUserService creates a new UserService.
-func NewPostService(db *sql.DB) *PostService {
-	return &PostService{db: db}
+func NewPostService(db *sql.DB, lps *LinkPreviewService, bs *BlockService, gs *GroupService) *PostService {
+	return &PostService{db: db, LinkPreviews: lps, Blocks: bs, Groups: gs}
 }
 
 func (s *PostService) GetPostAuthor(post *models.Post) (*models.User, error) {
@@ -66,6 +74,13 @@ func (s *PostService) GetLikes(post_id int64) ([]string, error) {
 	return likes, nil
 }
 
+// AttachLinkPreviews fills the cached link previews of a post.
+func (s *PostService) AttachLinkPreviews(post *models.Post) {
+	if s.LinkPreviews != nil {
+		post.LinkPreviews = s.LinkPreviews.GetPreviews(post.Content)
+	}
+}
+
 func (s *PostService) CheckPrivacy(post_id int64, user_id int64) bool {
 	stmt, err := s.db.Prepare(`
 	SELECT id FROM post_privacy WHERE post_id = ? AND user_id = ?;
@@ -73,6 +88,7 @@ func (s *PostService) CheckPrivacy(post_id int64, user_id int64) bool {
 	if err != nil {
 		return false
 	}
+	defer stmt.Close()
 	var id int
 	if err = stmt.QueryRow(post_id, user_id).Scan(&id); err != nil {
 		return false
@@ -80,6 +96,7 @@ func (s *PostService) CheckPrivacy(post_id int64, user_id int64) bool {
 	return true
 }
 
+// IsAuthorFriend reports whether the author and the user follow each other.
 func (s *PostService) IsAuthorFriend(author_id int64, user_id int64) bool {
 	stmt, err := s.db.Prepare(`
 		SELECT count(f1.follower_id) FROM followers f1
@@ -89,11 +106,12 @@ func (s *PostService) IsAuthorFriend(author_id int64, user_id int64) bool {
 	if err != nil {
 		return false
 	}
+	defer stmt.Close()
 	var count int
 	if err = stmt.QueryRow(author_id, user_id, author_id, user_id).Scan(&count); err != nil {
 		return false
 	}
-	return true
+	return count > 0
 }
 
 func (s *PostService) GetCurrentViewers(post_id int64) ([]int64, error) {
@@ -131,3 +149,241 @@ func (s *PostService) DeletePostCurrentViewers(post_id int64) error {
 
 	return nil
 }
+
+// Reasons given by EvaluateVisibility.
+const (
+	VisibilityAuthor      = "author"
+	VisibilityPublic      = "public"
+	VisibilityFriend      = "friend"
+	VisibilityViewer      = "selected_viewer"
+	VisibilityNotFriend   = "not_friend"
+	VisibilityNotSelected = "not_selected_viewer"
+	VisibilityAnonymous   = "not_authenticated"
+	VisibilityModerated   = "moderated"
+	VisibilityGroupMember = "group_member"
+	VisibilityNotMember   = "not_group_member"
+	VisibilityPublicGroup = "public_group"
+	VisibilityBlocked     = "blocked"
+	VisibilityNotVisible  = "not_visible" // what the author is told instead of VisibilityBlocked
+	VisibilityGroupGone   = "deleted_group"
+)
+
+// VisibilityDecision tells whether a user can see a post and why.
+type VisibilityDecision struct {
+	PostID  int64  `json:"post_id"`
+	UserID  int64  `json:"user_id"`
+	Visible bool   `json:"visible"`
+	Reason  string `json:"reason"`
+}
+
+// PostAudience describes everyone who can see a post.
+type PostAudience struct {
+	PostID      int64          `json:"post_id"`
+	PrivacyType int64          `json:"privacy_type"`
+	Audience    string         `json:"audience"` // "public", "friends", "selected", "group"
+	GroupID     *int64         `json:"group_id,omitempty"`
+	Friends     []*models.User `json:"friends"`
+	Viewers     []*models.User `json:"viewers"`
+	Members     []*models.User `json:"members,omitempty"`
+}
+
+// IsModerated reports whether a post was hidden by a moderator or its author
+// is suspended. Such a post is visible to nobody.
+func (s *PostService) IsModerated(post_id int64) bool {
+	var moderated bool
+	err := s.db.QueryRow(`
+		SELECT p.is_hidden OR NOT (`+ActiveUserCondition+`)
+		FROM posts p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.id = ?
+	`, post_id).Scan(&moderated)
+	return err == nil && moderated
+}
+
+// IsDeletedGroup reports whether the group was deleted and waits for its purge.
+func (s *PostService) IsDeletedGroup(group_id int64) bool {
+	var deleted bool
+	err := s.db.QueryRow(`SELECT deleted_at IS NOT NULL FROM groups WHERE id = ?`, group_id).Scan(&deleted)
+	return err == nil && deleted
+}
+
+// EvaluateVisibility is the single place deciding if a user can read a post.
+// A user ID of 0 stands for an anonymous viewer.
+func (s *PostService) EvaluateVisibility(post *models.Post, user_id int64) *VisibilityDecision {
+	decision := &VisibilityDecision{PostID: post.ID, UserID: user_id}
+
+	switch {
+	case s.IsModerated(post.ID):
+		decision.Reason = VisibilityModerated
+	case post.GroupID != nil && s.IsDeletedGroup(*post.GroupID):
+		decision.Reason = VisibilityGroupGone
+	case user_id != 0 && post.UserID == user_id:
+		decision.Visible, decision.Reason = true, VisibilityAuthor
+	case s.Blocks != nil && s.Blocks.IsBlocked(post.UserID, user_id):
+		decision.Reason = VisibilityBlocked
+	case post.GroupID != nil && user_id == 0:
+		decision.Reason = VisibilityAnonymous
+	case post.GroupID != nil && s.Groups.IsMember(*post.GroupID, user_id):
+		decision.Visible, decision.Reason = true, VisibilityGroupMember
+	case post.GroupID != nil && s.Groups.GetVisibility(*post.GroupID) == GroupPublic:
+		decision.Visible, decision.Reason = true, VisibilityPublicGroup
+	case post.GroupID != nil:
+		decision.Reason = VisibilityNotMember
+	case post.PrivacyType == 0:
+		decision.Visible, decision.Reason = true, VisibilityPublic
+	case user_id == 0:
+		decision.Reason = VisibilityAnonymous
+	case s.CheckPrivacy(post.ID, user_id):
+		decision.Visible, decision.Reason = true, VisibilityViewer
+	case post.PrivacyType == 1 && s.IsAuthorFriend(post.UserID, user_id):
+		decision.Visible, decision.Reason = true, VisibilityFriend
+	case post.PrivacyType == 1:
+		decision.Reason = VisibilityNotFriend
+	default:
+		decision.Reason = VisibilityNotSelected
+	}
+
+	return decision
+}
+
+// VisiblePostCondition is the SQL counterpart of EvaluateVisibility, used by
+// the list queries so that they do not evaluate every row one by one. The
+// posts table must be aliased p, and the returned arguments bound in place of
+// the condition. TestVisiblePostConditionMatchesEvaluateVisibility checks that
+// both agree.
+func VisiblePostCondition(user_id int64) (string, []any) {
+	condition := `(p.is_hidden = 0
+		AND EXISTS (SELECT 1 FROM users u WHERE u.id = p.user_id AND ` + ActiveUserCondition + `)
+		AND (p.group_id IS NULL OR EXISTS (
+			SELECT 1 FROM groups g WHERE g.id = p.group_id AND g.deleted_at IS NULL))
+		AND (p.user_id = ? OR (` + NotBlockedCondition("p.user_id") + ` AND CASE
+			WHEN p.group_id IS NOT NULL THEN ? != 0 AND (
+				EXISTS (SELECT 1 FROM group_members gm
+					WHERE gm.group_id = p.group_id AND gm.user_id = ? AND gm.accepted = 1)
+				OR EXISTS (SELECT 1 FROM groups g
+					WHERE g.id = p.group_id AND g.visibility = '` + GroupPublic + `'))
+			WHEN p.privacy_type = 0 THEN 1
+			WHEN EXISTS (SELECT 1 FROM post_privacy pp WHERE pp.post_id = p.id AND pp.user_id = ?) THEN 1
+			WHEN p.privacy_type = 1 THEN
+				EXISTS (SELECT 1 FROM followers f1
+					WHERE f1.follower_id = ? AND f1.followed_id = p.user_id AND f1.accepted = TRUE)
+				AND EXISTS (SELECT 1 FROM followers f2
+					WHERE f2.follower_id = p.user_id AND f2.followed_id = ? AND f2.accepted = TRUE)
+			ELSE 0
+		END)))`
+
+	// Chaque paramètre de la condition est l'identifiant du lecteur
+	args := make([]any, strings.Count(condition, "?"))
+	for i := range args {
+		args[i] = user_id
+	}
+	return condition, args
+}
+
+// CanView is a shortcut for EvaluateVisibility(post, user_id).Visible.
+func (s *PostService) CanView(post *models.Post, user_id int64) bool {
+	return s.EvaluateVisibility(post, user_id).Visible
+}
+
+// GetAudience lists the effective audience of a post.
+func (s *PostService) GetAudience(post *models.Post) (*PostAudience, error) {
+	audience := &PostAudience{
+		PostID:      post.ID,
+		PrivacyType: post.PrivacyType,
+		Friends:     []*models.User{},
+		Viewers:     []*models.User{},
+	}
+
+	if post.GroupID != nil {
+		audience.Audience = "group"
+		audience.GroupID = post.GroupID
+		members, err := s.getGroupMembers(*post.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		audience.Members = members
+		return audience, nil
+	}
+
+	switch post.PrivacyType {
+	case 0:
+		audience.Audience = "public"
+	case 1:
+		audience.Audience = "friends"
+		friends, err := s.getAuthorFriends(post.UserID)
+		if err != nil {
+			return nil, err
+		}
+		audience.Friends = friends
+	default:
+		audience.Audience = "selected"
+	}
+
+	viewers, err := s.getSelectedViewers(post.ID)
+	if err != nil {
+		return nil, err
+	}
+	audience.Viewers = viewers
+
+	return audience, nil
+}
+
+func (s *PostService) getAuthorFriends(author_id int64) ([]*models.User, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, u.avatar_path
+		FROM users u
+		INNER JOIN followers f1 ON f1.followed_id = u.id AND f1.follower_id = ? AND f1.accepted = TRUE
+		INNER JOIN followers f2 ON f2.follower_id = u.id AND f2.followed_id = ? AND f2.accepted = TRUE
+		ORDER BY u.username
+	`, author_id, author_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAudienceUsers(rows)
+}
+
+func (s *PostService) getGroupMembers(group_id int64) ([]*models.User, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, u.avatar_path
+		FROM group_members gm
+		JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = ? AND gm.accepted = 1
+		ORDER BY u.username
+	`, group_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAudienceUsers(rows)
+}
+
+func (s *PostService) getSelectedViewers(post_id int64) ([]*models.User, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, u.avatar_path
+		FROM post_privacy pp
+		JOIN users u ON u.id = pp.user_id
+		WHERE pp.post_id = ?
+		ORDER BY u.username
+	`, post_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAudienceUsers(rows)
+}
+
+func scanAudienceUsers(rows *sql.Rows) ([]*models.User, error) {
+	users := []*models.User{}
+	for rows.Next() {
+		user := &models.User{}
+		if err := rows.Scan(&user.ID, &user.Username, &user.AvatarPath); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
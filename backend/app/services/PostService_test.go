@@ -0,0 +1,135 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"social-network/backend/database/models"
+	"social-network/backend/database/sqlite"
+)
+
+// newTestDB returns a migrated database in a temporary directory.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"), "../../database/migrations/sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// mustExec runs the statements of a seed, failing the test on error.
+func mustExec(t *testing.T, db *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", strings.TrimSpace(query), err)
+	}
+}
+
+func insertTestUser(t *testing.T, db *sql.DB, id int64, name string) {
+	t.Helper()
+	mustExec(t, db, `
+		INSERT INTO users (id, email, password_hash, first_name, last_name, birth_date, username)
+		VALUES (?, ?, ?, ?, 'Test', '2000-01-01', ?)
+	`, id, name+"@example.com", strings.Repeat("x", 60), name, name)
+}
+
+// Users of the visibility fixture.
+const (
+	visAuthor int64 = iota + 1
+	visFriend
+	visFollower
+	visViewer
+	visBlocker
+	visStranger
+	visMember
+	visSuspended
+)
+
+// TestVisiblePostConditionMatchesEvaluateVisibility runs every post of the
+// fixture through both visibility paths, for every viewer.
+func TestVisiblePostConditionMatchesEvaluateVisibility(t *testing.T) {
+	db := newTestDB(t)
+	for id, name := range map[int64]string{
+		visAuthor: "author", visFriend: "friend", visFollower: "follower", visViewer: "viewer",
+		visBlocker: "blocker", visStranger: "stranger", visMember: "member", visSuspended: "suspended",
+	} {
+		insertTestUser(t, db, id, name)
+	}
+	mustExec(t, db, `UPDATE users SET suspended = 1 WHERE id = ?`, visSuspended)
+
+	// L'ami suit l'auteur en retour, le follower non
+	mustExec(t, db, `INSERT INTO followers (follower_id, followed_id, accepted) VALUES (?, ?, 1), (?, ?, 1), (?, ?, 1)`,
+		visFriend, visAuthor, visAuthor, visFriend, visFollower, visAuthor)
+	mustExec(t, db, `INSERT INTO user_blocks (blocker_id, blocked_id) VALUES (?, ?)`, visBlocker, visAuthor)
+
+	groups := map[string]int64{}
+	for i, visibility := range []string{GroupPublic, GroupPrivate, GroupSecret, "deleted"} {
+		id := int64(i + 1)
+		groups[visibility] = id
+		vis := visibility
+		if vis == "deleted" {
+			vis = GroupPublic
+		}
+		mustExec(t, db, `
+			INSERT INTO groups (id, creator_id, creator_name, title, visibility) VALUES (?, ?, 'author', ?, ?)
+		`, id, visAuthor, visibility, vis)
+		mustExec(t, db, `
+			INSERT INTO group_members (group_id, user_id, username, accepted, role) VALUES (?, ?, 'member', 1, 'member')
+		`, id, visMember)
+		mustExec(t, db, `
+			INSERT INTO group_members (group_id, user_id, username, accepted, role) VALUES (?, ?, 'stranger', 0, 'member')
+		`, id, visStranger)
+	}
+	mustExec(t, db, `UPDATE groups SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?`, groups["deleted"])
+
+	var posts []*models.Post
+	addPost := func(userID, privacy int64, groupID *int64, hidden bool, viewers ...int64) {
+		post := &models.Post{ID: int64(len(posts) + 1), UserID: userID, PrivacyType: privacy, GroupID: groupID}
+		mustExec(t, db, `
+			INSERT INTO posts (id, user_id, content, privacy_type, group_id, is_hidden) VALUES (?, ?, 'post', ?, ?, ?)
+		`, post.ID, userID, privacy, groupID, hidden)
+		for _, viewer := range viewers {
+			mustExec(t, db, `INSERT INTO post_privacy (post_id, user_id) VALUES (?, ?)`, post.ID, viewer)
+		}
+		posts = append(posts, post)
+	}
+	group := func(visibility string) *int64 {
+		id := groups[visibility]
+		return &id
+	}
+	for _, userID := range []int64{visAuthor, visBlocker, visSuspended} {
+		addPost(userID, 0, nil, false)
+		addPost(userID, 1, nil, false)
+		addPost(userID, 1, nil, false, visFollower)
+		addPost(userID, 2, nil, false, visViewer)
+		addPost(userID, 0, nil, true)
+		for _, visibility := range []string{GroupPublic, GroupPrivate, GroupSecret, "deleted"} {
+			addPost(userID, 0, group(visibility), false)
+		}
+	}
+	addPost(visMember, 0, group(GroupPrivate), false)
+	addPost(visMember, 2, nil, false, visAuthor)
+
+	ps := NewPostService(db, nil, NewBlockService(db), NewGroupService(db))
+	for _, viewer := range []int64{0, visAuthor, visFriend, visFollower, visViewer, visBlocker, visStranger, visMember, visSuspended} {
+		condition, args := VisiblePostCondition(viewer)
+		for _, post := range posts {
+			decision := ps.EvaluateVisibility(post, viewer)
+
+			var visible bool
+			query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM posts p WHERE p.id = ? AND %s)`, condition)
+			if err := db.QueryRow(query, append([]any{post.ID}, args...)...).Scan(&visible); err != nil {
+				t.Fatal(err)
+			}
+			if visible != decision.Visible {
+				t.Errorf("post %d (author %d, privacy %d, group %v) for viewer %d: SQL says %v, EvaluateVisibility says %v (%s)",
+					post.ID, post.UserID, post.PrivacyType, post.GroupID != nil, viewer, visible, decision.Visible, decision.Reason)
+			}
+		}
+	}
+}
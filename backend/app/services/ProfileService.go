@@ -0,0 +1,133 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"social-network/backend/database/models"
+)
+
+// Visibility of a profile field.
+const (
+	FieldPublic    = "public"
+	FieldFollowers = "followers"
+	FieldOnlyMe    = "only_me"
+)
+
+// ErrInvalidFieldVisibility is returned for an unknown field visibility.
+var ErrInvalidFieldVisibility = errors.New("invalid field visibility, expected public, followers or only_me")
+
+// DefaultProfilePrivacy applies to users who never changed their settings.
+var DefaultProfilePrivacy = models.ProfilePrivacy{
+	Email:     FieldFollowers,
+	BirthDate: FieldFollowers,
+	AboutMe:   FieldPublic,
+	RealName:  FieldPublic,
+}
+
+// ProfileService projects users into the profiles other users can see.
+type ProfileService struct {
+	db *sql.DB
+}
+
+// NewProfileService creates a new ProfileService.
+func NewProfileService(db *sql.DB) *ProfileService {
+	return &ProfileService{db: db}
+}
+
+// GetPrivacy returns the field visibility settings of a user.
+func (s *ProfileService) GetPrivacy(userID int64) (*models.ProfilePrivacy, error) {
+	privacy := DefaultProfilePrivacy
+	err := s.db.QueryRow(`
+		SELECT email, birth_date, about_me, real_name FROM profile_privacy WHERE user_id = ?
+	`, userID).Scan(&privacy.Email, &privacy.BirthDate, &privacy.AboutMe, &privacy.RealName)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &privacy, nil
+}
+
+// SetPrivacy stores the field visibility settings of a user.
+func (s *ProfileService) SetPrivacy(userID int64, privacy *models.ProfilePrivacy) error {
+	for _, visibility := range []string{privacy.Email, privacy.BirthDate, privacy.AboutMe, privacy.RealName} {
+		if visibility != FieldPublic && visibility != FieldFollowers && visibility != FieldOnlyMe {
+			return ErrInvalidFieldVisibility
+		}
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO profile_privacy (user_id, email, birth_date, about_me, real_name, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			email = excluded.email,
+			birth_date = excluded.birth_date,
+			about_me = excluded.about_me,
+			real_name = excluded.real_name,
+			updated_at = excluded.updated_at
+	`, userID, privacy.Email, privacy.BirthDate, privacy.AboutMe, privacy.RealName, time.Now())
+	return err
+}
+
+// IsFollower reports whether the viewer is an accepted follower of the owner.
+func (s *ProfileService) IsFollower(viewerID, ownerID int64) bool {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM followers WHERE follower_id = ? AND followed_id = ? AND accepted = 1
+	`, viewerID, ownerID).Scan(&count)
+	return err == nil && count > 0
+}
+
+// Project returns the profile of a user as seen by the viewer. The owner sees
+// every field and their settings. A private profile only shows its card to
+// non-followers; otherwise each field follows its own visibility.
+func (s *ProfileService) Project(user *models.User, viewerID int64) (*models.Profile, error) {
+	profile := &models.Profile{
+		ID:         user.ID,
+		Username:   user.Username,
+		AvatarPath: user.AvatarPath,
+		IsPublic:   user.IsPublic,
+		CreatedAt:  user.CreatedAt,
+	}
+
+	privacy, err := s.GetPrivacy(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := viewerID != 0 && viewerID == user.ID
+	follower := !owner && viewerID != 0 && s.IsFollower(viewerID, user.ID)
+	if !owner && !follower && !user.IsPublic {
+		profile.Restricted = true
+		return profile, nil
+	}
+
+	visible := func(visibility string) bool {
+		switch visibility {
+		case FieldPublic:
+			return true
+		case FieldFollowers:
+			return owner || follower
+		default:
+			return owner
+		}
+	}
+
+	if visible(privacy.RealName) {
+		profile.FirstName, profile.LastName = &user.FirstName, &user.LastName
+	}
+	if visible(privacy.Email) {
+		profile.Email = &user.Email
+	}
+	if visible(privacy.BirthDate) {
+		profile.BirthDate = &user.BirthDate
+	}
+	if visible(privacy.AboutMe) {
+		profile.AboutMe = &user.AboutMe
+	}
+	if owner {
+		profile.Privacy = privacy
+	}
+
+	return profile, nil
+}
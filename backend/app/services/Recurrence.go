@@ -0,0 +1,307 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies of the recurrence rules.
+const (
+	RecurDaily   = "DAILY"
+	RecurWeekly  = "WEEKLY"
+	RecurMonthly = "MONTHLY"
+)
+
+// RecurrenceHorizon is how far ahead open-ended series are expanded when no
+// end of range is given.
+const RecurrenceHorizon = 90 * 24 * time.Hour
+
+// MaxOccurrences bounds the occurrences of a series expanded at once.
+const MaxOccurrences = 500
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule, expected FREQ=DAILY, WEEKLY or MONTHLY with optional INTERVAL, BYDAY, UNTIL or COUNT")
+
+var recurrenceDays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// RecurrenceRule is the subset of the RFC 5545 RRULE supported by events:
+// daily, weekly on given weekdays or monthly on the day of the first
+// occurrence, every Interval periods, ending at Until or after Count
+// occurrences. Weeks start on Monday.
+type RecurrenceRule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday // WEEKLY only; the weekday of the start by default
+	Until    *time.Time
+	Count    int
+}
+
+// ParseRecurrence parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// The "RRULE:" prefix is optional.
+func ParseRecurrence(value string) (*RecurrenceRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, ErrInvalidRecurrence
+	}
+
+	rule := &RecurrenceRule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(key)
+		if !ok || val == "" || seen[key] {
+			return nil, ErrInvalidRecurrence
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, ErrInvalidRecurrence
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, ErrInvalidRecurrence
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseRecurrenceUntil(val)
+			if err != nil {
+				return nil, ErrInvalidRecurrence
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, name := range strings.Split(strings.ToUpper(val), ",") {
+				day, ok := recurrenceDays[name]
+				if !ok {
+					return nil, ErrInvalidRecurrence
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		default:
+			return nil, ErrInvalidRecurrence
+		}
+	}
+
+	if rule.Freq != RecurDaily && rule.Freq != RecurWeekly && rule.Freq != RecurMonthly {
+		return nil, ErrInvalidRecurrence
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, ErrInvalidRecurrence
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != RecurWeekly {
+		return nil, ErrInvalidRecurrence
+	}
+
+	// Jours triés à partir du lundi, sans doublon
+	sort.Slice(rule.ByDay, func(i, j int) bool { return weekOffset(rule.ByDay[i]) < weekOffset(rule.ByDay[j]) })
+	days := rule.ByDay[:0]
+	for i, day := range rule.ByDay {
+		if i == 0 || day != rule.ByDay[i-1] {
+			days = append(days, day)
+		}
+	}
+	rule.ByDay = days
+	return rule, nil
+}
+
+// parseRecurrenceUntil reads a UTC date-time, or a date meaning the end of
+// that day.
+func parseRecurrenceUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+// weekOffset is the position of a weekday in a week starting on Monday.
+func weekOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// String writes the rule in its canonical form.
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		names := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			names[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(names, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences returns the dates of the series starting at start that fall
+// between from and to, both included, in order and at most limit of them.
+// A zero from means the start of the series. Dates are computed on the wall
+// clock of the location of start, so that an occurrence keeps its local time
+// across daylight saving changes; from and to are compared as instants.
+// Dates before start are never generated, and monthly series skip the months
+// too short for their day.
+func (r *RecurrenceRule) Occurrences(start, from, to time.Time, limit int) []time.Time {
+	var dates []time.Time
+	count := 0
+
+	// emit reports whether the series goes on after the date
+	emit := func(date time.Time) bool {
+		if (r.Until != nil && date.After(*r.Until)) || (r.Count > 0 && count >= r.Count) || date.After(to) {
+			return false
+		}
+		count++
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+		return len(dates) < limit
+	}
+
+	// Les périodes entièrement passées avant from sont sautées, leurs
+	// occurrences restent comptées pour COUNT
+	skip := r.periodsBefore(start, from)
+
+	switch r.Freq {
+	case RecurDaily:
+		count = skip
+		for i := skip; emit(start.AddDate(0, 0, i*r.Interval)); i++ {
+		}
+	case RecurWeekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		if skip > 0 {
+			for _, day := range days {
+				if weekOffset(day) >= weekOffset(start.Weekday()) {
+					count++
+				}
+			}
+			count += (skip - 1) * len(days)
+		}
+		monday := start.AddDate(0, 0, -weekOffset(start.Weekday()))
+		for week := skip; ; week++ {
+			base := monday.AddDate(0, 0, 7*week*r.Interval)
+			for _, day := range days {
+				date := base.AddDate(0, 0, weekOffset(day))
+				if date.Before(start) {
+					continue
+				}
+				if !emit(date) {
+					return dates
+				}
+			}
+		}
+	case RecurMonthly:
+		for i := 0; i < skip; i++ {
+			if start.AddDate(0, i*r.Interval, 0).Day() == start.Day() {
+				count++
+			}
+		}
+		for i := skip; ; i++ {
+			date := start.AddDate(0, i*r.Interval, 0)
+			if date.Day() != start.Day() {
+				continue
+			}
+			if !emit(date) {
+				break
+			}
+		}
+	}
+	return dates
+}
+
+// periodsBefore returns a number of whole periods of the series, days, weeks
+// or months times the interval, that surely end before from. One period of
+// margin is kept so that no occurrence on or after from is skipped.
+func (r *RecurrenceRule) periodsBefore(start, from time.Time) int {
+	if !from.After(start) {
+		return 0
+	}
+	from = from.In(start.Location())
+
+	var periods int
+	switch r.Freq {
+	case RecurDaily:
+		periods = (dayNumber(from) - dayNumber(start)) / r.Interval
+	case RecurWeekly:
+		weeks := (dayNumber(from) - weekOffset(from.Weekday()) - dayNumber(start) + weekOffset(start.Weekday())) / 7
+		periods = weeks / r.Interval
+	case RecurMonthly:
+		months := (from.Year()-start.Year())*12 + int(from.Month()) - int(start.Month())
+		periods = months / r.Interval
+	}
+	return max(periods-1, 0)
+}
+
+// dayNumber numbers the calendar days, whatever the location and time of day.
+func dayNumber(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// Includes reports whether date is an occurrence of the series starting at
+// start.
+func (r *RecurrenceRule) Includes(start, date time.Time) bool {
+	dates := r.Occurrences(start, date, date, 1)
+	return len(dates) == 1 && dates[0].Equal(date)
+}
+
+// InTimeZone returns date on the wall clock of the IANA zone a series repeats
+// in, or in UTC when the zone is unknown. Dates loaded from the database only
+// carry a fixed offset, so series are expanded from the result.
+func InTimeZone(date time.Time, zone string) time.Time {
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return date.UTC()
+	}
+	return date.In(location)
+}
+
+// ShiftOccurrence moves the date of an occurrence as the start of its series
+// moved from previous to start: by whole days and a change of time on the
+// wall clock of the location of start, so that it keeps its local time.
+func ShiftOccurrence(date, previous, start time.Time) time.Time {
+	location := start.Location()
+	date, previous = date.In(location), previous.In(location)
+	days := dayNumber(start) - dayNumber(previous)
+	clock := time.Duration(start.Hour()-previous.Hour())*time.Hour +
+		time.Duration(start.Minute()-previous.Minute())*time.Minute +
+		time.Duration(start.Second()-previous.Second())*time.Second +
+		time.Duration(start.Nanosecond()-previous.Nanosecond())
+	return time.Date(date.Year(), date.Month(), date.Day()+days,
+		date.Hour(), date.Minute(), date.Second(), date.Nanosecond()+int(clock), location)
+}
+
+// Dropped returns the dates which are not occurrences of the series starting
+// at start, such as stored occurrences left behind by a new rule or start.
+func (r *RecurrenceRule) Dropped(start time.Time, dates []time.Time) []time.Time {
+	var dropped []time.Time
+	for _, date := range dates {
+		if !r.Includes(start, date) {
+			dropped = append(dropped, date)
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParseRecurrence(t *testing.T, value string) *RecurrenceRule {
+	t.Helper()
+	rule, err := ParseRecurrence(value)
+	if err != nil {
+		t.Fatalf("ParseRecurrence(%q): %v", value, err)
+	}
+	return rule
+}
+
+func TestOccurrencesKeepLocalTimeAcrossDST(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	// Le passage à l'heure d'été a lieu le 29 mars 2026
+	start := time.Date(2026, time.March, 16, 19, 0, 0, 0, paris)
+	rule := mustParseRecurrence(t, "FREQ=WEEKLY;BYDAY=MO;COUNT=4")
+
+	dates := rule.Occurrences(start, time.Time{}, start.AddDate(0, 1, 0), MaxOccurrences)
+	if len(dates) != 4 {
+		t.Fatalf("got %d occurrences, want 4", len(dates))
+	}
+	for _, date := range dates {
+		if local := date.In(paris); local.Hour() != 19 || local.Weekday() != time.Monday {
+			t.Errorf("occurrence %v is not on Monday at 19:00 in Paris", local)
+		}
+	}
+	if got := dates[3].UTC().Hour(); got != 17 {
+		t.Errorf("occurrence after the change at %d:00 UTC, want 17:00", got)
+	}
+
+	// Les bornes sont des instants, quelle que soit leur zone
+	from := dates[2].UTC()
+	if got := rule.Occurrences(start, from, from, 1); len(got) != 1 || !got[0].Equal(dates[2]) {
+		t.Errorf("Occurrences from %v = %v, want %v", from, got, dates[2])
+	}
+	if !rule.Includes(start, dates[3].UTC()) {
+		t.Errorf("Includes(%v) = false after the change", dates[3].UTC())
+	}
+}
+
+func TestOccurrencesSkipPeriodsBeforeFrom(t *testing.T) {
+	start := time.Date(2020, time.January, 31, 18, 30, 0, 0, time.UTC)
+	to := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, value := range []string{
+		"FREQ=DAILY",
+		"FREQ=DAILY;INTERVAL=3;COUNT=900",
+		"FREQ=DAILY;UNTIL=20240301",
+		"FREQ=WEEKLY",
+		"FREQ=WEEKLY;BYDAY=MO,WE,SU;INTERVAL=2;COUNT=200",
+		"FREQ=WEEKLY;BYDAY=TU,FR;COUNT=400",
+		"FREQ=MONTHLY",
+		"FREQ=MONTHLY;INTERVAL=5;COUNT=6",
+		"FREQ=MONTHLY;COUNT=30",
+	} {
+		rule := mustParseRecurrence(t, value)
+		all := rule.Occurrences(start, time.Time{}, to, 1<<20)
+		for _, from := range []time.Time{
+			start,
+			time.Date(2020, time.February, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2022, time.July, 14, 18, 30, 0, 0, time.UTC),
+			time.Date(2023, time.December, 31, 23, 59, 0, 0, time.UTC),
+			time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		} {
+			var want []time.Time
+			for _, date := range all {
+				if !date.Before(from) && len(want) < 5 {
+					want = append(want, date)
+				}
+			}
+			got := rule.Occurrences(start, from, to, 5)
+			if !equalDates(got, want) {
+				t.Errorf("%s from %v = %v, want %v", value, from, got, want)
+			}
+		}
+	}
+}
+
+func TestDropped(t *testing.T) {
+	start := time.Date(2026, time.November, 2, 18, 0, 0, 0, time.UTC) // lundi
+	monday := start.AddDate(0, 0, 7)
+	wednesday := start.AddDate(0, 0, 9)
+	stored := []time.Time{monday, wednesday}
+
+	for _, tc := range []struct {
+		rule  string
+		start time.Time
+		want  []time.Time
+	}{
+		{"FREQ=WEEKLY;BYDAY=MO,WE", start, nil},
+		{"FREQ=WEEKLY;BYDAY=MO", start, []time.Time{wednesday}},
+		{"FREQ=WEEKLY;BYDAY=MO,WE;INTERVAL=2", start, stored},
+		{"FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3", start, []time.Time{wednesday}},
+		{"FREQ=WEEKLY;BYDAY=MO,WE", start.Add(time.Hour), stored},
+		{"FREQ=DAILY", start, nil},
+	} {
+		rule := mustParseRecurrence(t, tc.rule)
+		if got := rule.Dropped(tc.start, stored); !equalDates(got, tc.want) {
+			t.Errorf("%s from %v: Dropped = %v, want %v", tc.rule, tc.start, got, tc.want)
+		}
+	}
+}
+
+func equalDates(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
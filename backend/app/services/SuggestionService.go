@@ -0,0 +1,116 @@
+package services
+
+import (
+	"database/sql"
+
+	"social-network/backend/database/models"
+)
+
+// Weights of the signals ranking the follow suggestions.
+const (
+	MutualFollowWeight = 3
+	SharedGroupWeight  = 2
+	SharedEventWeight  = 1
+)
+
+// Limits of the suggestions endpoint.
+const (
+	DefaultSuggestionsLimit = 10
+	MaxSuggestionsLimit     = 50
+)
+
+// SuggestionService ranks the users a user may want to follow.
+type SuggestionService struct {
+	db *sql.DB
+}
+
+// NewSuggestionService creates a new SuggestionService.
+func NewSuggestionService(db *sql.DB) *SuggestionService {
+	return &SuggestionService{db: db}
+}
+
+// GetSuggestions ranks the users by mutual follows (followed by the people
+// the user follows), shared groups and shared events. Users without any
+// signal come last, by number of followers, so new users still get some.
+// Blocked, dismissed, followed and pending users are left out.
+func (s *SuggestionService) GetSuggestions(userID int64, limit int) ([]*models.FollowSuggestion, error) {
+	rows, err := s.db.Query(`
+		WITH mutual AS (
+			SELECT f2.followed_id AS user_id, COUNT(*) AS n
+			FROM followers f1
+			JOIN followers f2 ON f2.follower_id = f1.followed_id AND f2.accepted = 1
+			WHERE f1.follower_id = ? AND f1.accepted = 1
+			GROUP BY f2.followed_id
+		), shared_groups AS (
+			SELECT gm2.user_id, COUNT(DISTINCT gm2.group_id) AS n
+			FROM group_members gm1
+			JOIN group_members gm2 ON gm2.group_id = gm1.group_id AND gm2.accepted = 1
+			WHERE gm1.user_id = ? AND gm1.accepted = 1
+			GROUP BY gm2.user_id
+		), shared_events AS (
+			SELECT er2.user_id, COUNT(DISTINCT er2.event_id) AS n
+			FROM event_responses er1
+			JOIN event_responses er2 ON er2.event_id = er1.event_id AND er2.status = 'going'
+			WHERE er1.user_id = ? AND er1.status = 'going'
+			GROUP BY er2.user_id
+		), popularity AS (
+			SELECT followed_id AS user_id, COUNT(*) AS n
+			FROM followers WHERE accepted = 1
+			GROUP BY followed_id
+		)
+		SELECT u.id, u.username, COALESCE(u.avatar_path, ''),
+			COALESCE(m.n, 0), COALESCE(g.n, 0), COALESCE(e.n, 0),
+			COALESCE(m.n, 0) * ? + COALESCE(g.n, 0) * ? + COALESCE(e.n, 0) * ? AS score
+		FROM users u
+		LEFT JOIN mutual m ON m.user_id = u.id
+		LEFT JOIN shared_groups g ON g.user_id = u.id
+		LEFT JOIN shared_events e ON e.user_id = u.id
+		LEFT JOIN popularity p ON p.user_id = u.id
+		WHERE u.id != ? AND `+ActiveUserCondition+` AND `+NotBlockedCondition("u.id")+`
+		AND NOT EXISTS (
+			SELECT 1 FROM followers f
+			WHERE (f.follower_id = ? AND f.followed_id = u.id)
+				OR (f.follower_id = u.id AND f.followed_id = ? AND f.accepted = 0)
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM suggestion_dismissals sd WHERE sd.user_id = ? AND sd.dismissed_id = u.id
+		)
+		ORDER BY score DESC, COALESCE(p.n, 0) DESC, u.id DESC
+		LIMIT ?
+	`,
+		userID, userID, userID,
+		MutualFollowWeight, SharedGroupWeight, SharedEventWeight,
+		userID, userID, userID, userID, userID, userID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []*models.FollowSuggestion{}
+	for rows.Next() {
+		suggestion := &models.FollowSuggestion{}
+		if err := rows.Scan(
+			&suggestion.ID,
+			&suggestion.Username,
+			&suggestion.AvatarPath,
+			&suggestion.MutualFollows,
+			&suggestion.SharedGroups,
+			&suggestion.SharedEvents,
+			&suggestion.Score,
+		); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, rows.Err()
+}
+
+// Dismiss hides a user from the suggestions of another one.
+func (s *SuggestionService) Dismiss(userID, dismissedID int64) error {
+	_, err := s.db.Exec(`
+		INSERT OR IGNORE INTO suggestion_dismissals (user_id, dismissed_id) VALUES (?, ?)
+	`, userID, dismissedID)
+	return err
+}
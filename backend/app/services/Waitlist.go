@@ -0,0 +1,74 @@
+package services
+
+import (
+	"database/sql"
+	"time"
+)
+
+// WaitlistPromotion lists the users who got a seat at an event freed by
+// someone else.
+type WaitlistPromotion struct {
+	EventID    int64
+	EventTitle string
+	UserIDs    []int64
+}
+
+// FreeSeats returns the number of seats left at an event, or -1 when it has
+// no capacity.
+func FreeSeats(tx *sql.Tx, eventID int64) (int64, error) {
+	var capacity sql.NullInt64
+	var going int64
+	err := tx.QueryRow(`
+		SELECT e.capacity, (SELECT COUNT(*) FROM event_responses er WHERE er.event_id = e.id AND er.status = 'going')
+		FROM events e WHERE e.id = ?
+	`, eventID).Scan(&capacity, &going)
+	if err != nil {
+		return 0, err
+	}
+	if !capacity.Valid {
+		return -1, nil
+	}
+	return max(capacity.Int64-going, 0), nil
+}
+
+// PromoteWaitlist gives the free seats of an event to the first waitlisted
+// users, and returns their IDs.
+func PromoteWaitlist(tx *sql.Tx, eventID int64) ([]int64, error) {
+	seats, err := FreeSeats(tx, eventID)
+	if err != nil || seats == 0 {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT user_id FROM event_responses
+		WHERE event_id = ? AND status = 'waitlisted'
+		ORDER BY updated_at ASC, created_at ASC
+		LIMIT ?
+	`, eventID, seats)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, userID := range userIDs {
+		_, err := tx.Exec(`
+			UPDATE event_responses SET status = 'going', updated_at = ? WHERE event_id = ? AND user_id = ?
+		`, time.Now(), eventID, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return userIDs, nil
+}
@@ -4,6 +4,9 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
+	_ "time/tzdata" // fuseaux des séries, absents de l'image alpine
 
 	gorillaHandlers "github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -52,22 +55,63 @@ func main() {
 	groupRepo := repository.NewGroupRepository(db)
 	notificationRepo := repository.NewNotificationRepository(db)
 	eventRepo := repository.NewEventRepository(db)
+	reportRepo := repository.NewReportRepository(db)
 
 	// Services
 	userService := services.NewUserService(db)
-	postService := services.NewPostService(db)
+	linkPreviewService := services.NewLinkPreviewService(db)
+	blockService := services.NewBlockService(db)
+	suggestionService := services.NewSuggestionService(db)
+	profileService := services.NewProfileService(db)
+	moderationService := services.NewModerationService(db)
+	groupService := services.NewGroupService(db)
+	postService := services.NewPostService(db, linkPreviewService, blockService, groupService)
+	inviteLinkService := services.NewInviteLinkService(db, groupService)
+	groupDirectoryService := services.NewGroupDirectoryService(db, groupService)
+	contentFilterService := services.NewContentFilterService(db,
+		services.NewVelocityFilter(db, time.Minute, services.DefaultVelocityLimits),
+		services.NewBannedWordFilter(strings.Split(os.Getenv("BANNED_WORDS"), ","), services.ParseFilterAction(os.Getenv("BANNED_WORDS_ACTION"))),
+		services.NewLinkFloodFilter(5),
+		services.NewDuplicateFilter(db, 10*time.Minute),
+	)
+
+	// Premier administrateur, défini par l'environnement
+	if adminEmail := os.Getenv("ADMIN_EMAIL"); adminEmail != "" {
+		if err := moderationService.PromoteAdmin(adminEmail); err != nil {
+			log.Printf("Cannot promote %s to admin: %v", adminEmail, err)
+		}
+	}
+
+	// Les comptes suspendus sont refusés par le middleware JWT
+	middlewares.IsSuspended = moderationService.IsSuspended
+
+	// Les groupes supprimés sont effacés une fois le délai de restauration passé
+	go func() {
+		for ; ; time.Sleep(time.Hour) {
+			if n, err := groupService.PurgeDeletedGroups(); err != nil {
+				log.Printf("Cannot purge deleted groups: %v", err)
+			} else if n > 0 {
+				log.Printf("Purged %d deleted groups", n)
+			}
+		}
+	}()
 
 	// Handlers
-	userHandler := appHandlers.NewUserHandler(userService, userRepo, sessionRepo)
-	postHandler := appHandlers.NewPostHandler(postService, postRepo, sessionRepo, userRepo)
-	commentHandler := appHandlers.NewCommentHandler(commentRepo, sessionRepo)
-	followerHandler := appHandlers.NewFollowerHandler(followerRepo, notificationRepo, userRepo)
-	messageHandler := appHandlers.NewMessageHandler(messageRepo, conversationRepo, conversationMembersRepo)
-	websocketHandler := websocket.NewWebSocketHandler(messageRepo, conversationRepo, conversationMembersRepo, notificationRepo)
+	userHandler := appHandlers.NewUserHandler(userService, userRepo, sessionRepo, blockService, profileService)
+	postHandler := appHandlers.NewPostHandler(postService, postRepo, sessionRepo, userRepo, contentFilterService)
+	commentHandler := appHandlers.NewCommentHandler(commentRepo, sessionRepo, postRepo, postService, contentFilterService, notificationRepo)
+	followerHandler := appHandlers.NewFollowerHandler(followerRepo, notificationRepo, userRepo, blockService)
+	messageHandler := appHandlers.NewMessageHandler(messageRepo, conversationRepo, conversationMembersRepo, linkPreviewService, contentFilterService, blockService)
+	websocketHandler := websocket.NewWebSocketHandler(messageRepo, conversationRepo, conversationMembersRepo, notificationRepo, linkPreviewService, contentFilterService, blockService, groupService)
 	notificationHandler := appHandlers.NewNotificationHandler(notificationRepo, followerRepo, groupRepo)
-	eventHandler := appHandlers.NewEventHandler(eventRepo, groupRepo)
+	eventHandler := appHandlers.NewEventHandler(eventRepo, groupRepo, groupService, notificationRepo)
+	moderationHandler := appHandlers.NewModerationHandler(reportRepo, moderationService)
+	blockHandler := appHandlers.NewBlockHandler(blockService, userRepo)
+	suggestionHandler := appHandlers.NewSuggestionHandler(suggestionService, userRepo)
+	inviteLinkHandler := appHandlers.NewInviteLinkHandler(inviteLinkService, userRepo)
+	groupDirectoryHandler := appHandlers.NewGroupDirectoryHandler(groupDirectoryService)
 
-	groupHandler := appHandlers.NewGroupHandler(groupRepo, sessionRepo, userRepo, notificationRepo)
+	groupHandler := appHandlers.NewGroupHandler(groupRepo, sessionRepo, userRepo, notificationRepo, contentFilterService, postRepo, postService, commentHandler, groupService)
 
 	// CORS
 	r.Use(middlewares.CORSMiddleware)
@@ -81,13 +125,16 @@ func main() {
 	routes.MessageRoutes(r, messageHandler)
 	routes.NotificationsRoutes(r, notificationHandler)
 	routes.EventsRoutes(r, eventHandler)
+	routes.ModerationRoutes(r, moderationHandler)
+	routes.BlockRoutes(r, blockHandler)
+	routes.SuggestionRoutes(r, suggestionHandler)
+	routes.InviteLinkRoutes(r, inviteLinkHandler)
+	routes.GroupDirectoryRoutes(r, groupDirectoryHandler)
 
 	// WebSocket
 	wsHandler := middlewares.JWTMiddleware(http.HandlerFunc(websocketHandler.HandleWebSocket))
 	r.Handle("/ws", wsHandler).Methods("GET", "OPTIONS")
 
-	r.Handle("/ws/groups", middlewares.JWTMiddleware(http.HandlerFunc(appHandlers.HandleGroupWebSocket)))
-
 	r.Handle("/api/messages/conversation", middlewares.CORSMiddleware(
 		http.HandlerFunc(websocketHandler.HandleGetConversation),
 	)).Methods("POST", "OPTIONS")
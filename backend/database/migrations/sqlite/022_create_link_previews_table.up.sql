@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS link_previews (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	url TEXT NOT NULL UNIQUE CHECK (length(url) <= 2048),
+	title TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	image_url TEXT NOT NULL DEFAULT '',
+	site_name TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'ok' CHECK (status IN ('ok', 'failed')),
+	fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,9 @@
+ALTER TABLE messages DROP COLUMN is_hidden;
+ALTER TABLE group_comments DROP COLUMN is_hidden;
+ALTER TABLE group_posts DROP COLUMN is_hidden;
+ALTER TABLE comments DROP COLUMN is_hidden;
+ALTER TABLE posts DROP COLUMN is_hidden;
+
+ALTER TABLE users DROP COLUMN suspended_until;
+ALTER TABLE users DROP COLUMN suspended;
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,9 @@
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin'));
+ALTER TABLE users ADD COLUMN suspended BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMP;
+
+ALTER TABLE posts ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE group_posts ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE group_comments ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE messages ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS reports (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	reporter_id INTEGER NOT NULL,
+	target_type TEXT NOT NULL CHECK (target_type IN ('post', 'comment', 'group_post', 'group_comment', 'message', 'user')),
+	target_id INTEGER NOT NULL,
+	reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate_speech', 'violence', 'nudity', 'misinformation', 'other')),
+	details TEXT CHECK (length(details) <= 1000),
+	status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'resolved', 'dismissed')),
+	resolved_by INTEGER,
+	resolution TEXT CHECK (length(resolution) <= 1000),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	resolved_at TIMESTAMP,
+	FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_pending_unique
+	ON reports (reporter_id, target_type, target_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_reports_status ON reports (status, created_at);
@@ -0,0 +1,26 @@
+ALTER TABLE group_messages ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT 0;
+
+-- Les signalements automatiques des filtres n'ont pas d'auteur
+CREATE TABLE reports_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	reporter_id INTEGER,
+	target_type TEXT NOT NULL CHECK (target_type IN ('post', 'comment', 'group_post', 'group_comment', 'message', 'group_message', 'user')),
+	target_id INTEGER NOT NULL,
+	reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate_speech', 'violence', 'nudity', 'misinformation', 'other', 'content_filter')),
+	details TEXT CHECK (length(details) <= 1000),
+	status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'resolved', 'dismissed')),
+	resolved_by INTEGER,
+	resolution TEXT CHECK (length(resolution) <= 1000),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	resolved_at TIMESTAMP,
+	FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+INSERT INTO reports_new SELECT * FROM reports;
+DROP TABLE reports;
+ALTER TABLE reports_new RENAME TO reports;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_pending_unique
+	ON reports (reporter_id, target_type, target_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_reports_status ON reports (status, created_at);
@@ -0,0 +1,39 @@
+-- parent_id est une clé étrangère : SQLite ne sait pas la supprimer, les tables sont reconstruites
+DROP INDEX IF EXISTS idx_group_comments_parent;
+DROP INDEX IF EXISTS idx_comments_parent;
+
+DELETE FROM comments WHERE parent_id IS NOT NULL;
+CREATE TABLE comments_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	post_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	content TEXT NOT NULL CHECK (length(content) BETWEEN 1 AND 1000),
+	image_path TEXT CHECK (length(image_path) <= 255),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	is_hidden BOOLEAN NOT NULL DEFAULT 0,
+	FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+INSERT INTO comments_old
+	SELECT id, post_id, user_id, content, image_path, created_at, updated_at, is_hidden FROM comments;
+DROP TABLE comments;
+ALTER TABLE comments_old RENAME TO comments;
+
+DELETE FROM group_comments WHERE parent_id IS NOT NULL;
+CREATE TABLE group_comments_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	group_post_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	content TEXT NOT NULL CHECK (length(content) BETWEEN 1 AND 1000),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	is_hidden BOOLEAN NOT NULL DEFAULT 0,
+	FOREIGN KEY (group_post_id) REFERENCES group_posts(id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+INSERT INTO group_comments_old
+	SELECT id, group_post_id, user_id, username, content, created_at, updated_at, is_hidden FROM group_comments;
+DROP TABLE group_comments;
+ALTER TABLE group_comments_old RENAME TO group_comments;
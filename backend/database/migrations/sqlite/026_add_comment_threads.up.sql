@@ -0,0 +1,7 @@
+ALTER TABLE comments ADD COLUMN parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN depth INTEGER NOT NULL DEFAULT 0 CHECK (depth >= 0);
+CREATE INDEX IF NOT EXISTS idx_comments_parent ON comments (parent_id, created_at);
+
+ALTER TABLE group_comments ADD COLUMN parent_id INTEGER REFERENCES group_comments(id) ON DELETE CASCADE;
+ALTER TABLE group_comments ADD COLUMN depth INTEGER NOT NULL DEFAULT 0 CHECK (depth >= 0);
+CREATE INDEX IF NOT EXISTS idx_group_comments_parent ON group_comments (parent_id, created_at);
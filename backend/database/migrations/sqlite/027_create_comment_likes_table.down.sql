@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_group_comments_post;
+DROP INDEX IF EXISTS idx_comments_post;
+DROP TABLE IF EXISTS group_comment_like;
+DROP TABLE IF EXISTS comment_like;
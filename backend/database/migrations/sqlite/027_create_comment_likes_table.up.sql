@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS comment_like (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    comment_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (comment_id, user_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS group_comment_like (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_comment_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (group_comment_id, user_id),
+    FOREIGN KEY (group_comment_id) REFERENCES group_comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Pagination des commentaires de premier niveau
+CREATE INDEX IF NOT EXISTS idx_comments_post ON comments (post_id, parent_id, id);
+CREATE INDEX IF NOT EXISTS idx_group_comments_post ON group_comments (group_post_id, parent_id, id);
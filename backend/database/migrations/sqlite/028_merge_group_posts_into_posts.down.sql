@@ -0,0 +1,89 @@
+-- Les identifiants des posts et commentaires de groupe sont conservés
+CREATE TABLE IF NOT EXISTS group_posts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	group_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	username TEXT NOT NULL CHECK (length(username) BETWEEN 1 AND 50),
+	content TEXT NOT NULL CHECK (length(content) BETWEEN 1 AND 1000),
+	image_path TEXT CHECK (length(image_path) <= 255),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	comments_count INTEGER DEFAULT 0 CHECK (comments_count >= 0),
+	is_hidden BOOLEAN NOT NULL DEFAULT 0,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS group_comments (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	group_post_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	content TEXT NOT NULL CHECK (length(content) BETWEEN 1 AND 1000),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	is_hidden BOOLEAN NOT NULL DEFAULT 0,
+	parent_id INTEGER REFERENCES group_comments(id) ON DELETE CASCADE,
+	depth INTEGER NOT NULL DEFAULT 0 CHECK (depth >= 0),
+	FOREIGN KEY (group_post_id) REFERENCES group_posts(id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_group_comments_parent ON group_comments (parent_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_group_comments_post ON group_comments (group_post_id, parent_id, id);
+
+CREATE TABLE IF NOT EXISTS group_comment_like (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_comment_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (group_comment_id, user_id),
+    FOREIGN KEY (group_comment_id) REFERENCES group_comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO group_posts (id, group_id, user_id, username, content, image_path, created_at, updated_at, comments_count, is_hidden)
+	SELECT p.id, p.group_id, p.user_id, u.username, p.content, p.image_path, p.created_at, p.updated_at,
+		(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id), p.is_hidden
+	FROM posts p
+	JOIN users u ON u.id = p.user_id
+	WHERE p.group_id IS NOT NULL;
+
+INSERT INTO group_comments (id, group_post_id, user_id, username, content, created_at, updated_at, is_hidden, parent_id, depth)
+	SELECT c.id, c.post_id, c.user_id, u.username, c.content, c.created_at, c.updated_at, c.is_hidden, c.parent_id, c.depth
+	FROM comments c
+	JOIN users u ON u.id = c.user_id
+	WHERE c.post_id IN (SELECT id FROM group_posts)
+	ORDER BY c.id;
+
+INSERT INTO group_comment_like (group_comment_id, user_id, created_at)
+	SELECT l.comment_id, l.user_id, l.created_at
+	FROM comment_like l
+	WHERE l.comment_id IN (SELECT id FROM group_comments);
+
+UPDATE reports SET target_type = 'group_post'
+WHERE target_type = 'post' AND target_id IN (SELECT id FROM group_posts);
+UPDATE reports SET target_type = 'group_comment'
+WHERE target_type = 'comment' AND target_id IN (SELECT id FROM group_comments);
+UPDATE notifications SET reference_type = 'group_comment'
+WHERE reference_type = 'comment' AND type = 'comment_reply' AND reference_id IN (SELECT id FROM group_comments);
+
+DELETE FROM comments WHERE post_id IN (SELECT id FROM group_posts);
+DELETE FROM posts WHERE group_id IS NOT NULL;
+
+-- group_id est une clé étrangère : SQLite ne sait pas la supprimer, la table est reconstruite.
+-- À lancer avec les clés étrangères désactivées pour ne pas vider les tables liées.
+DROP INDEX IF EXISTS idx_posts_group;
+CREATE TABLE posts_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	content TEXT NOT NULL CHECK (length(content) BETWEEN 1 AND 1000),
+	image_path TEXT CHECK (length(image_path) <= 255),
+	privacy_type INTEGER NOT NULL DEFAULT 0 CHECK (privacy_type IN (0, 1, 2)),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	is_hidden BOOLEAN NOT NULL DEFAULT 0,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+INSERT INTO posts_old
+	SELECT id, user_id, content, image_path, privacy_type, created_at, updated_at, is_hidden FROM posts;
+DROP TABLE posts;
+ALTER TABLE posts_old RENAME TO posts;
@@ -0,0 +1,54 @@
+-- Les posts de groupe deviennent des posts avec une audience de groupe.
+-- privacy_type vaut 2 (privé) pour qu'une requête oubliant group_id ne les rende pas publics.
+ALTER TABLE posts ADD COLUMN group_id INTEGER REFERENCES groups(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_posts_group ON posts (group_id, created_at);
+
+-- Correspondance des anciens identifiants vers les nouveaux
+CREATE TABLE group_post_ids (old_id INTEGER PRIMARY KEY, new_id INTEGER NOT NULL);
+INSERT INTO group_post_ids (old_id, new_id)
+	SELECT id, (SELECT COALESCE(MAX(id), 0) FROM posts) + ROW_NUMBER() OVER (ORDER BY id)
+	FROM group_posts
+	WHERE group_id IN (SELECT id FROM groups);
+
+CREATE TABLE group_comment_ids (old_id INTEGER PRIMARY KEY, new_id INTEGER NOT NULL);
+INSERT INTO group_comment_ids (old_id, new_id)
+	SELECT id, (SELECT COALESCE(MAX(id), 0) FROM comments) + ROW_NUMBER() OVER (ORDER BY id)
+	FROM group_comments
+	WHERE group_post_id IN (SELECT old_id FROM group_post_ids);
+
+INSERT INTO posts (id, user_id, content, image_path, privacy_type, created_at, updated_at, is_hidden, group_id)
+	SELECT m.new_id, gp.user_id, gp.content, gp.image_path, 2, gp.created_at, gp.updated_at, gp.is_hidden, gp.group_id
+	FROM group_posts gp
+	JOIN group_post_ids m ON m.old_id = gp.id;
+
+-- Les parents sont insérés avant leurs réponses
+INSERT INTO comments (id, post_id, user_id, content, created_at, updated_at, is_hidden, parent_id, depth)
+	SELECT c.new_id, p.new_id, gc.user_id, gc.content, gc.created_at, gc.updated_at, gc.is_hidden, pc.new_id, gc.depth
+	FROM group_comments gc
+	JOIN group_comment_ids c ON c.old_id = gc.id
+	JOIN group_post_ids p ON p.old_id = gc.group_post_id
+	LEFT JOIN group_comment_ids pc ON pc.old_id = gc.parent_id
+	ORDER BY gc.id;
+
+INSERT OR IGNORE INTO comment_like (comment_id, user_id, created_at)
+	SELECT c.new_id, l.user_id, l.created_at
+	FROM group_comment_like l
+	JOIN group_comment_ids c ON c.old_id = l.group_comment_id;
+
+UPDATE reports
+SET target_type = 'post', target_id = (SELECT new_id FROM group_post_ids WHERE old_id = reports.target_id)
+WHERE target_type = 'group_post' AND target_id IN (SELECT old_id FROM group_post_ids);
+
+UPDATE reports
+SET target_type = 'comment', target_id = (SELECT new_id FROM group_comment_ids WHERE old_id = reports.target_id)
+WHERE target_type = 'group_comment' AND target_id IN (SELECT old_id FROM group_comment_ids);
+
+UPDATE notifications
+SET reference_type = 'comment', reference_id = (SELECT new_id FROM group_comment_ids WHERE old_id = notifications.reference_id)
+WHERE reference_type = 'group_comment' AND reference_id IN (SELECT old_id FROM group_comment_ids);
+
+DROP TABLE group_comment_ids;
+DROP TABLE group_post_ids;
+DROP TABLE group_comment_like;
+DROP TABLE group_comments;
+DROP TABLE group_posts;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_mutes;
+DROP INDEX IF EXISTS idx_user_blocks_blocked;
+DROP TABLE IF EXISTS user_blocks;
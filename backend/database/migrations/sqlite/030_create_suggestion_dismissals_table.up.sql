@@ -0,0 +1,10 @@
+-- Suggestions de personnes écartées par l'utilisateur
+CREATE TABLE IF NOT EXISTS suggestion_dismissals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    dismissed_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, dismissed_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (dismissed_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+-- Visibilité de chaque champ du profil : 'public', 'followers' ou 'only_me'
+CREATE TABLE IF NOT EXISTS profile_privacy (
+    user_id INTEGER PRIMARY KEY,
+    email TEXT NOT NULL DEFAULT 'followers' CHECK (email IN ('public', 'followers', 'only_me')),
+    birth_date TEXT NOT NULL DEFAULT 'followers' CHECK (birth_date IN ('public', 'followers', 'only_me')),
+    about_me TEXT NOT NULL DEFAULT 'public' CHECK (about_me IN ('public', 'followers', 'only_me')),
+    real_name TEXT NOT NULL DEFAULT 'public' CHECK (real_name IN ('public', 'followers', 'only_me')),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
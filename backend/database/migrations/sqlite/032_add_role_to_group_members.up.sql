@@ -0,0 +1,7 @@
+-- Rôle de chaque membre : 'owner', 'admin', 'moderator' ou 'member'
+ALTER TABLE group_members ADD COLUMN role TEXT NOT NULL DEFAULT 'member'
+    CHECK (role IN ('owner', 'admin', 'moderator', 'member'));
+
+-- Le créateur de chaque groupe en devient le propriétaire
+UPDATE group_members SET role = 'owner'
+WHERE user_id = (SELECT g.creator_id FROM groups g WHERE g.id = group_members.group_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_group_join_requests_pending;
+DROP TABLE IF EXISTS group_join_requests;
@@ -0,0 +1,18 @@
+-- Demandes pour rejoindre un groupe : 'pending', 'approved', 'rejected' ou 'withdrawn'
+CREATE TABLE IF NOT EXISTS group_join_requests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id INTEGER NOT NULL,
+    requester_id INTEGER NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'withdrawn')),
+    decided_by INTEGER,
+    decided_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (requester_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (decided_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Une seule demande en attente par utilisateur et par groupe
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_join_requests_pending
+    ON group_join_requests (group_id, requester_id) WHERE status = 'pending';
@@ -0,0 +1,4 @@
+-- Visibilité des groupes : 'public' (adhésion libre, contenu lisible), 'private'
+-- (visible, contenu réservé aux membres) ou 'secret' (sur invitation, caché de la recherche)
+-- Les groupes existants fonctionnaient sur demande d'adhésion : ils deviennent privés
+ALTER TABLE groups ADD COLUMN visibility TEXT NOT NULL DEFAULT 'private' CHECK (visibility IN ('public', 'private', 'secret'));
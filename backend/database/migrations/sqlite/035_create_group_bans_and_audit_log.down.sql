@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_group_audit_log_group;
+DROP TABLE IF EXISTS group_audit_log;
+DROP TABLE IF EXISTS group_bans;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS announcement_acknowledgements;
+ALTER TABLE posts DROP COLUMN is_announcement;
+ALTER TABLE posts DROP COLUMN pinned_at;
@@ -0,0 +1,14 @@
+-- Publications épinglées en tête d'un groupe et annonces
+ALTER TABLE posts ADD COLUMN pinned_at TIMESTAMP;
+ALTER TABLE posts ADD COLUMN is_announcement BOOLEAN NOT NULL DEFAULT 0;
+
+-- Membres ayant pris connaissance d'une annonce
+CREATE TABLE IF NOT EXISTS announcement_acknowledgements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    post_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    acknowledged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(post_id, user_id)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_group_audit_log_action;
+DROP TRIGGER IF EXISTS group_audit_log_no_delete;
+DROP TRIGGER IF EXISTS group_audit_log_no_actor_update;
+DROP TRIGGER IF EXISTS group_audit_log_no_update;
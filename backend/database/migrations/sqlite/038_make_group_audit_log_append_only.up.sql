@@ -0,0 +1,24 @@
+-- Le journal d'audit des groupes est en ajout seul : ses entrées ne peuvent
+-- être ni modifiées ni supprimées, sauf par la suppression du groupe (cascade)
+-- ou de l'auteur de l'action (actor_id passe à NULL)
+CREATE TRIGGER IF NOT EXISTS group_audit_log_no_update
+BEFORE UPDATE OF group_id, action, target_type, target_id, details, created_at ON group_audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'group audit log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS group_audit_log_no_actor_update
+BEFORE UPDATE OF actor_id ON group_audit_log
+WHEN NEW.actor_id IS NOT NULL
+BEGIN
+    SELECT RAISE(ABORT, 'group audit log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS group_audit_log_no_delete
+BEFORE DELETE ON group_audit_log
+WHEN EXISTS (SELECT 1 FROM groups g WHERE g.id = OLD.group_id)
+BEGIN
+    SELECT RAISE(ABORT, 'group audit log is append-only');
+END;
+
+CREATE INDEX IF NOT EXISTS idx_group_audit_log_action ON group_audit_log (group_id, action);
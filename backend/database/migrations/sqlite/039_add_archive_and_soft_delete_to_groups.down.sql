@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_groups_deleted_at;
+ALTER TABLE groups DROP COLUMN deleted_at;
+ALTER TABLE groups DROP COLUMN archived_at;
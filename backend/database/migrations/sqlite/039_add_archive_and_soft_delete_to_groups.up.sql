@@ -0,0 +1,6 @@
+-- Un groupe archivé reste lisible mais n'accepte plus de contenu
+ALTER TABLE groups ADD COLUMN archived_at TIMESTAMP;
+
+-- Un groupe supprimé est caché, puis effacé définitivement après le délai de restauration
+ALTER TABLE groups ADD COLUMN deleted_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_groups_deleted_at ON groups (deleted_at) WHERE deleted_at IS NOT NULL;
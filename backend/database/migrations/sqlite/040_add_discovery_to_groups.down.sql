@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_group_tags_tag;
+DROP TABLE IF EXISTS group_tags;
+DROP INDEX IF EXISTS idx_groups_category;
+ALTER TABLE groups DROP COLUMN cover_path;
+ALTER TABLE groups DROP COLUMN category;
@@ -0,0 +1,14 @@
+-- Profil du groupe dans l'annuaire : catégorie et image de couverture
+ALTER TABLE groups ADD COLUMN category TEXT;
+ALTER TABLE groups ADD COLUMN cover_path TEXT;
+CREATE INDEX IF NOT EXISTS idx_groups_category ON groups (category);
+
+-- Mots-clés libres, normalisés en minuscules
+CREATE TABLE IF NOT EXISTS group_tags (
+    group_id INTEGER NOT NULL,
+    tag TEXT NOT NULL CHECK (length(tag) BETWEEN 1 AND 30),
+    PRIMARY KEY (group_id, tag),
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_tags_tag ON group_tags (tag);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS group_rules_acceptances;
+ALTER TABLE groups DROP COLUMN rules_updated_at;
+ALTER TABLE groups DROP COLUMN rules_version;
+ALTER TABLE groups DROP COLUMN rules;
+DROP INDEX IF EXISTS idx_group_join_request_answers_request;
+DROP TABLE IF EXISTS group_join_request_answers;
+DROP INDEX IF EXISTS idx_group_questions_group;
+DROP TABLE IF EXISTS group_questions;
@@ -0,0 +1,40 @@
+-- Questions posées à ceux qui demandent à rejoindre le groupe
+CREATE TABLE IF NOT EXISTS group_questions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    question TEXT NOT NULL CHECK (length(question) BETWEEN 1 AND 300),
+    required BOOLEAN NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_questions_group ON group_questions (group_id, position);
+
+-- Réponses d'une demande ; la question est recopiée pour survivre à sa modification
+CREATE TABLE IF NOT EXISTS group_join_request_answers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id INTEGER NOT NULL,
+    question_id INTEGER,
+    question TEXT NOT NULL,
+    answer TEXT NOT NULL CHECK (length(answer) <= 1000),
+    FOREIGN KEY (request_id) REFERENCES group_join_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (question_id) REFERENCES group_questions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_join_request_answers_request ON group_join_request_answers (request_id);
+
+-- Règlement du groupe, versionné : chaque modification demande une nouvelle acceptation
+ALTER TABLE groups ADD COLUMN rules TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN rules_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN rules_updated_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS group_rules_acceptances (
+    group_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    accepted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id),
+    FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
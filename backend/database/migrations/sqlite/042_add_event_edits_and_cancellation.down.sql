@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_event_changes_event;
+DROP TABLE IF EXISTS event_changes;
+ALTER TABLE events DROP COLUMN cancel_reason;
+ALTER TABLE events DROP COLUMN cancelled_by;
+ALTER TABLE events DROP COLUMN cancelled_at;
+ALTER TABLE events DROP COLUMN location;
@@ -0,0 +1,23 @@
+-- Lieu de l'événement, modifiable comme le reste
+ALTER TABLE events ADD COLUMN location TEXT NOT NULL DEFAULT '';
+
+-- Un événement annulé reste visible avec la raison de l'annulation
+ALTER TABLE events ADD COLUMN cancelled_at TIMESTAMP;
+ALTER TABLE events ADD COLUMN cancelled_by INTEGER;
+ALTER TABLE events ADD COLUMN cancel_reason TEXT NOT NULL DEFAULT '';
+
+-- Historique des modifications, un champ par ligne
+CREATE TABLE IF NOT EXISTS event_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id INTEGER NOT NULL,
+    changed_by INTEGER,
+    field TEXT NOT NULL,
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+    FOREIGN KEY (changed_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_changes_event ON event_changes (event_id, changed_at);
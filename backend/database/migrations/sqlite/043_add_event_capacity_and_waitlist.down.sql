@@ -0,0 +1,17 @@
+CREATE TABLE event_responses_old (
+	event_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	status TEXT NOT NULL CHECK(status IN ('going', 'not_going')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (event_id, user_id),
+	FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO event_responses_old (event_id, user_id, status, created_at)
+	SELECT event_id, user_id, status, created_at FROM event_responses
+	WHERE status IN ('going', 'not_going');
+DROP TABLE event_responses;
+ALTER TABLE event_responses_old RENAME TO event_responses;
+
+ALTER TABLE events DROP COLUMN capacity;
@@ -0,0 +1,22 @@
+-- Nombre maximum de participants ; NULL pour un événement sans limite
+ALTER TABLE events ADD COLUMN capacity INTEGER CHECK (capacity IS NULL OR capacity > 0);
+
+-- Nouveaux statuts : "maybe", et "waitlisted" une fois l'événement complet.
+-- updated_at ordonne la liste d'attente.
+CREATE TABLE event_responses_new (
+	event_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	status TEXT NOT NULL CHECK(status IN ('going', 'maybe', 'not_going', 'waitlisted')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (event_id, user_id),
+	FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO event_responses_new (event_id, user_id, status, created_at, updated_at)
+	SELECT event_id, user_id, status, created_at, created_at FROM event_responses;
+DROP TABLE event_responses;
+ALTER TABLE event_responses_new RENAME TO event_responses;
+
+CREATE INDEX IF NOT EXISTS idx_event_responses_status ON event_responses (event_id, status, updated_at);
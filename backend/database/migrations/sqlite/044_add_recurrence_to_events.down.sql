@@ -0,0 +1,5 @@
+DELETE FROM events WHERE series_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_events_occurrence;
+ALTER TABLE events DROP COLUMN occurrence_date;
+ALTER TABLE events DROP COLUMN series_id;
+ALTER TABLE events DROP COLUMN recurrence;
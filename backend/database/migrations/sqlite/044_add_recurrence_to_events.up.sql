@@ -0,0 +1,10 @@
+-- Règle de récurrence, sous-ensemble de RRULE ; vide pour un événement unique
+ALTER TABLE events ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+
+-- Occurrence d'une série ayant des réponses, modifiée ou annulée : series_id
+-- désigne la série et occurrence_date la date prévue par sa règle. Les
+-- occurrences sont effacées avec leur groupe, comme la série.
+ALTER TABLE events ADD COLUMN series_id INTEGER;
+ALTER TABLE events ADD COLUMN occurrence_date TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_occurrence ON events (series_id, occurrence_date) WHERE series_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Fuseau IANA dans lequel une série se répète : les dates relues de la base
+-- n'ont qu'un décalage fixe, qui ne suit pas les changements d'heure
+ALTER TABLE events ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
@@ -18,6 +18,37 @@ type User struct {
 	IsPublic     bool      `json:"is_public"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	Role           string     `json:"role,omitempty"` // "user", "moderator", "admin"
+	Suspended      bool       `json:"suspended,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"` // nil with Suspended means indefinitely
+}
+
+// Profile is the projection of a user shown to another user. The personal
+// fields are nil when the viewer is not allowed to see them.
+type Profile struct {
+	ID         int64      `json:"id"`
+	Username   string     `json:"username"`
+	AvatarPath string     `json:"avatar_path,omitempty"`
+	IsPublic   bool       `json:"is_public"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FirstName  *string    `json:"first_name,omitempty"`
+	LastName   *string    `json:"last_name,omitempty"`
+	Email      *string    `json:"email,omitempty"`
+	BirthDate  *time.Time `json:"birth_date,omitempty"`
+	AboutMe    *string    `json:"about_me,omitempty"`
+
+	Restricted bool            `json:"restricted"`        // private profile seen by a non-follower
+	Privacy    *ProfilePrivacy `json:"privacy,omitempty"` // only sent to the owner
+}
+
+// ProfilePrivacy holds the visibility of each personal field of a profile:
+// "public", "followers" or "only_me".
+type ProfilePrivacy struct {
+	Email     string `json:"email"`
+	BirthDate string `json:"birth_date"`
+	AboutMe   string `json:"about_me"`
+	RealName  string `json:"real_name"`
 }
 
 // Post model
@@ -30,6 +61,24 @@ type Post struct {
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 	CommentsCount int64     `json:"comments_count"`
+	GroupID       *int64    `json:"group_id,omitempty"` // only the group members can see the post
+
+	// Group posts only: pinned posts come first, announcements notify every member
+	PinnedAt       *time.Time `json:"pinned_at,omitempty"`
+	IsAnnouncement bool       `json:"is_announcement"`
+
+	LinkPreviews []*LinkPreview `json:"link_previews,omitempty"`
+}
+
+// LinkPreview model - cached OpenGraph / Twitter card metadata of a URL
+type LinkPreview struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	ImageURL    string    `json:"image_url,omitempty"`
+	SiteName    string    `json:"site_name,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
 }
 
 // Comment model
@@ -43,6 +92,26 @@ type Comment struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	Username  string    `json:"username"`
 	Author    User      `json:"author"`
+
+	ParentID     *int64     `json:"parent_id"`
+	Depth        int        `json:"depth"` // 0 for a top-level comment
+	RepliesCount int64      `json:"replies_count"`
+	Replies      []*Comment `json:"replies,omitempty"` // first replies only, see RepliesCount
+	LikesCount   int64      `json:"likes_count"`
+}
+
+// CommentPage is a page of top-level comments. NextCursor is empty on the
+// last page.
+type CommentPage struct {
+	Comments   []*Comment `json:"comments"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	HasMore    bool       `json:"has_more"`
+}
+
+// CommentCount is the number of visible comments of a post.
+type CommentCount struct {
+	Total    int64 `json:"total"`
+	TopLevel int64 `json:"top_level"`
 }
 
 // Follower model
@@ -53,6 +122,32 @@ type Follower struct {
 	FollowedAt time.Time `json:"followed_at"`
 }
 
+// FollowRequest is a pending follow request, seen from one of its two users.
+type FollowRequest struct {
+	UserID      int64     `json:"user_id"`
+	Username    string    `json:"username"`
+	AvatarPath  string    `json:"avatar_path"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// FollowRequestPage is a page of incoming or outgoing follow requests.
+type FollowRequestPage struct {
+	Requests []*FollowRequest `json:"requests"`
+	Total    int64            `json:"total"`
+	HasMore  bool             `json:"has_more"`
+}
+
+// FollowSuggestion is a user the viewer may know, with the signals behind it.
+type FollowSuggestion struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	AvatarPath    string `json:"avatar_path"`
+	MutualFollows int64  `json:"mutual_follows"`
+	SharedGroups  int64  `json:"shared_groups"`
+	SharedEvents  int64  `json:"shared_events"`
+	Score         int64  `json:"score"`
+}
+
 // Notification model
 type Notification struct {
 	ID            int64     `json:"id"`
@@ -65,6 +160,32 @@ type Notification struct {
 	CreatedAt     time.Time `json:"created_at"`
 }
 
+// Report model - a user flagging a piece of content or another user
+type Report struct {
+	ID         int64      `json:"id"`
+	ReporterID int64      `json:"reporter_id"` // 0 for reports raised by the content filters
+	TargetType string     `json:"target_type"` // "post", "comment", "message", "group_message", "user"
+	TargetID   int64      `json:"target_id"`
+	Reason     string     `json:"reason"`
+	Details    *string    `json:"details"`
+	Status     string     `json:"status"` // "pending", "resolved", "dismissed"
+	ResolvedBy *int64     `json:"resolved_by"`
+	Resolution *string    `json:"resolution"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+
+	ReporterUsername string          `json:"reporter_username,omitempty"`
+	Target           *ReportedTarget `json:"target,omitempty"`
+}
+
+// ReportedTarget is a snapshot of the reported content shown to moderators
+type ReportedTarget struct {
+	AuthorID       int64  `json:"author_id"`
+	AuthorUsername string `json:"author_username"`
+	Content        string `json:"content"`
+	Hidden         bool   `json:"hidden"`
+}
+
 // Session model
 type Session struct {
 	ID           int64     `json:"id"`
@@ -111,19 +232,57 @@ type Message struct {
 	Content        string     `json:"content"`
 	CreatedAt      time.Time  `json:"created_at"`
 	ReadAt         *time.Time `json:"read_at"`
+
+	LinkPreviews []*LinkPreview `json:"link_previews,omitempty"`
 }
 
 // --- Group models ---
 
 // Group model
 type Group struct {
-	ID          int64     `json:"id"`
-	CreatorID   int64     `json:"creator_id"`
-	CreatorName string    `json:"creator_name"`
-	Title       string    `json:"title"`
-	Description *string   `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64      `json:"id"`
+	CreatorID   int64      `json:"creator_id"`
+	CreatorName string     `json:"creator_name"`
+	Title       string     `json:"title"`
+	Description *string    `json:"description"`
+	Visibility  string     `json:"visibility"` // "public", "private" or "secret"
+	Category    string     `json:"category,omitempty"`
+	CoverPath   string     `json:"cover_path,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// GroupDirectoryEntry is a group as listed in the discovery directory.
+// FriendCount is only set on recommendations.
+type GroupDirectoryEntry struct {
+	ID             int64     `json:"id"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Visibility     string    `json:"visibility"`
+	Category       string    `json:"category"`
+	CoverPath      string    `json:"cover_path"`
+	Tags           []string  `json:"tags"`
+	MemberCount    int64     `json:"member_count"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	IsMember       bool      `json:"is_member"`
+	FriendCount    int64     `json:"friend_count,omitempty"`
+}
+
+// GroupDirectoryPage is a page of the discovery directory.
+type GroupDirectoryPage struct {
+	Groups  []*GroupDirectoryEntry `json:"groups"`
+	Total   int64                  `json:"total"`
+	HasMore bool                   `json:"has_more"`
+}
+
+// DeletedGroup is a deleted group its owner can still restore.
+type DeletedGroup struct {
+	ID           int64     `json:"id"`
+	Title        string    `json:"title"`
+	Visibility   string    `json:"visibility"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	RestoreUntil time.Time `json:"restore_until"`
 }
 
 // GroupPrivacy model
@@ -141,6 +300,7 @@ type GroupMember struct {
 	UserID    int64     `json:"user_id"`
 	Username  string    `json:"username"`
 	Accepted  bool      `json:"accepted"`
+	Role      string    `json:"role"` // "owner", "admin", "moderator" or "member"
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -154,6 +314,122 @@ type GroupInvitation struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// GroupJoinRequest is a request of a user to join a group.
+type GroupJoinRequest struct {
+	ID          int64      `json:"id"`
+	GroupID     int64      `json:"group_id"`
+	RequesterID int64      `json:"requester_id"`
+	Username    string     `json:"username"`
+	AvatarPath  string     `json:"avatar_path"`
+	Message     string     `json:"message"`
+	Status      string     `json:"status"` // "pending", "approved", "rejected" or "withdrawn"
+	DecidedBy   *int64     `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Answers []*JoinRequestAnswer `json:"answers,omitempty"`
+}
+
+// GroupQuestion is a question asked to the users requesting to join a group.
+type GroupQuestion struct {
+	ID       int64  `json:"id"`
+	GroupID  int64  `json:"group_id"`
+	Position int    `json:"position"`
+	Question string `json:"question"`
+	Required bool   `json:"required"`
+}
+
+// JoinRequestAnswer is the answer of a join request to a group question. The
+// question is copied, so the answer still reads well once it is edited.
+type JoinRequestAnswer struct {
+	QuestionID *int64 `json:"question_id,omitempty"`
+	Question   string `json:"question"`
+	Answer     string `json:"answer"`
+}
+
+// GroupRules are the rules of a group, as seen by a user. A version of 0
+// means the group never had rules.
+type GroupRules struct {
+	Rules           string     `json:"rules"`
+	Version         int        `json:"version"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+	AcceptedVersion int        `json:"accepted_version"`
+	MustAccept      bool       `json:"must_accept"`
+}
+
+// GroupBan is a user banned from a group.
+type GroupBan struct {
+	GroupID    int64     `json:"group_id"`
+	UserID     int64     `json:"user_id"`
+	Username   string    `json:"username"`
+	AvatarPath string    `json:"avatar_path"`
+	BannedBy   *int64    `json:"banned_by,omitempty"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// GroupInviteLink is a shareable link to join a group.
+type GroupInviteLink struct {
+	ID        int64      `json:"id"`
+	GroupID   int64      `json:"group_id"`
+	Token     string     `json:"token"`
+	CreatedBy *int64     `json:"created_by,omitempty"`
+	Role      string     `json:"role"`
+	MaxUses   *int64     `json:"max_uses,omitempty"`
+	Uses      int64      `json:"uses"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// GroupInvitePreview is what a user sees of a group before joining it through a link.
+type GroupInvitePreview struct {
+	GroupID     int64      `json:"group_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Visibility  string     `json:"visibility"`
+	MemberCount int64      `json:"member_count"`
+	Role        string     `json:"role"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	IsMember    bool       `json:"is_member"`
+}
+
+// GroupInviteLinkUse is a user who joined a group through a link.
+type GroupInviteLinkUse struct {
+	UserID     int64     `json:"user_id"`
+	Username   string    `json:"username"`
+	AvatarPath string    `json:"avatar_path"`
+	JoinedAt   time.Time `json:"joined_at"`
+}
+
+// AnnouncementAcknowledgement is a member who has read a group announcement.
+type AnnouncementAcknowledgement struct {
+	UserID         int64     `json:"user_id"`
+	Username       string    `json:"username"`
+	AvatarPath     string    `json:"avatar_path"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+}
+
+// GroupAuditEntry is an action recorded in the audit log of a group.
+type GroupAuditEntry struct {
+	ID         int64     `json:"id"`
+	GroupID    int64     `json:"group_id"`
+	ActorID    *int64    `json:"actor_id,omitempty"`
+	ActorName  string    `json:"actor_name"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   *int64    `json:"target_id,omitempty"`
+	Details    string    `json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// GroupAuditPage is a page of the audit log of a group.
+type GroupAuditPage struct {
+	Entries []*GroupAuditEntry `json:"entries"`
+	Total   int64              `json:"total"`
+	HasMore bool               `json:"has_more"`
+}
+
 // GroupMessage model
 type GroupMessage struct {
 	ID        int64     `json:"id"`
@@ -165,42 +441,45 @@ type GroupMessage struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// GroupPost model
-type GroupPost struct {
-	ID            int64     `json:"id"`
-	GroupID       int64     `json:"group_id"`
-	UserID        int64     `json:"user_id"`
-	Username      string    `json:"username"`
-	Content       string    `json:"content"`
-	ImagePath     *string   `json:"image_path,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	CommentsCount int64     `json:"comments_count"`
-}
-
-// GroupComment model
-type GroupComment struct {
-	ID          int64     `json:"id"`
-	GroupPostID int64     `json:"group_post_id"`
-	UserID      int64     `json:"user_id"`
-	Username    string    `json:"username"`
-	Content     string    `json:"content"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-}
-
 // --- Event models ---
 
 // Event model
 type Event struct {
-	ID          int64     `json:"id"`
-	GroupID     int64     `json:"group_id"`
-	CreatorID   int64     `json:"creator_id"`
-	Title       string    `json:"title"`
-	Description *string   `json:"description"`
-	EventDate   time.Time `json:"event_date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           int64      `json:"id"`
+	GroupID      int64      `json:"group_id"`
+	CreatorID    int64      `json:"creator_id"`
+	Title        string     `json:"title"`
+	Description  *string    `json:"description"`
+	EventDate    time.Time  `json:"event_date"`
+	Location     string     `json:"location"`
+	Capacity     *int64     `json:"capacity,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy  *int64     `json:"cancelled_by,omitempty"`
+	CancelReason string     `json:"cancel_reason,omitempty"`
+	// Recurrence is the RRULE of a series; its occurrences have a SeriesID
+	// and the OccurrenceDate the rule planned them at.
+	Recurrence     string     `json:"recurrence,omitempty"`
+	SeriesID       *int64     `json:"series_id,omitempty"`
+	OccurrenceDate *time.Time `json:"occurrence_date,omitempty"`
+	// TimeZone is the IANA zone a series repeats in, so that its occurrences
+	// keep their local time across daylight saving changes.
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+// EventChange is an entry of the change history of an event. Cancellations
+// are recorded with the "status" field.
+type EventChange struct {
+	ID        int64     `json:"id"`
+	EventID   int64     `json:"event_id"`
+	ChangedBy *int64    `json:"changed_by"`
+	Username  string    `json:"username,omitempty"`
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	Reason    string    `json:"reason,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
 }
 
 // EventResponse model
@@ -226,9 +505,22 @@ type EventWithResponses struct {
 	Title              string              `json:"title"`
 	Description        *string             `json:"description"`
 	EventDate          time.Time           `json:"event_date"`
+	Location           string              `json:"location"`
+	Capacity           *int64              `json:"capacity,omitempty"`
 	CreatedAt          time.Time           `json:"created_at"`
 	UpdatedAt          time.Time           `json:"updated_at"`
+	CancelledAt        *time.Time          `json:"cancelled_at,omitempty"`
+	CancelledBy        *int64              `json:"cancelled_by,omitempty"`
+	CancelReason       string              `json:"cancel_reason,omitempty"`
+	Recurrence         string              `json:"recurrence,omitempty"`
+	SeriesID           *int64              `json:"series_id,omitempty"`
+	OccurrenceDate     *time.Time          `json:"occurrence_date,omitempty"`
+	TimeZone           string              `json:"time_zone,omitempty"`
 	UserResponseStatus *string             `json:"user_response_status,omitempty"`
 	Participants       []*EventParticipant `json:"participants"`
 	NonParticipants    []*EventParticipant `json:"non_participants"`
+	MaybeParticipants  []*EventParticipant `json:"maybe_participants"`
+	Waitlist           []*EventParticipant `json:"waitlist"` // first in line first
+	AttendeeCount      int                 `json:"attendee_count"`
+	WaitlistCount      int                 `json:"waitlist_count"`
 }
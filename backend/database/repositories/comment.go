@@ -2,7 +2,11 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 )
 
@@ -16,12 +20,138 @@ func NewCommentRepository(db *sql.DB) *CommentRepository {
 	return &CommentRepository{db: db}
 }
 
+const (
+	// MaxCommentDepth is the deepest level of a reply, top-level comments being 0
+	MaxCommentDepth = 2
+
+	// RepliesPreviewSize is the number of replies returned along with their parent
+	RepliesPreviewSize = 3
+)
+
+// Sort modes of the top-level comments
+const (
+	CommentSortOldest      = "oldest"
+	CommentSortNewest      = "newest"
+	CommentSortMostReacted = "most_reacted"
+
+	DefaultCommentsPageSize = 20
+	MaxCommentsPageSize     = 100
+)
+
+var (
+	ErrInvalidCommentSort = errors.New("invalid comment sort")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+)
+
+// CommentPageQuery selects a page of top-level comments. The cursor is the
+// NextCursor of the previous page, empty for the first one.
+type CommentPageQuery struct {
+	Sort   string
+	Cursor string
+	Limit  int
+	Viewer int64 // comments of users blocking or blocked by the viewer are skipped
+}
+
+// commentCursor is the position of the last comment of a page. Comment ids
+// follow the creation order, so they are used as the chronological key.
+type commentCursor struct {
+	Sort  string `json:"s"`
+	ID    int64  `json:"id"`
+	Likes int64  `json:"l,omitempty"`
+}
+
+func encodeCursor(cursor commentCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value, sort string) (*commentCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := &commentCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// pageClause returns the condition, order and arguments selecting the page
+// described by the query.
+func (q *CommentPageQuery) pageClause() (string, string, []any, error) {
+	if q.Sort == "" {
+		q.Sort = CommentSortOldest
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultCommentsPageSize
+	}
+	if q.Limit > MaxCommentsPageSize {
+		q.Limit = MaxCommentsPageSize
+	}
+
+	var cursor *commentCursor
+	if q.Cursor != "" {
+		var err error
+		if cursor, err = decodeCursor(q.Cursor, q.Sort); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	const idColumn, likesExpr = "c.id", commentLikesExpr
+	switch q.Sort {
+	case CommentSortOldest:
+		if cursor == nil {
+			return "1 = 1", idColumn + " ASC", nil, nil
+		}
+		return idColumn + " > ?", idColumn + " ASC", []any{cursor.ID}, nil
+	case CommentSortNewest:
+		if cursor == nil {
+			return "1 = 1", idColumn + " DESC", nil, nil
+		}
+		return idColumn + " < ?", idColumn + " DESC", []any{cursor.ID}, nil
+	case CommentSortMostReacted:
+		order := likesExpr + " DESC, " + idColumn + " DESC"
+		if cursor == nil {
+			return "1 = 1", order, nil, nil
+		}
+		where := "(" + likesExpr + " < ? OR (" + likesExpr + " = ? AND " + idColumn + " < ?))"
+		return where, order, []any{cursor.Likes, cursor.Likes, cursor.ID}, nil
+	default:
+		return "", "", nil, ErrInvalidCommentSort
+	}
+}
+
+// nextCursor returns the cursor following the comment at id with likes.
+func (q *CommentPageQuery) nextCursor(id, likes int64) string {
+	cursor := commentCursor{Sort: q.Sort, ID: id}
+	if q.Sort == CommentSortMostReacted {
+		cursor.Likes = likes
+	}
+	return encodeCursor(cursor)
+}
+
+const commentLikesExpr = `(SELECT COUNT(*) FROM comment_like cl WHERE cl.comment_id = c.id)`
+
+// commentColumns is scanned by scanComment. The replies count skips hidden
+// replies and the ones written by suspended users, like the lists do.
+const commentColumns = `
+	c.id, c.post_id, c.user_id, c.content, c.image_path, c.created_at, c.updated_at,
+	c.parent_id, c.depth,
+	(
+		SELECT COUNT(*) FROM comments rc
+		JOIN users u ON u.id = rc.user_id
+		WHERE rc.parent_id = c.id AND rc.is_hidden = 0 AND ` + services.ActiveUserCondition + `
+	),
+	` + commentLikesExpr + `,
+	u.id, u.avatar_path, u.username, u.about_me, u.is_public, u.created_at`
+
 // Create a new comment in the database
 func (r *CommentRepository) Create(comment *models.Comment) (int64, error) {
 	stmt, err := r.db.Prepare(`
 		INSERT INTO comments(
-			post_id, user_id, content, image_path, created_at, updated_at
-		) VALUES(?, ?, ?, ?, ?, ?)
+			post_id, user_id, content, image_path, created_at, updated_at, parent_id, depth
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 
 	if err != nil {
@@ -36,6 +166,8 @@ func (r *CommentRepository) Create(comment *models.Comment) (int64, error) {
 		comment.ImagePath,
 		comment.CreatedAt,
 		comment.UpdatedAt,
+		comment.ParentID,
+		comment.Depth,
 	)
 
 	if err != nil {
@@ -55,93 +187,149 @@ func (r *CommentRepository) Create(comment *models.Comment) (int64, error) {
 // Get a comment by ID
 func (r *CommentRepository) GetByID(id int64) (*models.Comment, error) {
 	stmt, err := r.db.Prepare(`
-		SELECT 
-			c.id, c.post_id, c.user_id, c.content, c.image_path, c.created_at, c.updated_at,
-			u.id, u.avatar_path, u.username, u.about_me, u.is_public, u.created_at
+		SELECT ` + commentColumns + `
 		FROM comments c
 		JOIN users u ON c.user_id = u.id
-		WHERE c.id = ?
+		WHERE c.id = ? AND c.is_hidden = 0 AND ` + services.ActiveUserCondition + `
 	`)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	comment := &models.Comment{}
-	user := &models.User{}
+	return scanComment(stmt.QueryRow(id))
+}
 
-	err = stmt.QueryRow(id).Scan(
-		&comment.ID,
-		&comment.PostID,
-		&comment.UserID,
-		&comment.Content,
-		&comment.ImagePath,
-		&comment.CreatedAt,
-		&comment.UpdatedAt,
+// GetComments retrieves a page of the top-level comments of a post, including
+// user info and the first replies of each thread
+func (r *CommentRepository) GetComments(postID int64, query CommentPageQuery) (*models.CommentPage, error) {
+	where, order, args, err := query.pageClause()
+	if err != nil {
+		return nil, err
+	}
 
-		&user.ID,
-		&user.AvatarPath,
-		&user.Username,
-		&user.AboutMe,
-		&user.IsPublic,
-		&user.CreatedAt,
-	)
+	// Un commentaire de plus est lu pour savoir s'il reste une page
+	args = append([]any{postID, query.Viewer, query.Viewer}, args...)
+	args = append(args, query.Limit+1)
+	comments, err := r.queryComments(`
+		SELECT `+commentColumns+`
+		FROM comments c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.post_id = ? AND c.parent_id IS NULL AND c.is_hidden = 0 AND `+services.ActiveUserCondition+`
+		AND `+services.NotBlockedCondition("c.user_id")+`
+		AND `+where+`
+		ORDER BY `+order+`
+		LIMIT ?
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	print(user)
-	comment.Author = *user
-	comment.Username = user.Username
+	page := &models.CommentPage{Comments: comments}
+	if len(comments) > query.Limit {
+		page.Comments = comments[:query.Limit]
+		last := page.Comments[query.Limit-1]
+		page.HasMore = true
+		page.NextCursor = query.nextCursor(last.ID, last.LikesCount)
+	}
+	if page.Comments == nil {
+		page.Comments = []*models.Comment{}
+	}
 
-	return comment, nil
+	if err := r.attachReplies(page.Comments, query.Viewer); err != nil {
+		return nil, err
+	}
+	return page, nil
 }
 
-// GetComments retrieves the list of comments for a post
-// GetComments retrieves the list of comments for a post, including user info
-func (r *CommentRepository) GetComments(postID int64) ([]*models.Comment, error) {
-	rows, err := r.db.Query(`
-		SELECT 
-			c.id, c.post_id, c.user_id, c.content, c.image_path, c.created_at, c.updated_at,
-			u.id, u.avatar_path, u.username, u.about_me, u.is_public, u.created_at
+// CountComments counts the visible comments of a post
+func (r *CommentRepository) CountComments(postID int64) (*models.CommentCount, error) {
+	count := &models.CommentCount{}
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(c.parent_id IS NULL), 0)
 		FROM comments c
 		JOIN users u ON c.user_id = u.id
-		WHERE c.post_id = ?
+		WHERE c.post_id = ? AND c.is_hidden = 0 AND `+services.ActiveUserCondition+`
+	`, postID).Scan(&count.Total, &count.TopLevel)
+	if err != nil {
+		return nil, err
+	}
+	return count, nil
+}
+
+// ToggleLike adds or removes the like of a user on a comment and returns the
+// new state with the likes count
+func (r *CommentRepository) ToggleLike(commentID, userID int64) (bool, int64, error) {
+	result, err := r.db.Exec(`DELETE FROM comment_like WHERE comment_id = ? AND user_id = ?`, commentID, userID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Le like est ajouté s'il n'y avait rien à retirer
+	liked := false
+	if n, _ := result.RowsAffected(); n == 0 {
+		_, err = r.db.Exec(`INSERT INTO comment_like (comment_id, user_id) VALUES (?, ?)`, commentID, userID)
+		if err != nil {
+			return false, 0, err
+		}
+		liked = true
+	}
+
+	var count int64
+	err = r.db.QueryRow(`SELECT COUNT(*) FROM comment_like WHERE comment_id = ?`, commentID).Scan(&count)
+	return liked, count, err
+}
+
+// GetReplies retrieves a page of the direct replies of a comment, oldest first,
+// skipping the users blocking or blocked by the viewer
+func (r *CommentRepository) GetReplies(parentID, viewerID int64, limit, offset int) ([]*models.Comment, error) {
+	replies, err := r.queryComments(`
+		SELECT `+commentColumns+`
+		FROM comments c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.parent_id = ? AND c.is_hidden = 0 AND `+services.ActiveUserCondition+`
+		AND `+services.NotBlockedCondition("c.user_id")+`
 		ORDER BY c.created_at ASC
-	`, postID)
+		LIMIT ? OFFSET ?
+	`, parentID, viewerID, viewerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.attachReplies(replies, viewerID); err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
+
+// attachReplies loads the first replies of every comment having some
+func (r *CommentRepository) attachReplies(comments []*models.Comment, viewerID int64) error {
+	for _, comment := range comments {
+		if comment.RepliesCount == 0 || comment.Depth >= MaxCommentDepth {
+			continue
+		}
+		replies, err := r.GetReplies(comment.ID, viewerID, RepliesPreviewSize, 0)
+		if err != nil {
+			return err
+		}
+		comment.Replies = replies
+	}
+	return nil
+}
+
+func (r *CommentRepository) queryComments(query string, args ...any) ([]*models.Comment, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var comments []*models.Comment
-
 	for rows.Next() {
-		comment := &models.Comment{}
-		user := &models.User{}
-
-		err := rows.Scan(
-			&comment.ID,
-			&comment.PostID,
-			&comment.UserID,
-			&comment.Content,
-			&comment.ImagePath,
-			&comment.CreatedAt,
-			&comment.UpdatedAt,
-
-			&user.ID,
-			&user.AvatarPath,
-			&user.Username,
-			&user.AboutMe,
-			&user.IsPublic,
-			&user.CreatedAt,
-		)
+		comment, err := scanComment(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		comment.Author = *user
-		comment.Username = user.Username
 		comments = append(comments, comment)
 	}
 
@@ -152,17 +340,54 @@ func (r *CommentRepository) GetComments(postID int64) ([]*models.Comment, error)
 	return comments, nil
 }
 
-func (r *CommentRepository) GetCommentsFromUserByID(userID int64) ([]*models.Comment, error) {
+func scanComment(row rowScanner) (*models.Comment, error) {
+	comment := &models.Comment{}
+	user := &models.User{}
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.Content,
+		&comment.ImagePath,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+		&comment.ParentID,
+		&comment.Depth,
+		&comment.RepliesCount,
+		&comment.LikesCount,
+
+		&user.ID,
+		&user.AvatarPath,
+		&user.Username,
+		&user.AboutMe,
+		&user.IsPublic,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.Author = *user
+	comment.Username = user.Username
+	return comment, nil
+}
+
+func (r *CommentRepository) GetCommentsFromUserByID(userID, viewerID int64) ([]*models.Comment, error) {
+	visible, visibleArgs := services.VisiblePostCondition(viewerID)
 	rows, err := r.db.Query(`
-		SELECT id, post_id, user_id, content, image_path, created_at, updated_at
-		FROM comments WHERE user_id = ?
-	`, userID)
+		SELECT c.id, c.post_id, c.user_id, c.content, c.image_path, c.created_at, c.updated_at
+		FROM comments c
+		JOIN users u ON u.id = c.user_id
+		JOIN posts p ON p.id = c.post_id
+		WHERE c.user_id = ? AND c.is_hidden = 0 AND `+services.ActiveUserCondition+` AND `+visible,
+		append([]any{userID}, visibleArgs...)...)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var comments []*models.Comment
+	comments := []*models.Comment{}
 
 	for rows.Next() {
 		c := &models.Comment{}
@@ -5,8 +5,11 @@ import "social-network/backend/database/models"
 type CommentRepositoryInterface interface {
 	Create(post *models.Post) (int64, error)
 	GetByID(id int64) (*models.Post, error)
-	GetComments(postID int64) ([]*models.Comment, error)
-	GetCommentsFromUserByID(userId int64) ([]*models.Comment, error)
+	GetComments(postID int64, query CommentPageQuery) (*models.CommentPage, error)
+	CountComments(postID int64) (*models.CommentCount, error)
+	GetReplies(parentID, viewerID int64, limit, offset int) ([]*models.Comment, error)
+	GetCommentsFromUserByID(userId, viewerID int64) ([]*models.Comment, error)
 	Update(post *models.Post) error
+	ToggleLike(commentID, userID int64) (bool, int64, error)
 	Delete(id int64) error
 }
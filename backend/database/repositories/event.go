@@ -2,11 +2,27 @@ package repository
 
 import (
 	"database/sql"
+	"sort"
+	"strings"
 	"time"
 
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 )
 
+// OccurrenceDroppedReason is the cancel reason of the occurrences a new rule
+// of their series no longer produces.
+const OccurrenceDroppedReason = "Date retirée de la série"
+
+// Answers to an event. Users cannot pick EventWaitlisted: they are put on
+// the waitlist when they answer going to a full event.
+const (
+	EventGoing      = "going"
+	EventMaybe      = "maybe"
+	EventNotGoing   = "not_going"
+	EventWaitlisted = "waitlisted"
+)
+
 // Connection to the database
 type EventRepository struct {
 	db *sql.DB
@@ -17,23 +33,28 @@ func NewEventRepository(db *sql.DB) *EventRepository {
 	return &EventRepository{db: db}
 }
 
-// Create a new event in the database
+// Create a new event in the database, with its entry in the audit log of the
+// group
 func (r *EventRepository) CreateEvent(event *models.Event) (int64, error) {
-	stmt, err := r.db.Prepare(`
-		INSERT INTO events (group_id, creator_id, title, description, event_date, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return 0, err
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	result, err := stmt.Exec(
+	result, err := tx.Exec(`
+		INSERT INTO events (group_id, creator_id, title, description, event_date, location, capacity, recurrence, time_zone, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
 		event.GroupID,
 		event.CreatorID,
 		event.Title,
 		event.Description,
 		event.EventDate,
+		event.Location,
+		event.Capacity,
+		event.Recurrence,
+		event.TimeZone,
 		event.CreatedAt,
 		event.UpdatedAt,
 	)
@@ -41,13 +62,21 @@ func (r *EventRepository) CreateEvent(event *models.Event) (int64, error) {
 		return 0, err
 	}
 
-	return result.LastInsertId()
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := services.RecordAudit(tx, event.GroupID, event.CreatorID, services.AuditEventCreated, "event", id, event.Title); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
 }
 
 func (r *EventRepository) GetByID(eventID int64) (*models.Event, error) {
 	var event models.Event
 	err := r.db.QueryRow(`
-		SELECT id, group_id, creator_id, title, description, event_date, created_at, updated_at
+		SELECT id, group_id, creator_id, title, description, event_date, location, capacity, created_at, updated_at,
+			cancelled_at, cancelled_by, cancel_reason, recurrence, series_id, occurrence_date, time_zone
 		FROM events
 		WHERE id = ?
 	`, eventID).Scan(
@@ -57,8 +86,17 @@ func (r *EventRepository) GetByID(eventID int64) (*models.Event, error) {
 		&event.Title,
 		&event.Description,
 		&event.EventDate,
+		&event.Location,
+		&event.Capacity,
 		&event.CreatedAt,
 		&event.UpdatedAt,
+		&event.CancelledAt,
+		&event.CancelledBy,
+		&event.CancelReason,
+		&event.Recurrence,
+		&event.SeriesID,
+		&event.OccurrenceDate,
+		&event.TimeZone,
 	)
 	if err != nil {
 		return nil, err
@@ -72,7 +110,8 @@ func (r *EventRepository) GetEventWithResponsesByID(eventID, userID int64) (*mod
 
 	err := r.db.QueryRow(`
 		SELECT 
-			e.id, e.group_id, e.creator_id, e.title, e.description, e.event_date, e.created_at, e.updated_at,
+			e.id, e.group_id, e.creator_id, e.title, e.description, e.event_date, e.location, e.capacity, e.created_at, e.updated_at,
+			e.cancelled_at, e.cancelled_by, e.cancel_reason, e.recurrence, e.series_id, e.occurrence_date, e.time_zone,
 			COALESCE(user_response.status, '') as user_response_status
 		FROM events e
 		LEFT JOIN event_responses user_response ON e.id = user_response.event_id AND user_response.user_id = ?
@@ -84,8 +123,17 @@ func (r *EventRepository) GetEventWithResponsesByID(eventID, userID int64) (*mod
 		&event.Title,
 		&event.Description,
 		&event.EventDate,
+		&event.Location,
+		&event.Capacity,
 		&event.CreatedAt,
 		&event.UpdatedAt,
+		&event.CancelledAt,
+		&event.CancelledBy,
+		&event.CancelReason,
+		&event.Recurrence,
+		&event.SeriesID,
+		&event.OccurrenceDate,
+		&event.TimeZone,
 		&userResponseStatus,
 	)
 	if err != nil {
@@ -97,25 +145,10 @@ func (r *EventRepository) GetEventWithResponsesByID(eventID, userID int64) (*mod
 		event.UserResponseStatus = &userResponseStatus
 	}
 
-	// Get participants for this event
-	participants, err := r.getEventParticipants(event.ID)
-	if err != nil {
+	// Get the responses for this event
+	if err := r.attachResponses(&event); err != nil {
 		return nil, err
 	}
-	if participants == nil {
-		participants = []*models.EventParticipant{}
-	}
-	event.Participants = participants
-
-	// Get non-participants for this event
-	nonParticipants, err := r.getEventNonParticipants(event.ID)
-	if err != nil {
-		return nil, err
-	}
-	if nonParticipants == nil {
-		nonParticipants = []*models.EventParticipant{}
-	}
-	event.NonParticipants = nonParticipants
 
 	return &event, nil
 }
@@ -127,7 +160,8 @@ func (r *EventRepository) GetEventWithResponsesForBroadcast(eventID int64) (*mod
 
 	err := r.db.QueryRow(`
 		SELECT 
-			e.id, e.group_id, e.creator_id, e.title, e.description, e.event_date, e.created_at, e.updated_at
+			e.id, e.group_id, e.creator_id, e.title, e.description, e.event_date, e.location, e.capacity, e.created_at, e.updated_at,
+			e.cancelled_at, e.cancelled_by, e.cancel_reason, e.recurrence, e.series_id, e.occurrence_date, e.time_zone
 		FROM events e
 		WHERE e.id = ?
 	`, eventID).Scan(
@@ -137,8 +171,17 @@ func (r *EventRepository) GetEventWithResponsesForBroadcast(eventID int64) (*mod
 		&event.Title,
 		&event.Description,
 		&event.EventDate,
+		&event.Location,
+		&event.Capacity,
 		&event.CreatedAt,
 		&event.UpdatedAt,
+		&event.CancelledAt,
+		&event.CancelledBy,
+		&event.CancelReason,
+		&event.Recurrence,
+		&event.SeriesID,
+		&event.OccurrenceDate,
+		&event.TimeZone,
 	)
 	if err != nil {
 		return nil, err
@@ -146,41 +189,69 @@ func (r *EventRepository) GetEventWithResponsesForBroadcast(eventID int64) (*mod
 
 	// Don't set UserResponseStatus - leave it nil so each client preserves their own status
 
-	// Get participants for this event
-	participants, err := r.getEventParticipants(event.ID)
-	if err != nil {
+	// Get the responses for this event
+	if err := r.attachResponses(&event); err != nil {
 		return nil, err
 	}
-	if participants == nil {
-		participants = []*models.EventParticipant{}
-	}
-	event.Participants = participants
 
-	// Get non-participants for this event
-	nonParticipants, err := r.getEventNonParticipants(event.ID)
+	return &event, nil
+}
+
+// SetEventResponse records the answer of a user to an event and returns the
+// status actually saved: going users are waitlisted once the event is full.
+// When a going user changes their mind, the first waitlisted users take the
+// freed seats; their IDs are returned.
+func (r *EventRepository) SetEventResponse(eventID, userID int64, status string) (string, []int64, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
-	if nonParticipants == nil {
-		nonParticipants = []*models.EventParticipant{}
+	defer tx.Rollback()
+
+	var previous string
+	err = tx.QueryRow(`
+		SELECT status FROM event_responses WHERE event_id = ? AND user_id = ?
+	`, eventID, userID).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return "", nil, err
 	}
-	event.NonParticipants = nonParticipants
 
-	return &event, nil
-}
+	if status == EventGoing && previous != EventGoing {
+		seats, err := services.FreeSeats(tx, eventID)
+		if err != nil {
+			return "", nil, err
+		}
+		if seats == 0 {
+			status = EventWaitlisted
+		}
+	}
 
-func (r *EventRepository) SetEventResponse(eventID, userID int64, status string) error {
-	_, err := r.db.Exec(`
-		INSERT INTO event_responses (event_id, user_id, status, created_at)
-		VALUES (?, ?, ?, ?)
-		ON CONFLICT(event_id, user_id) DO UPDATE SET status = excluded.status
-	`, eventID, userID, status, time.Now())
-	return err
+	// Un utilisateur qui reste en liste d'attente garde sa place
+	now := time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO event_responses (event_id, user_id, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(event_id, user_id) DO UPDATE SET
+			status = excluded.status,
+			updated_at = CASE WHEN status = excluded.status THEN updated_at ELSE excluded.updated_at END
+	`, eventID, userID, status, now, now)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var promoted []int64
+	if previous == EventGoing && status != EventGoing {
+		if promoted, err = services.PromoteWaitlist(tx, eventID); err != nil {
+			return "", nil, err
+		}
+	}
+	return status, promoted, tx.Commit()
 }
 
 func (r *EventRepository) GetEventsByGroupID(groupID int64) ([]*models.Event, error) {
 	rows, err := r.db.Query(`
-		SELECT id, group_id, creator_id, title, description, event_date, created_at, updated_at
+		SELECT id, group_id, creator_id, title, description, event_date, location, capacity, created_at, updated_at,
+			cancelled_at, cancelled_by, cancel_reason, recurrence, series_id, occurrence_date, time_zone
 		FROM events
 		WHERE group_id = ?
 		ORDER BY event_date ASC
@@ -200,8 +271,17 @@ func (r *EventRepository) GetEventsByGroupID(groupID int64) ([]*models.Event, er
 			&event.Title,
 			&event.Description,
 			&event.EventDate,
+			&event.Location,
+			&event.Capacity,
 			&event.CreatedAt,
 			&event.UpdatedAt,
+			&event.CancelledAt,
+			&event.CancelledBy,
+			&event.CancelReason,
+			&event.Recurrence,
+			&event.SeriesID,
+			&event.OccurrenceDate,
+			&event.TimeZone,
 		)
 		if err != nil {
 			return nil, err
@@ -212,16 +292,53 @@ func (r *EventRepository) GetEventsByGroupID(groupID int64) ([]*models.Event, er
 	return events, nil
 }
 
-func (r *EventRepository) GetEventsWithResponsesByGroupID(groupID, userID int64) ([]*models.EventWithResponses, error) {
+// GetEventsWithResponsesByGroupID lists the events of a group between from
+// and to, when set, with the answers to them. Series are expanded into their
+// occurrences, up to RecurrenceHorizon ahead when to is not set.
+func (r *EventRepository) GetEventsWithResponsesByGroupID(groupID, userID int64, from, to time.Time) ([]*models.EventWithResponses, error) {
+	events, err := r.queryEventsWithResponses(userID, `e.group_id = ? AND e.series_id IS NULL`, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var listed []*models.EventWithResponses
+	for _, event := range events {
+		if event.Recurrence != "" {
+			occurrences, err := r.expandSeries(event, userID, from, to)
+			if err != nil {
+				return nil, err
+			}
+			listed = append(listed, occurrences...)
+			continue
+		}
+		if (!from.IsZero() && event.EventDate.Before(from)) || (!to.IsZero() && event.EventDate.After(to)) {
+			continue
+		}
+
+		// Get the responses for this event
+		if err := r.attachResponses(event); err != nil {
+			return nil, err
+		}
+		listed = append(listed, event)
+	}
+
+	sort.SliceStable(listed, func(i, j int) bool { return listed[i].EventDate.Before(listed[j].EventDate) })
+	return listed, nil
+}
+
+// queryEventsWithResponses lists the events matching the condition, with the
+// answer of the user but without the other answers.
+func (r *EventRepository) queryEventsWithResponses(userID int64, condition string, args ...any) ([]*models.EventWithResponses, error) {
 	rows, err := r.db.Query(`
 		SELECT 
-			e.id, e.group_id, e.creator_id, e.title, e.description, e.event_date, e.created_at, e.updated_at,
+			e.id, e.group_id, e.creator_id, e.title, e.description, e.event_date, e.location, e.capacity, e.created_at, e.updated_at,
+			e.cancelled_at, e.cancelled_by, e.cancel_reason, e.recurrence, e.series_id, e.occurrence_date, e.time_zone,
 			COALESCE(user_response.status, '') as user_response_status
 		FROM events e
 		LEFT JOIN event_responses user_response ON e.id = user_response.event_id AND user_response.user_id = ?
-		WHERE e.group_id = ?
+		WHERE `+condition+`
 		ORDER BY e.event_date ASC
-	`, userID, groupID)
+	`, append([]any{userID}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -238,8 +355,17 @@ func (r *EventRepository) GetEventsWithResponsesByGroupID(groupID, userID int64)
 			&event.Title,
 			&event.Description,
 			&event.EventDate,
+			&event.Location,
+			&event.Capacity,
 			&event.CreatedAt,
 			&event.UpdatedAt,
+			&event.CancelledAt,
+			&event.CancelledBy,
+			&event.CancelReason,
+			&event.Recurrence,
+			&event.SeriesID,
+			&event.OccurrenceDate,
+			&event.TimeZone,
 			&userResponseStatus,
 		)
 		if err != nil {
@@ -249,47 +375,104 @@ func (r *EventRepository) GetEventsWithResponsesByGroupID(groupID, userID int64)
 		if userResponseStatus != "" {
 			event.UserResponseStatus = &userResponseStatus
 		}
+		events = append(events, &event)
+	}
 
-		// Get participants for this event
-		participants, err := r.getEventParticipants(event.ID)
-		if err != nil {
-			return nil, err
-		}
-		if participants == nil {
-			participants = []*models.EventParticipant{}
-		}
-		event.Participants = participants
+	return events, rows.Err()
+}
 
-		// Get non-participants for this event
-		nonParticipants, err := r.getEventNonParticipants(event.ID)
-		if err != nil {
-			return nil, err
+// expandSeries lists the occurrences of a series between from and to, from
+// now on by default and up to the recurrence horizon. Stored
+// occurrences carry their own edits and answers; the others are built from
+// the series, keep its ID and have no answers yet.
+func (r *EventRepository) expandSeries(series *models.EventWithResponses, userID int64, from, to time.Time) ([]*models.EventWithResponses, error) {
+	rule, err := services.ParseRecurrence(series.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	// Sans période, seules les occurrences à venir sont listées
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if to.IsZero() {
+		to = time.Now().Add(services.RecurrenceHorizon)
+	}
+
+	stored, err := r.queryEventsWithResponses(userID, `e.series_id = ?`, series.ID)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[int64]*models.EventWithResponses, len(stored))
+	for _, occurrence := range stored {
+		if occurrence.OccurrenceDate != nil {
+			byDate[occurrence.OccurrenceDate.Unix()] = occurrence
 		}
-		if nonParticipants == nil {
-			nonParticipants = []*models.EventParticipant{}
+	}
+
+	var occurrences []*models.EventWithResponses
+	start := services.InTimeZone(series.EventDate, series.TimeZone)
+	for _, date := range rule.Occurrences(start, from, to, services.MaxOccurrences) {
+		date = date.UTC()
+		if occurrence, ok := byDate[date.Unix()]; ok {
+			if err := r.attachResponses(occurrence); err != nil {
+				return nil, err
+			}
+			occurrences = append(occurrences, occurrence)
+			continue
 		}
-		event.NonParticipants = nonParticipants
 
-		events = append(events, &event)
+		occurrence := *series
+		occurrence.EventDate = date
+		occurrence.OccurrenceDate = &date
+		occurrence.SeriesID = &series.ID
+		occurrence.UserResponseStatus = nil
+		occurrence.Participants = []*models.EventParticipant{}
+		occurrence.NonParticipants = []*models.EventParticipant{}
+		occurrence.MaybeParticipants = []*models.EventParticipant{}
+		occurrence.Waitlist = []*models.EventParticipant{}
+		occurrences = append(occurrences, &occurrence)
 	}
+	return occurrences, nil
+}
 
-	return events, nil
+// attachResponses loads the users who answered an event, by status, and the
+// attendee and waitlist counts.
+func (r *EventRepository) attachResponses(event *models.EventWithResponses) error {
+	var err error
+	if event.Participants, err = r.getEventRespondents(event.ID, EventGoing); err != nil {
+		return err
+	}
+	if event.NonParticipants, err = r.getEventRespondents(event.ID, EventNotGoing); err != nil {
+		return err
+	}
+	if event.MaybeParticipants, err = r.getEventRespondents(event.ID, EventMaybe); err != nil {
+		return err
+	}
+	if event.Waitlist, err = r.getEventRespondents(event.ID, EventWaitlisted); err != nil {
+		return err
+	}
+	event.AttendeeCount = len(event.Participants)
+	event.WaitlistCount = len(event.Waitlist)
+	return nil
 }
 
-func (r *EventRepository) getEventParticipants(eventID int64) ([]*models.EventParticipant, error) {
+// getEventRespondents lists the users who gave an answer to an event, by
+// username, or in line order for the waitlist.
+func (r *EventRepository) getEventRespondents(eventID int64, status string) ([]*models.EventParticipant, error) {
 	rows, err := r.db.Query(`
 		SELECT u.id, u.username
 		FROM event_responses er
 		JOIN users u ON er.user_id = u.id
-		WHERE er.event_id = ? AND er.status = 'going'
-		ORDER BY u.username
-	`, eventID)
+		WHERE er.event_id = ? AND er.status = ?
+		ORDER BY CASE WHEN er.status = 'waitlisted' THEN er.updated_at END, u.username
+	`, eventID, status)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var participants []*models.EventParticipant
+	// Ensure we return an empty slice instead of nil
+	participants := []*models.EventParticipant{}
 	for rows.Next() {
 		var participant models.EventParticipant
 		err := rows.Scan(&participant.UserID, &participant.Username)
@@ -299,49 +482,369 @@ func (r *EventRepository) getEventParticipants(eventID int64) ([]*models.EventPa
 		participants = append(participants, &participant)
 	}
 
-	// Ensure we return an empty slice instead of nil
-	if participants == nil {
-		participants = []*models.EventParticipant{}
+	return participants, rows.Err()
+}
+
+func (r *EventRepository) DeleteEvent(eventID int64) error {
+	_, err := r.db.Exec(`
+		DELETE FROM events
+		WHERE id = ?
+	`, eventID)
+	return err
+}
+
+// UpdateEvent saves the edited fields of an event and appends the changes to
+// its history and the audit log of the group, in one transaction. The edits
+// of a series are carried over to its stored occurrences, and those its new
+// rule no longer produces are cancelled. Waitlisted users promoted by a raised
+// capacity are returned, with the users who answered a cancelled occurrence.
+func (r *EventRepository) UpdateEvent(event *models.Event, actorID int64, changes []*models.EventChange) ([]int64, []int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var promoted, removed []int64
+	if event.Recurrence != "" {
+		if promoted, removed, err = updateOccurrences(tx, event, actorID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE events SET title = ?, description = ?, event_date = ?, location = ?, capacity = ?, recurrence = ?, updated_at = ?
+		WHERE id = ? AND cancelled_at IS NULL
+	`, event.Title, event.Description, event.EventDate, event.Location, event.Capacity, event.Recurrence, event.UpdatedAt, event.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := insertEventChanges(tx, event.ID, changes); err != nil {
+		return nil, nil, err
+	}
+	fields := make([]string, len(changes))
+	for i, change := range changes {
+		fields[i] = change.Field
+	}
+	if err := services.RecordAudit(tx, event.GroupID, actorID, services.AuditEventUpdated, "event", event.ID, strings.Join(fields, ", ")); err != nil {
+		return nil, nil, err
+	}
+
+	// Une capacité plus grande libère des places pour la liste d'attente
+	more, err := services.PromoteWaitlist(tx, event.ID)
+	if err != nil {
+		return nil, nil, err
 	}
+	return append(promoted, more...), removed, tx.Commit()
+}
+
+// updateOccurrences carries the edits of a series over to its stored
+// occurrences still scheduled, except the fields they changed on their own,
+// and moves all of them with the start of the series. The occurrences the
+// rule no longer produces are cancelled, keeping their history and answers.
+// It must run before the series is saved, and returns the users promoted from
+// the waitlists and those who answered an occurrence it cancelled.
+func updateOccurrences(tx *sql.Tx, series *models.Event, actorID int64) ([]int64, []int64, error) {
+	rule, err := services.ParseRecurrence(series.Recurrence)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var previous models.Event
+	err = tx.QueryRow(`
+		SELECT title, description, event_date, location, capacity FROM events WHERE id = ?
+	`, series.ID).Scan(&previous.Title, &previous.Description, &previous.EventDate, &previous.Location, &previous.Capacity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE events SET
+			title = CASE WHEN title = ? THEN ? ELSE title END,
+			description = CASE WHEN description IS ? THEN ? ELSE description END,
+			location = CASE WHEN location = ? THEN ? ELSE location END,
+			capacity = CASE WHEN capacity IS ? THEN ? ELSE capacity END,
+			updated_at = ?
+		WHERE series_id = ? AND cancelled_at IS NULL
+	`, previous.Title, series.Title, previous.Description, series.Description, previous.Location, series.Location,
+		previous.Capacity, series.Capacity, series.UpdatedAt, series.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Les occurrences sont déplacées en partant de la plus éloignée dans le
+	// sens du décalage, pour ne jamais prendre la date d'une autre
+	start := services.InTimeZone(series.EventDate, series.TimeZone)
+	moved := !start.Equal(previous.EventDate)
+	order := "ASC"
+	if start.After(previous.EventDate) {
+		order = "DESC"
+	}
+	rows, err := tx.Query(`
+		SELECT id, event_date, occurrence_date, cancelled_at FROM events
+		WHERE series_id = ? AND occurrence_date IS NOT NULL
+		ORDER BY occurrence_date `+order, series.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var occurrences []*models.Event
+	var dates []time.Time
+	for rows.Next() {
+		var occurrence models.Event
+		if err := rows.Scan(&occurrence.ID, &occurrence.EventDate, &occurrence.OccurrenceDate, &occurrence.CancelledAt); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		date := services.ShiftOccurrence(*occurrence.OccurrenceDate, previous.EventDate, start).UTC()
+		occurrence.OccurrenceDate = &date
+		occurrences = append(occurrences, &occurrence)
+		dates = append(dates, date)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	dropped := make(map[int64]bool)
+	for _, date := range rule.Dropped(start, dates) {
+		dropped[date.Unix()] = true
+	}
+
+	var promoted, removed []int64
+	seen := make(map[int64]bool)
+	for _, occurrence := range occurrences {
+		if moved {
+			eventDate := services.ShiftOccurrence(occurrence.EventDate, previous.EventDate, start).UTC()
+			_, err := tx.Exec(`
+				UPDATE events SET event_date = ?, occurrence_date = ? WHERE id = ?
+			`, eventDate, *occurrence.OccurrenceDate, occurrence.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if occurrence.CancelledAt != nil {
+			continue
+		}
+
+		if !dropped[occurrence.OccurrenceDate.Unix()] {
+			more, err := services.PromoteWaitlist(tx, occurrence.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			promoted = append(promoted, more...)
+			continue
+		}
 
-	return participants, nil
+		// Une date retirée de la série est annulée, pas supprimée
+		userIDs, err := respondentIDs(tx, occurrence.ID, EventGoing, EventMaybe, EventWaitlisted)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, userID := range userIDs {
+			if !seen[userID] {
+				seen[userID] = true
+				removed = append(removed, userID)
+			}
+		}
+		if err := cancelOccurrence(tx, occurrence.ID, actorID, series.UpdatedAt); err != nil {
+			return nil, nil, err
+		}
+	}
+	return promoted, removed, nil
 }
 
-func (r *EventRepository) getEventNonParticipants(eventID int64) ([]*models.EventParticipant, error) {
+// cancelOccurrence cancels a stored occurrence the rule of its series no
+// longer produces, and records it in its history.
+func cancelOccurrence(tx *sql.Tx, occurrenceID, actorID int64, now time.Time) error {
+	_, err := tx.Exec(`
+		UPDATE events SET cancelled_at = ?, cancelled_by = ?, cancel_reason = ?, updated_at = ?
+		WHERE id = ?
+	`, now, actorID, OccurrenceDroppedReason, now, occurrenceID)
+	if err != nil {
+		return err
+	}
+	change := &models.EventChange{
+		ChangedBy: &actorID,
+		Field:     "status",
+		OldValue:  "scheduled",
+		NewValue:  "cancelled",
+		Reason:    OccurrenceDroppedReason,
+		ChangedAt: now,
+	}
+	return insertEventChanges(tx, occurrenceID, []*models.EventChange{change})
+}
+
+// respondentIDs lists the users who gave one of the statuses to an event.
+func respondentIDs(tx *sql.Tx, eventID int64, statuses ...string) ([]int64, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(statuses)), ",")
+	args := []any{eventID}
+	for _, status := range statuses {
+		args = append(args, status)
+	}
+	rows, err := tx.Query(`
+		SELECT user_id FROM event_responses WHERE event_id = ? AND status IN (`+placeholders+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// CancelEvent marks an event as cancelled, with the stored occurrences of a
+// series, and records it in its history and the audit log of the group. It
+// reports whether the event was still scheduled.
+func (r *EventRepository) CancelEvent(eventID, userID int64, reason string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE events SET cancelled_at = ?, cancelled_by = ?, cancel_reason = ?, updated_at = ?
+		WHERE (id = ? OR series_id = ?) AND cancelled_at IS NULL
+	`, now, userID, reason, now, eventID, eventID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	change := &models.EventChange{
+		ChangedBy: &userID,
+		Field:     "status",
+		OldValue:  "scheduled",
+		NewValue:  "cancelled",
+		Reason:    reason,
+		ChangedAt: now,
+	}
+	if err := insertEventChanges(tx, eventID, []*models.EventChange{change}); err != nil {
+		return false, err
+	}
+
+	var groupID int64
+	if err := tx.QueryRow(`SELECT group_id FROM events WHERE id = ?`, eventID).Scan(&groupID); err != nil {
+		return false, err
+	}
+	if err := services.RecordAudit(tx, groupID, userID, services.AuditEventCancelled, "event", eventID, reason); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func insertEventChanges(tx *sql.Tx, eventID int64, changes []*models.EventChange) error {
+	for _, change := range changes {
+		_, err := tx.Exec(`
+			INSERT INTO event_changes (event_id, changed_by, field, old_value, new_value, reason, changed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, eventID, change.ChangedBy, change.Field, change.OldValue, change.NewValue, change.Reason, change.ChangedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEventChanges returns the change history of an event, oldest first.
+func (r *EventRepository) GetEventChanges(eventID int64) ([]*models.EventChange, error) {
 	rows, err := r.db.Query(`
-		SELECT u.id, u.username
-		FROM event_responses er
-		JOIN users u ON er.user_id = u.id
-		WHERE er.event_id = ? AND er.status = 'not_going'
-		ORDER BY u.username
+		SELECT c.id, c.event_id, c.changed_by, COALESCE(u.username, ''), c.field, c.old_value, c.new_value, c.reason, c.changed_at
+		FROM event_changes c
+		LEFT JOIN users u ON u.id = c.changed_by
+		WHERE c.event_id = ?
+		ORDER BY c.changed_at ASC, c.id ASC
 	`, eventID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var nonParticipants []*models.EventParticipant
+	changes := []*models.EventChange{}
 	for rows.Next() {
-		var participant models.EventParticipant
-		err := rows.Scan(&participant.UserID, &participant.Username)
+		var change models.EventChange
+		err := rows.Scan(
+			&change.ID,
+			&change.EventID,
+			&change.ChangedBy,
+			&change.Username,
+			&change.Field,
+			&change.OldValue,
+			&change.NewValue,
+			&change.Reason,
+			&change.ChangedAt,
+		)
 		if err != nil {
 			return nil, err
 		}
-		nonParticipants = append(nonParticipants, &participant)
+		changes = append(changes, &change)
 	}
+	return changes, rows.Err()
+}
 
-	// Ensure we return an empty slice instead of nil
-	if nonParticipants == nil {
-		nonParticipants = []*models.EventParticipant{}
+// GetGoingUserIDs lists the users who answered they are going to an event,
+// or to an upcoming occurrence of a series.
+func (r *EventRepository) GetGoingUserIDs(eventID int64) ([]int64, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT user_id FROM event_responses
+		WHERE status = 'going' AND event_id IN (
+			SELECT id FROM events WHERE id = ? OR (series_id = ? AND event_date >= ?)
+		)
+	`, eventID, eventID, time.Now().UTC())
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nonParticipants, nil
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
 }
 
-func (r *EventRepository) DeleteEvent(eventID int64) error {
+// FindOccurrence loads the stored occurrence of a series planned at date.
+func (r *EventRepository) FindOccurrence(seriesID int64, date time.Time) (*models.Event, error) {
+	var id int64
+	err := r.db.QueryRow(`
+		SELECT id FROM events WHERE series_id = ? AND occurrence_date = ?
+	`, seriesID, date.UTC()).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// GetOccurrence loads the occurrence of a series planned at date, storing it
+// from the series first if needed, so that it can have its own answers,
+// edits and cancellation. The date must be one of the series.
+func (r *EventRepository) GetOccurrence(seriesID int64, date time.Time) (*models.Event, error) {
+	date = date.UTC()
+	now := time.Now()
 	_, err := r.db.Exec(`
-		DELETE FROM events
-		WHERE id = ?
-	`, eventID)
-	return err
+		INSERT OR IGNORE INTO events (group_id, creator_id, title, description, event_date, location, capacity,
+			created_at, updated_at, cancelled_at, cancelled_by, cancel_reason, series_id, occurrence_date, time_zone)
+		SELECT group_id, creator_id, title, description, ?, location, capacity,
+			?, ?, cancelled_at, cancelled_by, cancel_reason, id, ?, time_zone
+		FROM events WHERE id = ? AND series_id IS NULL AND recurrence != ''
+	`, date, now, now, date, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	return r.FindOccurrence(seriesID, date)
 }
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"social-network/backend/database/models"
+	"social-network/backend/database/sqlite"
+)
+
+// newTestDB returns a migrated database in a temporary directory.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"), "../migrations/sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// mustExec runs the statements of a seed, failing the test on error.
+func mustExec(t *testing.T, db *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", strings.TrimSpace(query), err)
+	}
+}
+
+// TestSeriesKeepLocalTimeAcrossDST stores a series through the repository
+// and checks the occurrences expanded from it once loaded back, where its
+// start only carries a fixed offset.
+func TestSeriesKeepLocalTimeAcrossDST(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	db := newTestDB(t)
+	mustExec(t, db, `
+		INSERT INTO users (id, email, password_hash, first_name, last_name, birth_date, username)
+		VALUES (1, 'owner@example.com', ?, 'owner', 'Test', '2000-01-01', 'owner')
+	`, strings.Repeat("x", 60))
+	mustExec(t, db, `INSERT INTO groups (id, creator_id, creator_name, title) VALUES (1, 1, 'owner', 'club')`)
+	repo := NewEventRepository(db)
+
+	// Le passage à l'heure d'été a lieu le 29 mars 2026
+	description := ""
+	start := time.Date(2026, time.March, 16, 19, 0, 0, 0, paris)
+	series := &models.Event{
+		GroupID:     1,
+		CreatorID:   1,
+		Title:       "run",
+		Description: &description,
+		EventDate:   start,
+		Recurrence:  "FREQ=WEEKLY;BYDAY=MO;COUNT=4",
+		TimeZone:    "Europe/Paris",
+		CreatedAt:   start,
+		UpdatedAt:   start,
+	}
+	if series.ID, err = repo.CreateEvent(series); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := repo.GetEventsWithResponsesByGroupID(1, 1, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("got %d occurrences, want 4", len(events))
+	}
+	for _, event := range events {
+		if local := event.EventDate.In(paris); local.Hour() != 19 || local.Weekday() != time.Monday {
+			t.Errorf("occurrence %v is not on Monday at 19:00 in Paris", local)
+		}
+	}
+
+	// Une occurrence enregistrée suit la série déplacée au mardi
+	last := events[3].EventDate
+	occurrence, err := repo.GetOccurrence(series.ID, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	series, err = repo.GetByID(series.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	series.EventDate = series.EventDate.AddDate(0, 0, 1)
+	series.Recurrence = "FREQ=WEEKLY;BYDAY=TU;COUNT=4"
+	if _, _, err := repo.UpdateEvent(series, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if occurrence, err = repo.GetByID(occurrence.ID); err != nil {
+		t.Fatal(err)
+	}
+	if occurrence.CancelledAt != nil {
+		t.Errorf("moved occurrence was cancelled")
+	}
+	if local := occurrence.EventDate.In(paris); local.Hour() != 19 || local.Weekday() != time.Tuesday {
+		t.Errorf("moved occurrence %v is not on Tuesday at 19:00 in Paris", local)
+	}
+}
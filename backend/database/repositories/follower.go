@@ -224,3 +224,107 @@ func (r *FollowerRepository) GetFriends(userID int64) ([]*FollowerInfo, error) {
 
     return friends, nil
 }
+
+// GetIncomingRequests returns a page of the pending requests received by a
+// user, most recent first
+func (r *FollowerRepository) GetIncomingRequests(userID int64, limit, offset int) (*models.FollowRequestPage, error) {
+	return r.getRequests(`f.followed_id = ?`, `f.follower_id`, userID, limit, offset)
+}
+
+// GetOutgoingRequests returns a page of the pending requests sent by a user,
+// most recent first
+func (r *FollowerRepository) GetOutgoingRequests(userID int64, limit, offset int) (*models.FollowRequestPage, error) {
+	return r.getRequests(`f.follower_id = ?`, `f.followed_id`, userID, limit, offset)
+}
+
+// getRequests lists the pending requests matching condition, otherColumn
+// being the user shown for each request
+func (r *FollowerRepository) getRequests(condition, otherColumn string, userID int64, limit, offset int) (*models.FollowRequestPage, error) {
+	page := &models.FollowRequestPage{Requests: []*models.FollowRequest{}}
+
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM followers f WHERE `+condition+` AND f.accepted = 0
+	`, userID).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT u.id, u.username, COALESCE(u.avatar_path, ''), f.followed_at
+		FROM followers f
+		JOIN users u ON u.id = `+otherColumn+`
+		WHERE `+condition+` AND f.accepted = 0
+		ORDER BY f.followed_at DESC, u.id DESC
+		LIMIT ? OFFSET ?
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		request := &models.FollowRequest{}
+		if err := rows.Scan(&request.UserID, &request.Username, &request.AvatarPath, &request.RequestedAt); err != nil {
+			return nil, err
+		}
+		page.Requests = append(page.Requests, request)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page.HasMore = int64(offset+len(page.Requests)) < page.Total
+	return page, nil
+}
+
+// ResolvePendingBatch accepts or declines the pending requests of several
+// followers in one transaction, removing their follow request notifications.
+// It returns the followers whose request was resolved and the ones without a
+// pending request; nothing is written when an error occurs.
+func (r *FollowerRepository) ResolvePendingBatch(followedID int64, followerIDs []int64, accept bool) ([]int64, []int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM followers WHERE follower_id = ? AND followed_id = ? AND accepted = 0`
+	if accept {
+		query = `UPDATE followers SET accepted = 1 WHERE follower_id = ? AND followed_id = ? AND accepted = 0`
+	}
+
+	processed, notFound := []int64{}, []int64{}
+	for _, followerID := range followerIDs {
+		result, err := tx.Exec(query, followerID, followedID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return nil, nil, err
+		} else if n == 0 {
+			notFound = append(notFound, followerID)
+			continue
+		}
+
+		if _, err := tx.Exec(`
+			DELETE FROM notifications WHERE user_id = ? AND reference_id = ? AND type = 'follow_request'
+		`, followedID, followerID); err != nil {
+			return nil, nil, err
+		}
+		processed = append(processed, followerID)
+	}
+
+	return processed, notFound, tx.Commit()
+}
+
+// DeletePending removes a pending request and reports whether there was one
+func (r *FollowerRepository) DeletePending(followerID, followedID int64) (bool, error) {
+	result, err := r.db.Exec(`
+		DELETE FROM followers WHERE follower_id = ? AND followed_id = ? AND accepted = 0
+	`, followerID, followedID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
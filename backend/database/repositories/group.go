@@ -2,9 +2,8 @@ package repository
 
 import (
 	"database/sql"
-	"fmt"
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
-	"strconv"
 	"time"
 )
 
@@ -22,8 +21,8 @@ func NewGroupRepository(db *sql.DB) *GroupRepository {
 func (r *GroupRepository) Create(group *models.Group) (int64, error) {
 	stmt, err := r.db.Prepare(`
 		INSERT INTO groups(
-			creator_id, creator_name, title, description, created_at, updated_at
-		) VALUES(?, ?, ?, ?, ?, ?)
+			creator_id, creator_name, title, description, visibility, created_at, updated_at
+		) VALUES(?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return 0, err
@@ -35,6 +34,7 @@ func (r *GroupRepository) Create(group *models.Group) (int64, error) {
 		group.CreatorName,
 		group.Title,
 		group.Description,
+		group.Visibility,
 		group.CreatedAt,
 		group.UpdatedAt,
 	)
@@ -66,18 +66,19 @@ func (r *GroupRepository) AddMember(groupID, userID int64, Username string, acce
 	return err
 }
 
+// CreateGroupInvitation invites a user to a group and records it in the audit
+// log of the group, in one transaction.
 func (r *GroupRepository) CreateGroupInvitation(groupID, inviterID, inviteeID int64) (*models.Group, error) {
-	stmt, err := r.db.Prepare(`
-		INSERT INTO group_invitations (group_id, inviter_id, invitee_id, create_at)
-		VALUES (?, ?, ?, ?)
-	`)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	createdAt := time.Now()
-	result, err := stmt.Exec(groupID, inviterID, inviteeID, createdAt)
+	result, err := tx.Exec(`
+		INSERT INTO group_invitations (group_id, inviter_id, invitee_id, create_at)
+		VALUES (?, ?, ?, ?)
+	`, groupID, inviterID, inviteeID, time.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +87,12 @@ func (r *GroupRepository) CreateGroupInvitation(groupID, inviterID, inviteeID in
 	if err != nil {
 		return nil, err
 	}
+	if err := services.RecordAudit(tx, groupID, inviterID, services.AuditMemberInvited, "user", inviteeID, ""); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	group := &models.Group{
 		ID:        id,
@@ -110,10 +117,11 @@ func (r *GroupRepository) DeleteInvitation(userID, groupID int64) error {
 
 func (r *GroupRepository) GetGroupsByUserID(userID int64) ([]models.Group, error) {
 	stmt, err := r.db.Prepare(`
-		SELECT g.id, g.creator_id, g.creator_name, g.title, g.description, g.created_at, g.updated_at
+		SELECT g.id, g.creator_id, g.creator_name, g.title, g.description, g.visibility,
+			COALESCE(g.category, ''), COALESCE(g.cover_path, ''), g.created_at, g.updated_at
 		FROM groups g
 		JOIN group_members gm ON g.id = gm.group_id
-		WHERE gm.user_id = ?
+		WHERE gm.user_id = ? AND g.deleted_at IS NULL
 	`)
 	if err != nil {
 		return nil, err
@@ -129,7 +137,7 @@ func (r *GroupRepository) GetGroupsByUserID(userID int64) ([]models.Group, error
 	var groups []models.Group
 	for rows.Next() {
 		var group models.Group
-		if err := rows.Scan(&group.ID, &group.CreatorID, &group.CreatorName, &group.Title, &group.Description, &group.CreatedAt, &group.UpdatedAt); err != nil {
+		if err := rows.Scan(&group.ID, &group.CreatorID, &group.CreatorName, &group.Title, &group.Description, &group.Visibility, &group.Category, &group.CoverPath, &group.CreatedAt, &group.UpdatedAt); err != nil {
 			return nil, err
 		}
 		groups = append(groups, group)
@@ -140,9 +148,10 @@ func (r *GroupRepository) GetGroupsByUserID(userID int64) ([]models.Group, error
 
 func (r *GroupRepository) GetGroupByID(groupID int64) (*models.Group, error) {
 	stmt, err := r.db.Prepare(`
-		SELECT id, creator_id, creator_name, title, description, created_at, updated_at
+		SELECT id, creator_id, creator_name, title, description, visibility,
+			COALESCE(category, ''), COALESCE(cover_path, ''), archived_at, created_at, updated_at
 		FROM groups
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`)
 	if err != nil {
 		return nil, err
@@ -150,13 +159,17 @@ func (r *GroupRepository) GetGroupByID(groupID int64) (*models.Group, error) {
 	defer stmt.Close()
 
 	var group models.Group
-	err = stmt.QueryRow(groupID).Scan(&group.ID, &group.CreatorID, &group.CreatorName, &group.Title, &group.Description, &group.CreatedAt, &group.UpdatedAt)
+	var archivedAt sql.NullTime
+	err = stmt.QueryRow(groupID).Scan(&group.ID, &group.CreatorID, &group.CreatorName, &group.Title, &group.Description, &group.Visibility, &group.Category, &group.CoverPath, &archivedAt, &group.CreatedAt, &group.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
+	if archivedAt.Valid {
+		group.ArchivedAt = &archivedAt.Time
+	}
 
 	return &group, nil
 
@@ -164,7 +177,7 @@ func (r *GroupRepository) GetGroupByID(groupID int64) (*models.Group, error) {
 
 func (r *GroupRepository) GetMembersByGroupID(groupID int64) ([]models.GroupMember, error) {
 	stmt, err := r.db.Prepare(`
-		SELECT gm.id, gm.group_id, gm.user_id, gm.username, gm.accepted, gm.created_at
+		SELECT gm.id, gm.group_id, gm.user_id, gm.username, gm.accepted, gm.role, gm.created_at
 		FROM group_members gm
 		JOIN users u ON gm.user_id = u.id
 		WHERE gm.group_id = ?
@@ -183,7 +196,7 @@ func (r *GroupRepository) GetMembersByGroupID(groupID int64) ([]models.GroupMemb
 	var members []models.GroupMember
 	for rows.Next() {
 		var member models.GroupMember
-		if err := rows.Scan(&member.ID, &member.GroupID, &member.UserID, &member.Username, &member.Accepted, &member.CreatedAt); err != nil {
+		if err := rows.Scan(&member.ID, &member.GroupID, &member.UserID, &member.Username, &member.Accepted, &member.Role, &member.CreatedAt); err != nil {
 			return nil, err
 		}
 		members = append(members, member)
@@ -222,10 +235,11 @@ func (r *GroupRepository) CreateGroupMessage(groupMessage *models.GroupMessage)
 
 func (r *GroupRepository) GetMessagesByGroupID(groupID int64) ([]models.GroupMessage, error) {
 	rows, err := r.db.Query(`
-		SELECT id, group_id, user_id, username, content, created_at, updated_at
-		FROM group_messages
-		WHERE group_id = ?
-		ORDER BY created_at ASC
+		SELECT gm.id, gm.group_id, gm.user_id, gm.username, gm.content, gm.created_at, gm.updated_at
+		FROM group_messages gm
+		JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = ? AND gm.is_hidden = 0 AND `+services.ActiveUserCondition+`
+		ORDER BY gm.created_at ASC
 	`, groupID)
 	if err != nil {
 		return nil, err
@@ -244,75 +258,11 @@ func (r *GroupRepository) GetMessagesByGroupID(groupID int64) ([]models.GroupMes
 	return messages, nil
 }
 
-func (r *GroupRepository) CreateGroupPost(groupPost *models.GroupPost) (int64, error) {
-	stmt, err := r.db.Prepare(`
-		INSERT INTO group_posts (group_id, user_id, username, content, image_path, created_at, updated_at, comments_count)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`)
-	if err != nil {
-		return 0, err
-	}
-	defer stmt.Close()
-
-	result, err := stmt.Exec(
-		groupPost.GroupID,
-		groupPost.UserID,
-		groupPost.Username,
-		groupPost.Content,
-		groupPost.ImagePath,
-		groupPost.CreatedAt,
-		groupPost.UpdatedAt,
-		groupPost.CommentsCount,
-	)
-	if err != nil {
-		return 0, err
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-
-	groupPost.ID = id
-	return id, nil
-}
-
-func (r *GroupRepository) GetPostsByGroupID(groupID int64) ([]models.GroupPost, error) {
-	stmt, err := r.db.Prepare(`
-		SELECT gp.id, gp.group_id, gp.user_id, gp.username, gp.content, gp.image_path, gp.created_at, gp.updated_at, gp.comments_count
-		FROM group_posts gp
-		JOIN users u ON gp.user_id = u.id
-		WHERE gp.group_id = ?
-		ORDER BY gp.created_at DESC
-	`)
-	if err != nil {
-		return nil, err
-	}
-	defer stmt.Close()
-
-	rows, err := stmt.Query(groupID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var posts []models.GroupPost
-	for rows.Next() {
-		var post models.GroupPost
-		if err := rows.Scan(&post.ID, &post.GroupID, &post.UserID, &post.Username, &post.Content, &post.ImagePath, &post.CreatedAt, &post.UpdatedAt, &post.CommentsCount); err != nil {
-			return nil, err
-		}
-		posts = append(posts, post)
-	}
-
-	return posts, nil
-}
-
 func (r *GroupRepository) GetGroupInfos(group_id int64) (int64, string, error) {
 	stmt, err := r.db.Prepare(`
 		SELECT creator_id, title 
 		FROM groups 
-		WHERE id = ?;
+		WHERE id = ? AND deleted_at IS NULL;
 	`)
 	if err != nil {
 		return 0, "", err
@@ -328,114 +278,185 @@ func (r *GroupRepository) GetGroupInfos(group_id int64) (int64, string, error) {
 	return id, title, nil
 }
 
-func (r *GroupRepository) CreateGroupComment(comment *models.GroupComment) (int64, error) {
+// ismember checks if a user is a member of a group
+func (r *GroupRepository) IsMember(groupID, userID int64) (bool, error) {
 	stmt, err := r.db.Prepare(`
-		INSERT INTO group_comments (group_post_id, user_id, username, content, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		SELECT COUNT(*)
+		FROM group_members
+		WHERE group_id = ? AND user_id = ?
 	`)
 	if err != nil {
-		return 0, err
+		return false, err
 	}
 	defer stmt.Close()
 
-	result, err := stmt.Exec(
-		comment.GroupPostID,
-		comment.UserID,
-		comment.Username,
-		comment.Content,
-		comment.CreatedAt,
-		comment.UpdatedAt,
-	)
+	var count int
+	err = stmt.QueryRow(groupID, userID).Scan(&count)
 	if err != nil {
-		return 0, err
+		return false, err
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-	comment.ID = id
+	return count > 0, nil
+}
 
-	_, err = r.db.Exec(`
-		UPDATE group_posts
-		SET comments_count = comments_count + 1
-		WHERE id = ?
-	`, comment.GroupPostID)
+// IsRequestPending returns the ID of the pending join request of a user, or 0
+func (r *GroupRepository) IsRequestPending(groupID, userID int64) int64 {
+	var id int64
+	err := r.db.QueryRow(`
+		SELECT id FROM group_join_requests WHERE group_id = ? AND requester_id = ? AND status = 'pending'
+	`, groupID, userID).Scan(&id)
 	if err != nil {
-		return id, fmt.Errorf("failed to update comments_count: %w", err)
+		return 0
 	}
+	return id
+}
 
-	return id, nil
+// HasInvitation checks if a user has been invited to a group
+func (r *GroupRepository) HasInvitation(userID, groupID int64) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM group_invitations WHERE invitee_id = ? AND group_id = ?
+	`, userID, groupID).Scan(&count)
+	return count > 0, err
 }
 
-func (r *GroupRepository) GetCommentsByPostID(postID int64) ([]models.GroupComment, error) {
-	stmt, err := r.db.Prepare(`
-		SELECT gc.id, gc.group_post_id, gc.user_id, gc.username, gc.content, gc.created_at, gc.updated_at
-		FROM group_comments gc
-		JOIN users u ON gc.user_id = u.id
-		WHERE gc.group_post_id = ?
-		ORDER BY gc.created_at ASC
-	`)
+const joinRequestColumns = `
+	SELECT jr.id, jr.group_id, jr.requester_id, u.username, COALESCE(u.avatar_path, ''),
+		jr.message, jr.status, jr.decided_by, jr.decided_at, jr.created_at
+	FROM group_join_requests jr
+	JOIN users u ON u.id = jr.requester_id
+`
+
+func scanJoinRequest(scanner interface{ Scan(...any) error }) (*models.GroupJoinRequest, error) {
+	request := &models.GroupJoinRequest{}
+	var decidedBy sql.NullInt64
+	var decidedAt sql.NullTime
+	err := scanner.Scan(
+		&request.ID,
+		&request.GroupID,
+		&request.RequesterID,
+		&request.Username,
+		&request.AvatarPath,
+		&request.Message,
+		&request.Status,
+		&decidedBy,
+		&decidedAt,
+		&request.CreatedAt,
+	)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
+	if decidedBy.Valid {
+		request.DecidedBy = &decidedBy.Int64
+	}
+	if decidedAt.Valid {
+		request.DecidedAt = &decidedAt.Time
+	}
+	return request, nil
+}
+
+// GetJoinRequest retrieves a join request by its ID
+func (r *GroupRepository) GetJoinRequest(id int64) (*models.GroupJoinRequest, error) {
+	return scanJoinRequest(r.db.QueryRow(joinRequestColumns+` WHERE jr.id = ?`, id))
+}
 
-	rows, err := stmt.Query(postID)
+// GetPendingJoinRequests lists the pending join requests of a group, oldest first
+func (r *GroupRepository) GetPendingJoinRequests(groupID int64) ([]*models.GroupJoinRequest, error) {
+	rows, err := r.db.Query(joinRequestColumns+`
+		WHERE jr.group_id = ? AND jr.status = 'pending'
+		ORDER BY jr.created_at, jr.id
+	`, groupID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var comments []models.GroupComment
+	requests := []*models.GroupJoinRequest{}
 	for rows.Next() {
-		var comment models.GroupComment
-		if err := rows.Scan(&comment.ID, &comment.GroupPostID, &comment.UserID, &comment.Username, &comment.Content, &comment.CreatedAt, &comment.UpdatedAt); err != nil {
+		request, err := scanJoinRequest(rows)
+		if err != nil {
 			return nil, err
 		}
-		comments = append(comments, comment)
+		requests = append(requests, request)
 	}
-
-	return comments, nil
+	return requests, rows.Err()
 }
 
-// ismember checks if a user is a member of a group
-func (r *GroupRepository) IsMember(groupID, userID int64) (bool, error) {
-	stmt, err := r.db.Prepare(`
-		SELECT COUNT(*)
-		FROM group_members
-		WHERE group_id = ? AND user_id = ?
-	`)
+// ApproveJoinRequest approves a pending request and adds the requester to the
+// group, and reports whether the request was still pending
+func (r *GroupRepository) ApproveJoinRequest(request *models.GroupJoinRequest, deciderID int64) (bool, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return false, err
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	var count int
-	err = stmt.QueryRow(groupID, userID).Scan(&count)
+	result, err := tx.Exec(`
+		UPDATE group_join_requests SET status = 'approved', decided_by = ?, decided_at = ?
+		WHERE id = ? AND status = 'pending'
+	`, deciderID, time.Now(), request.ID)
 	if err != nil {
 		return false, err
 	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
 
-	return count > 0, nil
+	if _, err := tx.Exec(`
+		INSERT INTO group_members (group_id, user_id, username, accepted, created_at)
+		SELECT ?, ?, ?, 1, ?
+		WHERE NOT EXISTS (SELECT 1 FROM group_members WHERE group_id = ? AND user_id = ?)
+	`, request.GroupID, request.RequesterID, request.Username, time.Now(), request.GroupID, request.RequesterID); err != nil {
+		return false, err
+	}
+
+	// Les requérants approuvés par eux-mêmes ont rejoint un groupe public
+	details := ""
+	if deciderID == request.RequesterID {
+		details = "public group"
+	}
+	if err := services.RecordAudit(tx, request.GroupID, deciderID, services.AuditJoinApproved, "user", request.RequesterID, details); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
 }
 
-func (r *GroupRepository) IsRequestPending(groupID, userID int64) int64 {
-	stmt, err := r.db.Prepare(`
-		SELECT id
-		FROM notifications
-		WHERE reference_id = ? AND reference_type = ? AND content LIKE ? ; 
-	`)
+// RejectJoinRequest rejects a pending request, recording it in the audit log
+// of the group, and reports whether there was one
+func (r *GroupRepository) RejectJoinRequest(request *models.GroupJoinRequest, deciderID int64) (bool, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return 0
+		return false, err
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	var count int64
-	err = stmt.QueryRow(groupID, "group", "%;"+strconv.Itoa(int(userID))).Scan(&count)
+	result, err := tx.Exec(`
+		UPDATE group_join_requests SET status = 'rejected', decided_by = ?, decided_at = ?
+		WHERE id = ? AND status = 'pending'
+	`, deciderID, time.Now(), request.ID)
 	if err != nil {
-		return 0
+		return false, err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
 	}
 
-	return count
+	if err := services.RecordAudit(tx, request.GroupID, deciderID, services.AuditJoinRejected, "user", request.RequesterID, ""); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// WithdrawJoinRequest withdraws a pending request of the requester and reports
+// whether there was one
+func (r *GroupRepository) WithdrawJoinRequest(id, requesterID int64) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE group_join_requests SET status = 'withdrawn'
+		WHERE id = ? AND requester_id = ? AND status = 'pending'
+	`, id, requesterID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
 }
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"time"
 
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 )
 
@@ -79,10 +80,12 @@ func (r *MessageRepository) GetByID(id int64) (*models.Message, error) {
 // Get messages between two users (deprecated - use GetMessagesByConversation instead)
 func (r *MessageRepository) GetMessagesBetweenUsers(user1ID, user2ID int64) ([]*models.Message, error) {
 	stmt, err := r.db.Prepare(`
-		SELECT id, conversation_id, sender_id, receiver_id, group_id, content, created_at, read_at
-		FROM messages
-		WHERE (sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?)
-		ORDER BY created_at ASC
+		SELECT m.id, m.conversation_id, m.sender_id, m.receiver_id, m.group_id, m.content, m.created_at, m.read_at
+		FROM messages m
+		JOIN users u ON u.id = m.sender_id
+		WHERE ((m.sender_id = ? AND m.receiver_id = ?) OR (m.sender_id = ? AND m.receiver_id = ?))
+			AND m.is_hidden = 0 AND ` + services.ActiveUserCondition + `
+		ORDER BY m.created_at ASC
 	`)
 	if err != nil {
 		return nil, err
@@ -123,10 +126,11 @@ func (r *MessageRepository) GetMessagesBetweenUsers(user1ID, user2ID int64) ([]*
 
 func (r *MessageRepository) GetMessagesByConversationID(conversationID int64) ([]*models.Message, error) {
 	stmt, err := r.db.Prepare(`
-		SELECT id, conversation_id, sender_id, receiver_id, group_id, content, created_at, read_at
-		FROM messages
-		WHERE conversation_id = ?
-		ORDER BY created_at ASC
+		SELECT m.id, m.conversation_id, m.sender_id, m.receiver_id, m.group_id, m.content, m.created_at, m.read_at
+		FROM messages m
+		JOIN users u ON u.id = m.sender_id
+		WHERE m.conversation_id = ? AND m.is_hidden = 0 AND ` + services.ActiveUserCondition + `
+		ORDER BY m.created_at ASC
 	`)
 	if err != nil {
 		return nil, err
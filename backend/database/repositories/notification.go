@@ -2,7 +2,6 @@ package repository
 
 import (
 	"database/sql"
-	"strconv"
 
 	"social-network/backend/database/models"
 )
@@ -229,22 +228,18 @@ func (r *NotificationRepository) DeleteGroupInvitationRequest(userID, groupID in
 		return err
 	}
 
-	stmt2, err := r.db.Prepare(`
-		DELETE FROM notifications WHERE reference_id = ? AND content LIKE ? AND type = 'group_request'
-	`)
-	if err != nil {
-		return err
-	}
-	defer stmt2.Close()
-	checker := "%;" + strconv.Itoa(int(userID))
-	_, err = stmt2.Exec(groupID, checker)
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
 
+// DeleteByReference removes the notifications of a type about the same object,
+// whoever received them
+func (r *NotificationRepository) DeleteByReference(notifType, referenceType string, referenceID int64) error {
+	_, err := r.db.Exec(`
+		DELETE FROM notifications WHERE type = ? AND reference_type = ? AND reference_id = ?
+	`, notifType, referenceType, referenceID)
+	return err
+}
+
 func (r *NotificationRepository) CreateNotification(
 	userID int64,
 	notifType string,
@@ -24,8 +24,8 @@ func NewPostRepository(db *sql.DB) *PostRepository {
 func (r *PostRepository) Create(post *models.Post) (int64, error) {
 	stmt, err := r.db.Prepare(`
 	INSERT INTO posts(
-		user_id, content, image_path, privacy_type, created_at, updated_at)
-		VALUES(?, ?, ?, ?, ?, ?)
+		user_id, content, image_path, privacy_type, created_at, updated_at, group_id)
+		VALUES(?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return 0, err
@@ -39,6 +39,7 @@ func (r *PostRepository) Create(post *models.Post) (int64, error) {
 		post.PrivacyType,
 		post.CreatedAt,
 		post.UpdatedAt,
+		post.GroupID,
 	)
 	if err != nil {
 		return 0, err
@@ -56,7 +57,7 @@ func (r *PostRepository) Create(post *models.Post) (int64, error) {
 // Get a post by ID
 func (r *PostRepository) GetByID(id int64, ps *services.PostService, curr_user *models.User) (map[string]any, error) {
 	stmt, err := r.db.Prepare(`
-		SELECT id, user_id, content, image_path, privacy_type, created_at, updated_at
+		SELECT id, user_id, content, image_path, privacy_type, created_at, updated_at, group_id, pinned_at, is_announcement
 		FROM posts WHERE id = ?
 	`)
 	if err != nil {
@@ -73,10 +74,17 @@ func (r *PostRepository) GetByID(id int64, ps *services.PostService, curr_user *
 		&post.PrivacyType,
 		&post.CreatedAt,
 		&post.UpdatedAt,
+		&post.GroupID,
+		&post.PinnedAt,
+		&post.IsAnnouncement,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if !ps.CanView(post, curr_user.ID) {
+		return nil, services.ErrPostNotVisible
+	}
+	ps.AttachLinkPreviews(post)
 	user, _ := ps.GetPostAuthor(post)
 	likes, _ := ps.GetLikes(post.ID)
 	return map[string]any{
@@ -87,9 +95,24 @@ func (r *PostRepository) GetByID(id int64, ps *services.PostService, curr_user *
 	}, nil
 }
 
+// GetPosts returns the main feed. Group posts are only listed in their group
+// and the authors muted by the user are skipped.
 func (r *PostRepository) GetPosts(ps *services.PostService, curr_user *models.User) ([]map[string]any, error) {
+	return r.listPosts(ps, curr_user, `p.group_id IS NULL AND `+services.NotMutedCondition("p.user_id"), curr_user.ID)
+}
+
+// GetGroupPosts returns the posts of a group, visible to its members only.
+// Pinned posts come first, the last pinned on top.
+func (r *PostRepository) GetGroupPosts(group_id int64, ps *services.PostService, curr_user *models.User) ([]map[string]any, error) {
+	return r.listPosts(ps, curr_user, `p.group_id = ?`, group_id)
+}
+
+func (r *PostRepository) listPosts(ps *services.PostService, curr_user *models.User, condition string, args ...any) ([]map[string]any, error) {
 	var posts []map[string]any
 
+	// Le filtrage de confidentialité est fait par la requête
+	visible, visibleArgs := services.VisiblePostCondition(curr_user.ID)
+
 	stmt, err := r.db.Prepare(`
 SELECT
     p.id,
@@ -99,34 +122,25 @@ SELECT
     p.privacy_type,
     p.created_at,
     p.updated_at,
+    p.group_id,
+    p.pinned_at,
+    p.is_announcement,
+    (SELECT COUNT(*) FROM announcement_acknowledgements aa WHERE aa.post_id = p.id),
+    EXISTS (SELECT 1 FROM announcement_acknowledgements aa WHERE aa.post_id = p.id AND aa.user_id = ?),
     u.username,
     u.avatar_path
 FROM posts p
 JOIN users u ON u.id = p.user_id
-LEFT JOIN followers f1 ON f1.follower_id = ? 
-                      AND f1.followed_id = p.user_id 
-                      AND f1.accepted = 1
-LEFT JOIN followers f2 ON f2.follower_id = p.user_id 
-                      AND f2.followed_id = ? 
-                      AND f2.accepted = 1
-WHERE
-    p.user_id = ? -- l'auteur voit toujours ses propres posts
-    OR p.privacy_type = 0
-    OR (p.privacy_type = 1 AND f1.follower_id IS NOT NULL AND f2.follower_id IS NOT NULL)
-    OR EXISTS (
-        SELECT 1
-        FROM post_privacy pp
-        WHERE pp.post_id = p.id AND pp.user_id = ?
-    )
-ORDER BY p.created_at DESC;
+WHERE ` + visible + ` AND ` + condition + `
+ORDER BY p.pinned_at IS NULL, p.pinned_at DESC, p.created_at DESC;
 `)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	// Paramètres : curr_user.ID utilisé 4 fois (f1, f2, auteur, post_privacy)
-	results, err := stmt.Query(curr_user.ID, curr_user.ID, curr_user.ID, curr_user.ID)
+	queryArgs := append([]any{curr_user.ID}, visibleArgs...)
+	results, err := stmt.Query(append(queryArgs, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -135,6 +149,8 @@ ORDER BY p.created_at DESC;
 	for results.Next() {
 		post := &models.Post{}
 		var username, avatarPath string
+		var acknowledgements int64
+		var acknowledged bool
 
 		err = results.Scan(
 			&post.ID,
@@ -144,6 +160,11 @@ ORDER BY p.created_at DESC;
 			&post.PrivacyType,
 			&post.CreatedAt,
 			&post.UpdatedAt,
+			&post.GroupID,
+			&post.PinnedAt,
+			&post.IsAnnouncement,
+			&acknowledgements,
+			&acknowledged,
 			&username,
 			&avatarPath,
 		)
@@ -160,13 +181,19 @@ ORDER BY p.created_at DESC;
 			return nil, err
 		}
 		post.CommentsCount = commentCount
+		ps.AttachLinkPreviews(post)
 
-		posts = append(posts, map[string]any{
+		entry := map[string]any{
 			"post":       post,
 			"user":       map[string]any{"username": username, "avatar_path": avatarPath},
 			"like":       len(likes),
 			"user_liked": slices.Contains(likes, curr_user.Username),
-		})
+		}
+		if post.IsAnnouncement {
+			entry["acknowledgements"] = acknowledgements
+			entry["acknowledged"] = acknowledged
+		}
+		posts = append(posts, entry)
 	}
 
 	if err = results.Err(); err != nil {
@@ -293,10 +320,11 @@ func (r *PostRepository) Delete(id int64) error {
 }
 
 func (r *PostRepository) GetPostsFromUserByID(id int64, curr_user int64, ps *services.PostService) ([]*models.Post, error) {
+	visible, visibleArgs := services.VisiblePostCondition(curr_user)
 	rows, err := r.db.Query(`
-		SELECT id, user_id, content, image_path, privacy_type, created_at, updated_at
-		FROM posts WHERE user_id = ?
-	`, id)
+		SELECT p.id, p.user_id, p.content, p.image_path, p.privacy_type, p.created_at, p.updated_at, p.group_id
+		FROM posts p WHERE p.user_id = ? AND p.group_id IS NULL AND `+visible,
+		append([]any{id}, visibleArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -313,21 +341,12 @@ func (r *PostRepository) GetPostsFromUserByID(id int64, curr_user int64, ps *ser
 			&post.PrivacyType,
 			&post.CreatedAt,
 			&post.UpdatedAt,
+			&post.GroupID,
 		); err != nil {
 			return nil, err
 		}
-		isPrivate := post.UserID != curr_user && post.PrivacyType != 0
-		if isPrivate {
-			switch post.PrivacyType {
-			case 1:
-				isPrivate = !ps.IsAuthorFriend(post.UserID, curr_user)
-			case 2:
-				isPrivate = !ps.CheckPrivacy(post.ID, curr_user)
-			}
-		}
-		if !isPrivate {
-			posts = append(posts, post)
-		}
+		ps.AttachLinkPreviews(post)
+		posts = append(posts, post)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -369,7 +388,7 @@ func (r *PostRepository) GetLikedPostsByUserId(userID int64) ([]int64, error) {
 
 func (r *PostRepository) GetPostById(postID int64) (*models.Post, error) {
 	query := `
-		SELECT id, user_id, content, image_path, privacy_type, created_at, updated_at
+		SELECT id, user_id, content, image_path, privacy_type, created_at, updated_at, group_id, pinned_at, is_announcement
 		FROM posts
 		WHERE id = ?
 	`
@@ -386,6 +405,9 @@ func (r *PostRepository) GetPostById(postID int64) (*models.Post, error) {
 		&post.PrivacyType,
 		&post.CreatedAt,
 		&post.UpdatedAt,
+		&post.GroupID,
+		&post.PinnedAt,
+		&post.IsAnnouncement,
 	)
 
 	if err != nil {
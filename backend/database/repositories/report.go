@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"social-network/backend/database/models"
+)
+
+// ErrAlreadyReported is returned when a user reports the same target twice.
+var ErrAlreadyReported = errors.New("already reported")
+
+// Connection to the database
+type ReportRepository struct {
+	db *sql.DB
+}
+
+// New Constructor for ReportRepository
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// Create a new report in the database
+func (r *ReportRepository) Create(report *models.Report) (int64, error) {
+	stmt, err := r.db.Prepare(`
+		INSERT INTO reports (
+			reporter_id, target_type, target_id, reason, details, status, created_at
+		) VALUES (?, ?, ?, ?, ?, 'pending', ?)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(
+		report.ReporterID,
+		report.TargetType,
+		report.TargetID,
+		report.Reason,
+		report.Details,
+		report.CreatedAt,
+	)
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return 0, ErrAlreadyReported
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	report.ID = id
+	report.Status = "pending"
+	return id, nil
+}
+
+// Get a report by ID
+func (r *ReportRepository) GetByID(id int64) (*models.Report, error) {
+	stmt, err := r.db.Prepare(`
+		SELECT r.id, COALESCE(r.reporter_id, 0), r.target_type, r.target_id, r.reason, r.details, r.status,
+			r.resolved_by, r.resolution, r.created_at, r.resolved_at, COALESCE(u.username, '')
+		FROM reports r
+		LEFT JOIN users u ON u.id = r.reporter_id
+		WHERE r.id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	return scanReport(stmt.QueryRow(id))
+}
+
+// GetByStatus returns the reports with the given status, oldest first so the
+// queue is handled in order.
+func (r *ReportRepository) GetByStatus(status string) ([]*models.Report, error) {
+	rows, err := r.db.Query(`
+		SELECT r.id, COALESCE(r.reporter_id, 0), r.target_type, r.target_id, r.reason, r.details, r.status,
+			r.resolved_by, r.resolution, r.created_at, r.resolved_at, COALESCE(u.username, '')
+		FROM reports r
+		LEFT JOIN users u ON u.id = r.reporter_id
+		WHERE r.status = ?
+		ORDER BY r.created_at ASC
+	`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*models.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// Resolve closes a pending report as "resolved" or "dismissed"
+func (r *ReportRepository) Resolve(id int64, moderatorID int64, status string, resolution string) error {
+	result, err := r.db.Exec(`
+		UPDATE reports SET status = ?, resolved_by = ?, resolution = ?, resolved_at = ?
+		WHERE id = ? AND status = 'pending'
+	`, status, moderatorID, resolution, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ResolveForTarget closes every pending report about a target once a
+// moderator acted on it
+func (r *ReportRepository) ResolveForTarget(targetType string, targetID int64, moderatorID int64, resolution string) error {
+	_, err := r.db.Exec(`
+		UPDATE reports SET status = 'resolved', resolved_by = ?, resolution = ?, resolved_at = ?
+		WHERE target_type = ? AND target_id = ? AND status = 'pending'
+	`, moderatorID, resolution, time.Now(), targetType, targetID)
+	return err
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReport(row rowScanner) (*models.Report, error) {
+	report := &models.Report{}
+	err := row.Scan(
+		&report.ID,
+		&report.ReporterID,
+		&report.TargetType,
+		&report.TargetID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.ResolvedBy,
+		&report.Resolution,
+		&report.CreatedAt,
+		&report.ResolvedAt,
+		&report.ReporterUsername,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
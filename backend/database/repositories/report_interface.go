@@ -0,0 +1,11 @@
+package repository
+
+import "social-network/backend/database/models"
+
+type ReportRepositoryInterface interface {
+	Create(report *models.Report) (int64, error)
+	GetByID(id int64) (*models.Report, error)
+	GetByStatus(status string) ([]*models.Report, error)
+	Resolve(id int64, moderatorID int64, status string, resolution string) error
+	ResolveForTarget(targetType string, targetID int64, moderatorID int64, resolution string) error
+}
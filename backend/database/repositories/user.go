@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 )
 
@@ -76,7 +77,8 @@ func (r *UserRepository) Create(user *models.User) (int64, error) {
 func (r *UserRepository) GetByID(id int64) (*models.User, error) {
 	stmt, err := r.db.Prepare(`
 		SELECT id, email, password_hash, first_name, last_name, birth_date,
-			avatar_path, username, about_me, is_public, created_at, updated_at
+			avatar_path, username, about_me, is_public, created_at, updated_at,
+			role, suspended, suspended_until
 		FROM users WHERE id = ?
 	`)
 	if err != nil {
@@ -98,6 +100,9 @@ func (r *UserRepository) GetByID(id int64) (*models.User, error) {
 		&user.IsPublic,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Role,
+		&user.Suspended,
+		&user.SuspendedUntil,
 	)
 	if err != nil {
 		return nil, err
@@ -110,7 +115,8 @@ func (r *UserRepository) GetByID(id int64) (*models.User, error) {
 func (r *UserRepository) GetByUserName(username string) (*models.User, error) {
 	stmt, err := r.db.Prepare(`
 		SELECT id, email, password_hash, first_name, last_name, birth_date,
-			avatar_path, username, about_me, is_public, created_at, updated_at
+			avatar_path, username, about_me, is_public, created_at, updated_at,
+			role, suspended, suspended_until
 		FROM users WHERE username = ?
 	`)
 	if err != nil {
@@ -132,6 +138,9 @@ func (r *UserRepository) GetByUserName(username string) (*models.User, error) {
 		&user.IsPublic,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Role,
+		&user.Suspended,
+		&user.SuspendedUntil,
 	)
 	if err != nil {
 		return nil, err
@@ -144,7 +153,8 @@ func (r *UserRepository) GetByUserName(username string) (*models.User, error) {
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	stmt, err := r.db.Prepare(`
 		SELECT id, email, password_hash, first_name, last_name, birth_date,
-			avatar_path, username, about_me, is_public, created_at, updated_at
+			avatar_path, username, about_me, is_public, created_at, updated_at,
+			role, suspended, suspended_until
 		FROM users WHERE email = ?
 	`)
 	if err != nil {
@@ -166,6 +176,9 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 		&user.IsPublic,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Role,
+		&user.Suspended,
+		&user.SuspendedUntil,
 	)
 	if err != nil {
 		return nil, err
@@ -180,6 +193,7 @@ func (r *UserRepository) GetFriendsByUserID(userID int64) ([]*models.User, error
 		FROM users u
 		INNER JOIN followers f1 ON f1.followed_id = u.id AND f1.follower_id = ? AND f1.accepted = TRUE
 		INNER JOIN followers f2 ON f2.follower_id = u.id AND f2.followed_id = ? AND f2.accepted = TRUE
+		WHERE ` + services.ActiveUserCondition + `
 		ORDER BY u.username
 	`)
 	if err != nil {
@@ -215,7 +229,8 @@ func (r *UserRepository) GetUsersForContact(currentUserID int64, query string) (
 		FROM users u
 		INNER JOIN followers f1 ON f1.followed_id = u.id AND f1.follower_id = ? AND f1.accepted = TRUE
 		INNER JOIN followers f2 ON f2.follower_id = u.id AND f2.followed_id = ? AND f2.accepted = TRUE
-		WHERE LOWER(u.username) LIKE LOWER(?)
+		WHERE LOWER(u.username) LIKE LOWER(?) AND ` + services.ActiveUserCondition + `
+		AND ` + services.NotBlockedCondition("u.id") + `
 		ORDER BY u.username
 		LIMIT 5
 	`)
@@ -225,7 +240,7 @@ func (r *UserRepository) GetUsersForContact(currentUserID int64, query string) (
 	defer stmt.Close()
 
 	likeQuery := "%" + query + "%"
-	rows, err := stmt.Query(currentUserID, currentUserID, likeQuery)
+	rows, err := stmt.Query(currentUserID, currentUserID, likeQuery, currentUserID, currentUserID)
 	if err != nil {
 		return nil, err
 	}
@@ -357,7 +372,7 @@ func (r *UserRepository) SearchInstance(query string, currentUserId int) ([]Sear
 			WHERE f2.follower_id = u.id AND f2.followed_id = ? AND f2.accepted = 1
 		) AS is_followed_by
 		FROM users u
-		WHERE u.id != ? AND (
+		WHERE u.id != ? AND ` + services.ActiveUserCondition + ` AND ` + services.NotBlockedCondition("u.id") + ` AND (
 			u.username LIKE ? OR u.first_name LIKE ? OR u.last_name LIKE ?
 		)
 		LIMIT 10
@@ -367,7 +382,7 @@ func (r *UserRepository) SearchInstance(query string, currentUserId int) ([]Sear
 	}
 	defer userStmt.Close()
 
-	userRows, err := userStmt.Query(currentUserId, currentUserId, currentUserId, escapedQuery, escapedQuery, escapedQuery)
+	userRows, err := userStmt.Query(currentUserId, currentUserId, currentUserId, currentUserId, currentUserId, escapedQuery, escapedQuery, escapedQuery)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -398,7 +413,11 @@ func (r *UserRepository) SearchInstance(query string, currentUserId int) ([]Sear
 			WHERE gm.group_id = g.id AND gm.user_id = ? AND gm.accepted = 1
 		) AS is_member
 		FROM groups g
-		WHERE g.title LIKE ?
+		WHERE g.title LIKE ? AND g.deleted_at IS NULL
+		AND (g.visibility != 'secret' OR EXISTS (
+			SELECT 1 FROM group_members gm
+			WHERE gm.group_id = g.id AND gm.user_id = ? AND gm.accepted = 1
+		))
 		LIMIT 10
 	`)
 	if err != nil {
@@ -406,7 +425,7 @@ func (r *UserRepository) SearchInstance(query string, currentUserId int) ([]Sear
 	}
 	defer groupStmt.Close()
 
-	groupRows, err := groupStmt.Query(currentUserId, escapedQuery)
+	groupRows, err := groupStmt.Query(currentUserId, escapedQuery, currentUserId)
 	if err != nil {
 		return nil, nil, err
 	}
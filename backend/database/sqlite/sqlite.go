@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
@@ -20,28 +21,42 @@ func InitDBAndMigrate() *sql.DB {
 		log.Fatal("Environment variable DB_PATH is not set")
 	}
 
-	// Open a SQLite connection
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := Open(dbPath, "backend/database/migrations/sqlite")
 	if err != nil {
-		log.Fatalf("Cannot open SQLite database: %v", err)
+		log.Fatal(err)
 	}
 
-	// active les foreign keys
-	_, err = db.Exec("PRAGMA foreign_keys = ON")
+	fmt.Println("Migrations applied successfully")
+	return db
+}
+
+// Open opens the SQLite database at dbPath and applies the migrations of
+// migrationsDir, relative to the working directory. Tests use it on a
+// temporary database.
+func Open(dbPath, migrationsDir string) (*sql.DB, error) {
+	// Open a SQLite connection. Foreign keys are enabled through the DSN so that
+	// every connection of the pool enforces them, not only the first one.
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	db, err := sql.Open("sqlite3", dbPath+separator+"_foreign_keys=on")
 	if err != nil {
-		log.Fatal("Impossible d’activer les foreign keys :", err)
+		return nil, fmt.Errorf("cannot open SQLite database: %w", err)
 	}
 
 	// Prepare the driver for migrations
 	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
 	if err != nil {
-		log.Fatalf("Cannot create SQLite migration driver: %v", err)
+		db.Close()
+		return nil, fmt.Errorf("cannot create SQLite migration driver: %w", err)
 	}
 
 	// Resolve the absolute path to the migration files
-	absPath, err := filepath.Abs("backend/database/migrations/sqlite")
+	absPath, err := filepath.Abs(migrationsDir)
 	if err != nil {
-		log.Fatalf("Cannot resolve absolute path for migrations: %v", err)
+		db.Close()
+		return nil, fmt.Errorf("cannot resolve absolute path for migrations: %w", err)
 	}
 
 	// Apply the migrations
@@ -49,13 +64,13 @@ func InitDBAndMigrate() *sql.DB {
 		"file://"+absPath,
 		"sqlite3", driver)
 	if err != nil {
-		log.Fatalf("Migration init failed: %v", err)
+		db.Close()
+		return nil, fmt.Errorf("migration init failed: %w", err)
 	}
 
-	if err := m.Up(); err != nil && err.Error() != "no change" {
-		log.Fatalf("Migration failed: %v", err)
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		db.Close()
+		return nil, fmt.Errorf("migration failed: %w", err)
 	}
-
-	fmt.Println("Migrations applied successfully")
-	return db
+	return db, nil
 }
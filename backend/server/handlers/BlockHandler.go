@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"social-network/backend/app/services"
+	repository "social-network/backend/database/repositories"
+	"social-network/backend/server/middlewares"
+)
+
+// BlockHandler handles the blocks and mutes of the current user.
+type BlockHandler struct {
+	BlockService   *services.BlockService
+	UserRepository *repository.UserRepository
+}
+
+// NewBlockHandler creates a new BlockHandler.
+func NewBlockHandler(bs *services.BlockService, ur *repository.UserRepository) *BlockHandler {
+	return &BlockHandler{
+		BlockService:   bs,
+		UserRepository: ur,
+	}
+}
+
+// targetUser returns the current user ID and the ID of the user in the path.
+func (h *BlockHandler) targetUser(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, 0, false
+	}
+
+	targetID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	if targetID == userID {
+		http.Error(w, services.ErrSelfBlock.Error(), http.StatusBadRequest)
+		return 0, 0, false
+	}
+	if _, err := h.UserRepository.GetByID(targetID); err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return 0, 0, false
+	}
+	return userID, targetID, true
+}
+
+func writeBlockState(w http.ResponseWriter, userID int64, field string, value bool) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userID,
+		field:     value,
+	})
+}
+
+// BlockUser blocks a user and removes the follows between both users.
+func (h *BlockHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.BlockService.Block(userID, targetID); err != nil {
+		http.Error(w, "Failed to block user", http.StatusInternalServerError)
+		return
+	}
+
+	writeBlockState(w, targetID, "blocked", true)
+}
+
+// UnblockUser lifts a block.
+func (h *BlockHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.BlockService.Unblock(userID, targetID); err != nil {
+		http.Error(w, "Failed to unblock user", http.StatusInternalServerError)
+		return
+	}
+
+	writeBlockState(w, targetID, "blocked", false)
+}
+
+// MuteUser hides the posts of a user from the current user's feed.
+func (h *BlockHandler) MuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.BlockService.Mute(userID, targetID); err != nil {
+		http.Error(w, "Failed to mute user", http.StatusInternalServerError)
+		return
+	}
+
+	writeBlockState(w, targetID, "muted", true)
+}
+
+// UnmuteUser lifts a mute.
+func (h *BlockHandler) UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.BlockService.Unmute(userID, targetID); err != nil {
+		http.Error(w, "Failed to unmute user", http.StatusInternalServerError)
+		return
+	}
+
+	writeBlockState(w, targetID, "muted", false)
+}
+
+// GetBlockedUsers lists the users blocked by the current user.
+func (h *BlockHandler) GetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	users, err := h.BlockService.ListBlocked(userID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve blocked users", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// GetMutedUsers lists the users muted by the current user.
+func (h *BlockHandler) GetMutedUsers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	users, err := h.BlockService.ListMuted(userID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve muted users", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
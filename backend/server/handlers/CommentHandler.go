@@ -3,30 +3,92 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"time"
 
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 	repository "social-network/backend/database/repositories"
+	"social-network/backend/server/middlewares"
 )
 
 // CommentHandler handles comment-related HTTP requests.
 type CommentHandler struct {
-	CommentRepository *repository.CommentRepository
-	SessionRepository *repository.SessionRepository
+	CommentRepository      *repository.CommentRepository
+	SessionRepository      *repository.SessionRepository
+	PostRepository         *repository.PostRepository
+	PostService            *services.PostService
+	ContentFilter          *services.ContentFilterService
+	NotificationRepository *repository.NotificationRepository
 }
 
 // NewCommentHandler creates a new CommentHandler.
-func NewCommentHandler(cr *repository.CommentRepository, sr *repository.SessionRepository) *CommentHandler {
+func NewCommentHandler(cr *repository.CommentRepository, sr *repository.SessionRepository, pr *repository.PostRepository, ps *services.PostService, cf *services.ContentFilterService, nr *repository.NotificationRepository) *CommentHandler {
 	return &CommentHandler{
-		CommentRepository: cr,
-		SessionRepository: sr,
+		CommentRepository:      cr,
+		SessionRepository:      sr,
+		PostRepository:         pr,
+		PostService:            ps,
+		ContentFilter:          cf,
+		NotificationRepository: nr,
 	}
 }
 
+// canViewPost checks the post audience through the PostService.
+func (h *CommentHandler) canViewPost(postID, userID int64) bool {
+	post, err := h.PostRepository.GetPostById(postID)
+	if err != nil || post == nil {
+		return false
+	}
+	return h.PostService.CanView(post, userID)
+}
+
+// canInteract checks the user may comment or like on the post. Posts of
+// public groups are readable by everyone but only current members who
+// accepted the group rules take part, the post author included. Banned users
+// and archived groups take no new interactions.
+func (h *CommentHandler) canInteract(postID, userID int64) error {
+	post, err := h.PostRepository.GetPostById(postID)
+	if err != nil || post == nil {
+		return services.ErrPostNotVisible
+	}
+	if post.GroupID == nil {
+		return nil
+	}
+	if h.PostService.Groups.IsBanned(*post.GroupID, userID) {
+		return services.ErrGroupBanned
+	}
+	if h.PostService.Groups.IsArchived(*post.GroupID) {
+		return services.ErrGroupArchived
+	}
+	if !h.PostService.Groups.IsMember(*post.GroupID, userID) {
+		return services.ErrNotGroupMember
+	}
+	if !h.PostService.Groups.HasAcceptedRules(*post.GroupID, userID) {
+		return services.ErrGroupRulesNotAccepted
+	}
+	return nil
+}
+
+// isBlocked reports whether the two users blocked each other, in either way.
+func (h *CommentHandler) isBlocked(userID, otherID int64) bool {
+	return h.PostService.Blocks != nil && h.PostService.Blocks.IsBlocked(userID, otherID)
+}
+
+// viewerFromCookie returns the user ID from the jwt cookie, or 0 when absent.
+func viewerFromCookie(r *http.Request) int64 {
+	token, err := r.Cookie("jwt")
+	if err != nil {
+		return 0
+	}
+	return middlewares.CheckJWT(token.Value)
+}
+
 // Request structs
 type createCommentRequest struct {
 	JWT       string  `json:"jwt"`
 	PostID    int64   `json:"post_id"`
+	ParentID  *int64  `json:"parent_id,omitempty"`
 	Content   string  `json:"content"`
 	ImagePath *string `json:"image_path,omitempty"`
 }
@@ -50,7 +112,11 @@ type deleteCommentRequest struct {
 }
 
 type getPostCommentsRequest struct {
-	PostID int64 `json:"post_id"`
+	JWT    string `json:"jwt"`
+	PostID int64  `json:"post_id"`
+	Sort   string `json:"sort,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
 }
 
 // Handlers
@@ -76,30 +142,117 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    comment := &models.Comment{
-        PostID:    req.PostID,
-        UserID:    session.UserID,
-        Content:   req.Content,
-        ImagePath: req.ImagePath,
-        CreatedAt: time.Now(),
-        UpdatedAt: time.Now(),
-        Author:    *user, // attach full user info as Author
-    }
-
-    id, err := h.CommentRepository.Create(comment)
-    if err != nil {
-        http.Error(w, "Failed to create comment", http.StatusInternalServerError)
+    if !h.canViewPost(req.PostID, session.UserID) {
+        http.Error(w, "Post not found", http.StatusNotFound)
         return
     }
 
-    comment.ID = id
+    h.addComment(w, user, req.PostID, req.ParentID, req.Content, req.ImagePath)
+}
+
+// addComment writes a comment or a reply on a post the user can see, then
+// answers with the created comment, also returned on success. Group comments
+// go through it as well.
+func (h *CommentHandler) addComment(w http.ResponseWriter, user *models.User, postID int64, parentID *int64, content string, imagePath *string) *models.Comment {
+	if err := h.canInteract(postID, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil
+	}
+
+	var parent *models.Comment
+	if parentID != nil {
+		var err error
+		parent, err = h.CommentRepository.GetByID(*parentID)
+		if err != nil || parent.PostID != postID || h.isBlocked(parent.UserID, user.ID) {
+			http.Error(w, "Parent comment not found", http.StatusNotFound)
+			return nil
+		}
+	}
+
+	outcome, err := h.ContentFilter.Run(user.ID, services.ContentComment, content)
+	if err != nil {
+		writeFilterError(w, err)
+		return nil
+	}
+
+	comment := &models.Comment{
+		PostID:    postID,
+		UserID:    user.ID,
+		Content:   outcome.Content,
+		ImagePath: imagePath,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Username:  user.Username,
+		Author:    *user, // attach full user info as Author
+	}
+	if parent != nil {
+		comment.ParentID, comment.Depth = replyPlacement(parent)
+	}
 
-    w.Header().Set("Content-Type", "application/json")
-    w.WriteHeader(http.StatusCreated)
-    json.NewEncoder(w).Encode(comment)
+	id, err := h.CommentRepository.Create(comment)
+	if err != nil {
+		http.Error(w, "Failed to create comment", http.StatusInternalServerError)
+		return nil
+	}
+
+	comment.ID = id
+	h.ContentFilter.FlagForReview(services.ContentComment, id, outcome)
+
+	if parent != nil && parent.UserID != user.ID {
+		notifyUser(h.NotificationRepository, parent.UserID, "comment_reply",
+			user.Username+" replied to your comment", id, "comment")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+	return comment
+}
+
+// replyPlacement returns the parent and depth of a reply. Threads stop at
+// MaxCommentDepth: deeper replies are attached to the parent's own parent.
+func replyPlacement(parent *models.Comment) (*int64, int) {
+	if parent.Depth >= repository.MaxCommentDepth && parent.ParentID != nil {
+		return parent.ParentID, parent.Depth
+	}
+	return &parent.ID, parent.Depth + 1
 }
 
+// pageParams reads the limit and offset query parameters of a paginated list.
+func pageParams(r *http.Request, defaultLimit, maxLimit int) (int, int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// commentPageFromQuery reads the sort, cursor and limit query parameters.
+func commentPageFromQuery(r *http.Request, viewerID int64) repository.CommentPageQuery {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	return repository.CommentPageQuery{
+		Sort:   r.URL.Query().Get("sort"),
+		Cursor: r.URL.Query().Get("cursor"),
+		Limit:  limit,
+		Viewer: viewerID,
+	}
+}
 
+// writeCommentPageError answers a failed comment listing.
+func writeCommentPageError(w http.ResponseWriter, err error) {
+	switch err {
+	case repository.ErrInvalidCommentSort:
+		http.Error(w, "Invalid sort, expected oldest, newest or most_reacted", http.StatusBadRequest)
+	case repository.ErrInvalidCursor:
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+	default:
+		http.Error(w, "Failed to retrieve comments", http.StatusInternalServerError)
+	}
+}
 
 // GetComment returns a single comment by ID.
 func (h *CommentHandler) GetComment(w http.ResponseWriter, r *http.Request) {
@@ -110,8 +263,9 @@ func (h *CommentHandler) GetComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID := viewerFromCookie(r)
 	comment, err := h.CommentRepository.GetByID(req.ID)
-	if err != nil {
+	if err != nil || h.isBlocked(comment.UserID, viewerID) || !h.canViewPost(comment.PostID, viewerID) {
 		http.Error(w, "Comment not found", http.StatusNotFound)
 		return
 	}
@@ -126,14 +280,19 @@ func (h *CommentHandler) GetCommentsFromUserByID(w http.ResponseWriter, r *http.
 		return
 	}
 
-	comment, err := h.CommentRepository.GetCommentsFromUserByID(req.ID)
-	if err != nil {
+	viewerID := viewerFromCookie(r)
+	if h.isBlocked(req.ID, viewerID) {
 		http.Error(w, "Comment not found", http.StatusNotFound)
 		return
 	}
 
+	comments, err := h.CommentRepository.GetCommentsFromUserByID(req.ID, viewerID)
+	if err != nil {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
 
-	json.NewEncoder(w).Encode(comment)
+	json.NewEncoder(w).Encode(comments)
 }
 
 // GetCommentsByPost returns all comments for a specific post.
@@ -145,13 +304,139 @@ func (h *CommentHandler) GetCommentsByPost(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	comments, err := h.CommentRepository.GetComments(req.PostID)
+	session, err := h.SessionRepository.GetBySessionToken(req.JWT)
 	if err != nil {
-		http.Error(w, "Failed to retrieve comments", http.StatusInternalServerError)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	json.NewEncoder(w).Encode(comments)
+	if !h.canViewPost(req.PostID, session.UserID) {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	h.writeCommentPage(w, req.PostID, repository.CommentPageQuery{
+		Sort:   req.Sort,
+		Cursor: req.Cursor,
+		Limit:  req.Limit,
+		Viewer: session.UserID,
+	})
+}
+
+// writeCommentPage answers with a page of the top-level comments of a post.
+func (h *CommentHandler) writeCommentPage(w http.ResponseWriter, postID int64, query repository.CommentPageQuery) {
+	page, err := h.CommentRepository.GetComments(postID, query)
+	if err != nil {
+		writeCommentPageError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// CountComments returns the number of visible comments of a post.
+func (h *CommentHandler) CountComments(w http.ResponseWriter, r *http.Request) {
+	postID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	if !h.canViewPost(postID, viewerFromCookie(r)) {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	h.writeCommentCount(w, postID)
+}
+
+func (h *CommentHandler) writeCommentCount(w http.ResponseWriter, postID int64) {
+	count, err := h.CommentRepository.CountComments(postID)
+	if err != nil {
+		http.Error(w, "Failed to count comments", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(count)
+}
+
+// LikeComment adds or removes the like of the current user on a comment.
+func (h *CommentHandler) LikeComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	commentID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.CommentRepository.GetByID(commentID)
+	if err != nil || h.isBlocked(comment.UserID, userID) || !h.canViewPost(comment.PostID, userID) {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+
+	h.toggleLike(w, comment, userID)
+}
+
+func (h *CommentHandler) toggleLike(w http.ResponseWriter, comment *models.Comment, userID int64) {
+	if err := h.canInteract(comment.PostID, userID); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	liked, count, err := h.CommentRepository.ToggleLike(comment.ID, userID)
+	if err != nil {
+		http.Error(w, "Failed to like comment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"liked":       liked,
+		"likes_count": count,
+	})
+}
+
+// GetCommentReplies returns a page of the replies of a comment, used to load
+// more replies than the ones returned with the post comments.
+func (h *CommentHandler) GetCommentReplies(w http.ResponseWriter, r *http.Request) {
+	commentID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	viewerID := viewerFromCookie(r)
+	parent, err := h.CommentRepository.GetByID(commentID)
+	if err != nil || h.isBlocked(parent.UserID, viewerID) || !h.canViewPost(parent.PostID, viewerID) {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return
+	}
+
+	h.writeReplies(w, r, parent, viewerID)
+}
+
+func (h *CommentHandler) writeReplies(w http.ResponseWriter, r *http.Request, parent *models.Comment, viewerID int64) {
+	limit, offset := pageParams(r, 10, 50)
+	replies, err := h.CommentRepository.GetReplies(parent.ID, viewerID, limit, offset)
+	if err != nil {
+		http.Error(w, "Failed to retrieve replies", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"replies":       replies,
+		"replies_count": parent.RepliesCount,
+		"has_more":      int64(offset+len(replies)) < parent.RepliesCount,
+	})
 }
 
 // UpdateComment modifies an existing comment.
@@ -168,7 +453,14 @@ func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	comment.Content = req.Content
+	// Une modification passe par les mêmes filtres qu'une création
+	outcome, err := h.ContentFilter.RunEdit(comment.UserID, services.ContentComment, comment.ID, req.Content)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
+	comment.Content = outcome.Content
 	comment.ImagePath = req.ImagePath
 	comment.UpdatedAt = time.Now()
 
@@ -176,6 +468,7 @@ func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to update comment", http.StatusInternalServerError)
 		return
 	}
+	h.ContentFilter.FlagForReview(services.ContentComment, comment.ID, outcome)
 
 	json.NewEncoder(w).Encode(map[string]string{
 		"message": "Comment updated successfully",
@@ -1,46 +1,115 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 	repository "social-network/backend/database/repositories"
 	"social-network/backend/server/middlewares"
+	"social-network/backend/websocket"
 
 	"github.com/gorilla/mux"
 )
 
 // EventHandler handles HTTP requests related to events.
 type EventHandler struct {
-	EventRepository *repository.EventRepository
-	GroupRepository *repository.GroupRepository
+	EventRepository        *repository.EventRepository
+	GroupRepository        *repository.GroupRepository
+	GroupService           *services.GroupService
+	NotificationRepository *repository.NotificationRepository
 }
 
 // NewEventHandler creates a new EventHandler.
-func NewEventHandler(er *repository.EventRepository, gr *repository.GroupRepository) *EventHandler {
+func NewEventHandler(er *repository.EventRepository, gr *repository.GroupRepository, gs *services.GroupService, nr *repository.NotificationRepository) *EventHandler {
 	return &EventHandler{
-		EventRepository: er,
-		GroupRepository: gr,
+		EventRepository:        er,
+		GroupRepository:        gr,
+		GroupService:           gs,
+		NotificationRepository: nr,
 	}
 }
 
 // Request DTOs
 
+// createEventRequest holds the fields a client may set on a new event; the
+// others are set by the server.
 type createEventRequest struct {
-	GroupID     int64     `json:"group_id"`
-	CreatorID   int64     `json:"creator_id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	EventDate   time.Time `json:"event_date"`
+	Location    string    `json:"location"`
+	Capacity    *int64    `json:"capacity,omitempty"`
+	Recurrence  string    `json:"recurrence,omitempty"`
+	TimeZone    string    `json:"time_zone,omitempty"` // IANA zone of a series, UTC by default
 }
 type setEventResponseRequest struct {
 	EventID int64  `json:"event_id"`
 	UserID  int64  `json:"user_id"`
-	Status  string `json:"status"` // "going" or "not_going"
+	Status  string `json:"status"` // "going", "maybe" or "not_going"
+}
+
+// updateEventRequest holds the edited fields of an event; omitted fields are
+// left unchanged.
+type updateEventRequest struct {
+	Title       *string    `json:"title,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	EventDate   *time.Time `json:"event_date,omitempty"`
+	Location    *string    `json:"location,omitempty"`
+	Capacity    *int64     `json:"capacity,omitempty"`   // 0 removes the limit
+	Recurrence  *string    `json:"recurrence,omitempty"` // series only
+	Reason      string     `json:"reason,omitempty"`
+}
+
+type cancelEventRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Limits of the edited fields of an event.
+const (
+	maxEventTitleLength       = 100
+	maxEventDescriptionLength = 1000
+	maxEventLocationLength    = 200
+	maxEventReasonLength      = 500
+)
+
+// validateEventFields checks the fields set on an event, by its creation or
+// an edit, against their limits. The title is trimmed and the rule of a
+// series normalized.
+func validateEventFields(req *updateEventRequest) error {
+	if req.Title != nil {
+		*req.Title = strings.TrimSpace(*req.Title)
+		if *req.Title == "" || len(*req.Title) > maxEventTitleLength {
+			return errors.New("Title must be between 1 and 100 characters")
+		}
+	}
+	if (req.Description != nil && len(*req.Description) > maxEventDescriptionLength) ||
+		(req.Location != nil && len(*req.Location) > maxEventLocationLength) ||
+		len(req.Reason) > maxEventReasonLength {
+		return errors.New("Description, location or reason too long")
+	}
+	if req.Capacity != nil && *req.Capacity < 0 {
+		return errors.New("Capacity cannot be negative")
+	}
+	if req.Recurrence != nil {
+		rule, err := services.ParseRecurrence(*req.Recurrence)
+		if err != nil {
+			return err
+		}
+		*req.Recurrence = rule.String()
+	}
+	if req.EventDate != nil && req.EventDate.IsZero() {
+		return errors.New("Invalid event date")
+	}
+	return nil
 }
 
 // Handlers
@@ -56,17 +125,60 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	userID := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !h.GroupService.Can(groupID, userID, services.PermCreateEvent) {
+		http.Error(w, "Not allowed to create events in this group", http.StatusForbidden)
+		return
+	}
+	if h.GroupService.IsArchived(groupID) {
+		http.Error(w, services.ErrGroupArchived.Error(), http.StatusForbidden)
+		return
+	}
 
-	var event models.Event
-	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+	var req createEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid body", http.StatusBadRequest)
 		return
 	}
+	fields := updateEventRequest{
+		Title:       &req.Title,
+		Description: &req.Description,
+		EventDate:   &req.EventDate,
+		Location:    &req.Location,
+		Capacity:    req.Capacity,
+	}
+	if req.Recurrence != "" {
+		fields.Recurrence = &req.Recurrence
+	}
+	if err := validateEventFields(&fields); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Capacity != nil && *req.Capacity == 0 {
+		http.Error(w, "Capacity must be positive", http.StatusBadRequest)
+		return
+	}
+	if req.TimeZone == "" {
+		req.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.TimeZone); err != nil || req.TimeZone == "Local" {
+		http.Error(w, "Invalid time zone", http.StatusBadRequest)
+		return
+	}
 
-	event.GroupID = groupID
-	event.CreatorID = userID
-	event.CreatedAt = time.Now()
-	event.UpdatedAt = time.Now()
+	// Les occurrences d'une série sont créées à leur première utilisation
+	event := models.Event{
+		GroupID:     groupID,
+		CreatorID:   userID,
+		Title:       req.Title,
+		Description: &req.Description,
+		EventDate:   req.EventDate,
+		Location:    req.Location,
+		Capacity:    req.Capacity,
+		Recurrence:  req.Recurrence,
+		TimeZone:    req.TimeZone,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
 
 	id, err := h.EventRepository.CreateEvent(&event)
 	if err != nil {
@@ -91,40 +203,62 @@ func (h *EventHandler) SetEventResponse(w http.ResponseWriter, r *http.Request)
 	var payload struct {
 		Status string `json:"status"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || (payload.Status != "going" && payload.Status != "not_going") {
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil ||
+		(payload.Status != repository.EventGoing && payload.Status != repository.EventMaybe && payload.Status != repository.EventNotGoing) {
 		http.Error(w, "Invalid status", http.StatusBadRequest)
 		return
 	}
 
-	err = h.EventRepository.SetEventResponse(eventID, userID, payload.Status)
+	// Get the event to find the group ID
+	event, err := h.EventRepository.GetByID(eventID)
 	if err != nil {
-		http.Error(w, "Failed to set response: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	if !groupAccess(h.GroupService, w, event.GroupID, userID, true) {
 		return
 	}
 
-	// Get the event to find the group ID
-	event, err := h.EventRepository.GetByID(eventID)
+	// On répond à une occurrence d'une série, pas à la série entière
+	date, ok := occurrenceDate(w, r, event)
+	if !ok {
+		return
+	}
+	if event.Recurrence != "" && date == nil {
+		http.Error(w, "Answer an occurrence of a recurring event", http.StatusBadRequest)
+		return
+	}
+	if date != nil {
+		if event, err = h.EventRepository.GetOccurrence(event.ID, *date); err != nil {
+			http.Error(w, "Failed to retrieve occurrence", http.StatusInternalServerError)
+			return
+		}
+		eventID = event.ID
+	}
+	if event.CancelledAt != nil {
+		http.Error(w, "Event is cancelled", http.StatusConflict)
+		return
+	}
+
+	status, promoted, err := h.EventRepository.SetEventResponse(eventID, userID, payload.Status)
 	if err != nil {
-		http.Error(w, "Failed to get event: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to set response: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.notifyPromoted(event, promoted)
 
 	// Get updated event with participants for broadcast (without specific user response status)
 	updatedEvent, err := h.EventRepository.GetEventWithResponsesForBroadcast(eventID)
 	if err == nil {
 		// Broadcast the updated event data to all group members
-		broadcastData := map[string]interface{}{
-			"type":  "event_response_update",
-			"event": updatedEvent,
-		}
-
-		// Use the existing BroadcastToGroupClients function
-		BroadcastToGroupClients(event.GroupID, broadcastData)
+		websocket.GlobalHub.BroadcastToGroup(event.GroupID, "event_response_update", updatedEvent)
 	} else {
 		fmt.Printf("❌ Error getting updated event: %v\n", err)
 	}
 
-	w.WriteHeader(http.StatusOK)
+	// Le statut enregistré peut différer de celui demandé si l'événement est complet
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"event_id": eventID, "status": status})
 }
 
 func (h *EventHandler) GetEventsByGroupID(w http.ResponseWriter, r *http.Request) {
@@ -137,8 +271,22 @@ func (h *EventHandler) GetEventsByGroupID(w http.ResponseWriter, r *http.Request
 	}
 
 	userID := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !groupAccess(h.GroupService, w, groupID, userID, false) {
+		return
+	}
 
-	events, err := h.EventRepository.GetEventsWithResponsesByGroupID(groupID, userID)
+	// Période facultative, les séries sont développées en occurrences
+	var from, to time.Time
+	for name, bound := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := r.URL.Query().Get(name); value != "" {
+			if *bound, err = time.Parse(time.RFC3339, value); err != nil {
+				http.Error(w, "Invalid "+name+" date", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	events, err := h.EventRepository.GetEventsWithResponsesByGroupID(groupID, userID, from, to)
 	if err != nil {
 		http.Error(w, "Failed to fetch events: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -148,20 +296,335 @@ func (h *EventHandler) GetEventsByGroupID(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(events)
 }
 
-func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	eventIDStr := vars["eventID"]
-	eventID, err := strconv.ParseInt(eventIDStr, 10, 64)
+// eventManager loads the event of the path, or the occurrence of a series
+// given by the "occurrence" query parameter, if the current user can manage
+// it: its creator, or a member with the given permission. Events of archived
+// groups cannot be managed.
+func (h *EventHandler) eventManager(w http.ResponseWriter, r *http.Request, perm services.GroupPermission) (*models.Event, int64, bool) {
+	eventID, err := parseIDVar(r, "eventID")
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return nil, 0, false
+	}
+
+	event, err := h.EventRepository.GetByID(eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return nil, 0, false
+	}
+	if !groupAccess(h.GroupService, w, event.GroupID, userID, true) {
+		return nil, 0, false
+	}
+
+	// Le créateur peut toujours gérer son événement
+	if event.CreatorID != userID && !h.GroupService.Can(event.GroupID, userID, perm) {
+		http.Error(w, "Not allowed to manage this event", http.StatusForbidden)
+		return nil, 0, false
+	}
+
+	date, ok := occurrenceDate(w, r, event)
+	if !ok {
+		return nil, 0, false
+	}
+	if date != nil {
+		if event, err = h.EventRepository.GetOccurrence(event.ID, *date); err != nil {
+			http.Error(w, "Failed to retrieve occurrence", http.StatusInternalServerError)
+			return nil, 0, false
+		}
+	}
+	if event.CancelledAt != nil {
+		http.Error(w, "Event is cancelled", http.StatusConflict)
+		return nil, 0, false
+	}
+	return event, userID, true
+}
+
+// occurrenceDate reads the "occurrence" query parameter, the planned date of
+// an occurrence of the series of the path. It is nil when absent.
+func occurrenceDate(w http.ResponseWriter, r *http.Request, event *models.Event) (*time.Time, bool) {
+	value := r.URL.Query().Get("occurrence")
+	if value == "" {
+		return nil, true
+	}
+	if event.Recurrence == "" {
+		http.Error(w, "Event is not recurring", http.StatusBadRequest)
+		return nil, false
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		http.Error(w, "Invalid occurrence date", http.StatusBadRequest)
+		return nil, false
+	}
+	date = date.UTC()
+
+	rule, err := services.ParseRecurrence(event.Recurrence)
+	if err != nil || !rule.Includes(services.InTimeZone(event.EventDate, event.TimeZone), date) {
+		http.Error(w, "Occurrence not found", http.StatusNotFound)
+		return nil, false
+	}
+	return &date, true
+}
+
+// eventChanges applies the request to the event and lists the fields that
+// actually changed.
+func eventChanges(event *models.Event, req *updateEventRequest, userID int64) []*models.EventChange {
+	now := time.Now()
+	var changes []*models.EventChange
+	record := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, &models.EventChange{
+				ChangedBy: &userID,
+				Field:     field,
+				OldValue:  oldValue,
+				NewValue:  newValue,
+				Reason:    req.Reason,
+				ChangedAt: now,
+			})
+		}
+	}
+
+	if req.Title != nil {
+		record("title", event.Title, *req.Title)
+		event.Title = *req.Title
+	}
+	if req.Description != nil {
+		description := ""
+		if event.Description != nil {
+			description = *event.Description
+		}
+		record("description", description, *req.Description)
+		event.Description = req.Description
+	}
+	if req.EventDate != nil && !req.EventDate.Equal(event.EventDate) {
+		record("event_date", event.EventDate.Format(time.RFC3339), req.EventDate.Format(time.RFC3339))
+		event.EventDate = *req.EventDate
+	}
+	if req.Location != nil {
+		record("location", event.Location, *req.Location)
+		event.Location = *req.Location
+	}
+	if req.Capacity != nil {
+		capacity := req.Capacity
+		if *capacity == 0 {
+			capacity = nil
+		}
+		record("capacity", formatCapacity(event.Capacity), formatCapacity(capacity))
+		event.Capacity = capacity
+	}
+	if req.Recurrence != nil {
+		record("recurrence", event.Recurrence, *req.Recurrence)
+		event.Recurrence = *req.Recurrence
+	}
+	return changes
+}
+
+// formatCapacity writes the capacity of an event for its history; events
+// without a limit have an empty capacity.
+func formatCapacity(capacity *int64) string {
+	if capacity == nil {
+		return ""
+	}
+	return strconv.FormatInt(*capacity, 10)
+}
+
+// notifyPromoted tells the users taken off the waitlist of an event that they
+// are now going.
+func (h *EventHandler) notifyPromoted(event *models.Event, userIDs []int64) {
+	notifyPromotion(h.NotificationRepository, &services.WaitlistPromotion{EventID: event.ID, EventTitle: event.Title, UserIDs: userIDs})
+}
+
+// notifyPromotion tells the users taken off the waitlist of an event.
+func notifyPromotion(nr *repository.NotificationRepository, promotion *services.WaitlistPromotion) {
+	content := fmt.Sprintf("Une place s'est libérée : vous participez à l'événement \"%s\".", promotion.EventTitle)
+	for _, userID := range promotion.UserIDs {
+		notifyUser(nr, userID, "event_waitlist_promoted", content, promotion.EventID, "event")
+	}
+}
+
+// notifyRemoved tells the users who answered occurrences dropped from a
+// series, but the actor, that they no longer take place.
+func (h *EventHandler) notifyRemoved(event *models.Event, actorID int64, userIDs []int64) {
+	content := fmt.Sprintf("Des dates de l'événement \"%s\" auxquelles vous aviez répondu ont été retirées de la série.", event.Title)
+	for _, userID := range userIDs {
+		if userID != actorID {
+			notifyUser(h.NotificationRepository, userID, "event_occurrence_removed", content, event.ID, "event")
+		}
+	}
+}
+
+// notifyAttendees tells the users going to an event, but the actor, that it
+// changed, then pushes the new state of the event to the group.
+func (h *EventHandler) notifyAttendees(event *models.Event, actorID int64, notifType, content string) {
+	userIDs, err := h.EventRepository.GetGoingUserIDs(event.ID)
+	if err != nil {
+		fmt.Println("Failed to list event attendees:", err)
+	}
+	for _, userID := range userIDs {
+		if userID != actorID {
+			notifyUser(h.NotificationRepository, userID, notifType, content, event.ID, "event")
+		}
+	}
+
+	// Les clients rechargent les occurrences d'une série modifiée
+	if event.Recurrence != "" {
+		websocket.GlobalHub.BroadcastToGroup(event.GroupID, "event_series_updated", event)
+		return
+	}
+	updatedEvent, err := h.EventRepository.GetEventWithResponsesForBroadcast(event.ID)
+	if err != nil {
+		fmt.Printf("❌ Error getting updated event: %v\n", err)
+		return
+	}
+	websocket.GlobalHub.BroadcastToGroup(event.GroupID, notifType, updatedEvent)
+}
+
+// UpdateEvent edits the title, description, date or location of an event.
+func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
+	event, userID, ok := h.eventManager(w, r, services.PermEditEvent)
+	if !ok {
+		return
+	}
+
+	var req updateEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	// Un événement unique ne devient pas une série, ni l'inverse
+	if req.Recurrence != nil && event.Recurrence == "" {
+		http.Error(w, "Only the rule of a whole series can be changed", http.StatusBadRequest)
+		return
+	}
+	if err := validateEventFields(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	title := event.Title
+	changes := eventChanges(event, &req, userID)
+	if len(changes) > 0 {
+		event.UpdatedAt = time.Now()
+		promoted, removed, err := h.EventRepository.UpdateEvent(event, userID, changes)
+		if err != nil {
+			http.Error(w, "Failed to update event: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.notifyPromoted(event, promoted)
+		h.notifyRemoved(event, userID, removed)
+
+		fields := make([]string, len(changes))
+		for i, change := range changes {
+			fields[i] = change.Field
+		}
+		h.notifyAttendees(event, userID, "event_updated",
+			fmt.Sprintf("L'événement \"%s\" a été modifié (%s).", title, strings.Join(fields, ", ")))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+// CancelEvent cancels an event with an optional reason. The event is kept,
+// with its responses and history.
+func (h *EventHandler) CancelEvent(w http.ResponseWriter, r *http.Request) {
+	event, userID, ok := h.eventManager(w, r, services.PermCancelEvent)
+	if !ok {
+		return
+	}
+
+	// Le corps est facultatif, notamment pour DELETE
+	var req cancelEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len(req.Reason) > maxEventReasonLength {
+		http.Error(w, "Reason too long", http.StatusBadRequest)
+		return
+	}
+
+	cancelled, err := h.EventRepository.CancelEvent(event.ID, userID, req.Reason)
+	if err != nil {
+		http.Error(w, "Failed to cancel event: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !cancelled {
+		http.Error(w, "Event is cancelled", http.StatusConflict)
+		return
+	}
+
+	content := fmt.Sprintf("L'événement \"%s\" a été annulé.", event.Title)
+	if req.Reason != "" {
+		content = fmt.Sprintf("L'événement \"%s\" a été annulé : %s", event.Title, req.Reason)
+	}
+	h.notifyAttendees(event, userID, "event_cancelled", content)
+
+	cancelledEvent, err := h.EventRepository.GetByID(event.ID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve event", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cancelledEvent)
+}
+
+// GetEventHistory lists the changes made to an event, oldest first.
+func (h *EventHandler) GetEventHistory(w http.ResponseWriter, r *http.Request) {
+	eventID, err := parseIDVar(r, "eventID")
 	if err != nil {
 		http.Error(w, "Invalid event ID", http.StatusBadRequest)
 		return
 	}
 
-	err = h.EventRepository.DeleteEvent(eventID)
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := h.EventRepository.GetByID(eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	if !groupAccess(h.GroupService, w, event.GroupID, userID, false) {
+		return
+	}
+
+	// Une occurrence jamais modifiée n'a pas d'historique propre
+	date, ok := occurrenceDate(w, r, event)
+	if !ok {
+		return
+	}
+	if date != nil {
+		occurrence, err := h.EventRepository.FindOccurrence(eventID, *date)
+		if err == sql.ErrNoRows {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode([]*models.EventChange{})
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to retrieve occurrence", http.StatusInternalServerError)
+			return
+		}
+		eventID = occurrence.ID
+	}
+
+	changes, err := h.EventRepository.GetEventChanges(eventID)
 	if err != nil {
-		http.Error(w, "Failed to delete event: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to retrieve event history", http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
 }
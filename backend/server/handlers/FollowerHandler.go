@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 	repository "social-network/backend/database/repositories"
 	"social-network/backend/server/middlewares"
@@ -17,6 +18,7 @@ type FollowerHandler struct {
 	FollowerRepository     *repository.FollowerRepository
 	NotificationRepository *repository.NotificationRepository
 	UserRepository         *repository.UserRepository
+	BlockService           *services.BlockService
 }
 
 // NewFollowerHandler creates a new instance of FollowerHandler.
@@ -24,11 +26,13 @@ func NewFollowerHandler(
 	fr *repository.FollowerRepository, 
 	nr *repository.NotificationRepository, 
 	ur *repository.UserRepository,  // Add this
+	bs *services.BlockService,
 ) *FollowerHandler {
 	return &FollowerHandler{
 		FollowerRepository:     fr,
 		NotificationRepository: nr,
 		UserRepository:         ur,  // Initialize it
+		BlockService:           bs,
 	}
 }
 
@@ -54,6 +58,11 @@ func (h *FollowerHandler) CreateFollower(w http.ResponseWriter, r *http.Request)
         return
     }
 
+    if h.BlockService.IsBlocked(req.FollowerID, req.FollowedID) {
+        http.Error(w, "User not found", http.StatusNotFound)
+        return
+    }
+
     // Determine if the follow is automatic or a request
     accepted := req.IsPublic
 
@@ -250,42 +259,46 @@ func (h *FollowerHandler) GetFollowersHandler(w http.ResponseWriter, r *http.Req
 	json.NewEncoder(w).Encode(response)
 }
 
+// AcceptFollower accepts the follow request of one user, received by the
+// current user.
 func (h *FollowerHandler) AcceptFollower(w http.ResponseWriter, r *http.Request) {
-	var req acceptFollowerRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
+	h.resolveFollowRequest(w, r, true)
+}
 
-	if err := h.FollowerRepository.Accept(req.FollowerID, req.FollowedID); err != nil {
-		http.Error(w, "Failed to accept follower", http.StatusInternalServerError)
-		return
-	}
+// DeclineFollower declines the follow request of one user, received by the
+// current user.
+func (h *FollowerHandler) DeclineFollower(w http.ResponseWriter, r *http.Request) {
+	h.resolveFollowRequest(w, r, false)
+}
 
-	if err := h.NotificationRepository.DeleteFollowRequestFromUser(req.FollowedID, req.FollowerID); err != nil {
-		http.Error(w, "Failed to delete friend request", http.StatusInternalServerError)
+// resolveFollowRequest resolves a single request like the bulk endpoints.
+// The followed user is always the current user, whatever the body says.
+func (h *FollowerHandler) resolveFollowRequest(w http.ResponseWriter, r *http.Request, accept bool) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-}
-
-func (h *FollowerHandler) DeclineFollower(w http.ResponseWriter, r *http.Request) {
 	var req acceptFollowerRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.FollowerID == 0 {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if err := h.FollowerRepository.Delete(req.FollowerID, req.FollowedID); err != nil {
-		http.Error(w, "Failed to decline follower", http.StatusInternalServerError)
+	processed, _, ok := h.resolvePending(w, userID, []int64{req.FollowerID}, accept)
+	if !ok {
 		return
 	}
-
-	if err := h.NotificationRepository.DeleteFollowRequestFromUser(req.FollowedID, req.FollowerID); err != nil {
-		http.Error(w, "Failed to delete friend request", http.StatusInternalServerError)
+	if len(processed) == 0 {
+		http.Error(w, "Follow request not found", http.StatusNotFound)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"processed": processed,
+	})
 }
 
 func (h *FollowerHandler) GetFollowingHandler(w http.ResponseWriter, r *http.Request) {
@@ -353,3 +366,153 @@ func (h *FollowerHandler) GetFriendsHandler(w http.ResponseWriter, r *http.Reque
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(response)
 }
+
+// MaxBulkFollowRequests caps the requests handled by one bulk action.
+const MaxBulkFollowRequests = 100
+
+type bulkFollowRequestsRequest struct {
+	FollowerIDs []int64 `json:"follower_ids"`
+}
+
+// pushFollowRequestUpdate tells both users in real time that a request was
+// accepted, declined or cancelled.
+func pushFollowRequestUpdate(followerID, followedID int64, status string) {
+	if websocket.GlobalHub == nil {
+		return
+	}
+	update := map[string]interface{}{
+		"follower_id": followerID,
+		"followed_id": followedID,
+		"status":      status,
+	}
+	websocket.GlobalHub.SendEventToUser(followerID, "follow_request_updated", update)
+	websocket.GlobalHub.SendEventToUser(followedID, "follow_request_updated", update)
+}
+
+// GetIncomingFollowRequests returns a page of the requests received by the current user.
+func (h *FollowerHandler) GetIncomingFollowRequests(w http.ResponseWriter, r *http.Request) {
+	h.writeFollowRequests(w, r, h.FollowerRepository.GetIncomingRequests)
+}
+
+// GetOutgoingFollowRequests returns a page of the requests sent by the current user.
+func (h *FollowerHandler) GetOutgoingFollowRequests(w http.ResponseWriter, r *http.Request) {
+	h.writeFollowRequests(w, r, h.FollowerRepository.GetOutgoingRequests)
+}
+
+func (h *FollowerHandler) writeFollowRequests(w http.ResponseWriter, r *http.Request, list func(int64, int, int) (*models.FollowRequestPage, error)) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit, offset := pageParams(r, 20, 100)
+	page, err := list(userID, limit, offset)
+	if err != nil {
+		http.Error(w, "Failed to retrieve follow requests", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// AcceptFollowRequests accepts several requests received by the current user.
+func (h *FollowerHandler) AcceptFollowRequests(w http.ResponseWriter, r *http.Request) {
+	h.resolveFollowRequests(w, r, true)
+}
+
+// DeclineFollowRequests declines several requests received by the current user.
+func (h *FollowerHandler) DeclineFollowRequests(w http.ResponseWriter, r *http.Request) {
+	h.resolveFollowRequests(w, r, false)
+}
+
+func (h *FollowerHandler) resolveFollowRequests(w http.ResponseWriter, r *http.Request, accept bool) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req bulkFollowRequestsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.FollowerIDs) == 0 {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.FollowerIDs) > MaxBulkFollowRequests {
+		http.Error(w, "Too many follow requests", http.StatusBadRequest)
+		return
+	}
+
+	processed, notFound, ok := h.resolvePending(w, userID, req.FollowerIDs, accept)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"processed": processed,
+		"not_found": notFound,
+	})
+}
+
+// resolvePending accepts or declines the requests of the followers in one
+// transaction, then tells each follower. It writes the error response and
+// returns false on failure.
+func (h *FollowerHandler) resolvePending(w http.ResponseWriter, userID int64, followerIDs []int64, accept bool) ([]int64, []int64, bool) {
+	user, err := h.UserRepository.GetByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to get user", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	processed, notFound, err := h.FollowerRepository.ResolvePendingBatch(userID, followerIDs, accept)
+	if err != nil {
+		http.Error(w, "Failed to update follow requests", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	for _, followerID := range processed {
+		if accept {
+			notifyUser(h.NotificationRepository, followerID, "follow_accepted",
+				user.Username+" accepted your follow request", userID, "user")
+			pushFollowRequestUpdate(followerID, userID, "accepted")
+		} else {
+			pushFollowRequestUpdate(followerID, userID, "declined")
+		}
+	}
+	return processed, notFound, true
+}
+
+// CancelFollowRequest withdraws a request sent by the current user.
+func (h *FollowerHandler) CancelFollowRequest(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	followedID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	done, err := h.FollowerRepository.DeletePending(userID, followedID)
+	if err != nil {
+		http.Error(w, "Failed to cancel follow request", http.StatusInternalServerError)
+		return
+	}
+	if !done {
+		http.Error(w, "Follow request not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.NotificationRepository.DeleteFollowRequestFromUser(followedID, userID); err != nil {
+		http.Error(w, "Failed to delete friend request", http.StatusInternalServerError)
+		return
+	}
+	pushFollowRequestUpdate(userID, followedID, "cancelled")
+
+	w.WriteHeader(http.StatusNoContent)
+}
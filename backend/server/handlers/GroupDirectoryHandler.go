@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"social-network/backend/app/services"
+	"social-network/backend/server/middlewares"
+)
+
+// GroupDirectoryHandler handles the discovery directory of the groups.
+type GroupDirectoryHandler struct {
+	Directory *services.GroupDirectoryService
+}
+
+// NewGroupDirectoryHandler creates a new GroupDirectoryHandler.
+func NewGroupDirectoryHandler(ds *services.GroupDirectoryService) *GroupDirectoryHandler {
+	return &GroupDirectoryHandler{Directory: ds}
+}
+
+// writeGroupProfileError answers an invalid group profile.
+func writeGroupProfileError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidGroupCategory, services.ErrInvalidGroupTags:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeGroupError(w, err)
+	}
+}
+
+// DiscoverGroups lists a page of the groups the current user can find.
+func (h *GroupDirectoryHandler) DiscoverGroups(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	query := services.DirectoryQuery{
+		Search:   params.Get("q"),
+		Category: params.Get("category"),
+		Tag:      params.Get("tag"),
+		Sort:     params.Get("sort"),
+	}
+	query.Limit, query.Offset = pageParams(r, 20, 100)
+
+	page, err := h.Directory.Discover(userID, query)
+	if err == services.ErrInvalidDirectorySort || err == services.ErrInvalidGroupTags {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to retrieve groups", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// RecommendGroups lists the groups the friends of the current user belong to.
+func (h *GroupDirectoryHandler) RecommendGroups(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	limit, _ := pageParams(r, 10, 50)
+	groups, err := h.Directory.Recommend(userID, limit)
+	if err != nil {
+		http.Error(w, "Failed to retrieve recommended groups", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
+
+// GetGroupCategories lists the categories a group can have.
+func (h *GroupDirectoryHandler) GetGroupCategories(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services.GroupCategories)
+}
+
+// UpdateGroupProfile changes the category, cover and tags of the group.
+func (h *GroupDirectoryHandler) UpdateGroupProfile(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	var profile services.GroupProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Directory.Groups.UpdateProfile(groupID, userID, profile); err != nil {
+		writeGroupProfileError(w, err)
+		return
+	}
+
+	tags, err := h.Directory.Groups.Tags(groupID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve group tags", http.StatusInternalServerError)
+		return
+	}
+	profile.Tags = tags
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
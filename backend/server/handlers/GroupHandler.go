@@ -3,16 +3,18 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/gorilla/websocket"
 
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 	repository "social-network/backend/database/repositories"
 	"social-network/backend/server/middlewares"
+	"social-network/backend/websocket"
 )
 
 // GroupHandler handles HTTP requests for groups.
@@ -21,33 +23,52 @@ type GroupHandler struct {
 	SessionRepository      *repository.SessionRepository
 	UserRepository         *repository.UserRepository
 	NotificationRepository *repository.NotificationRepository
+	ContentFilter          *services.ContentFilterService
+	PostRepository         *repository.PostRepository
+	PostService            *services.PostService
+	Comments               *CommentHandler // group comments are regular comments
+	GroupService           *services.GroupService
 }
 
 // NewGroupHandler creates a new GroupHandler.
-func NewGroupHandler(gr *repository.GroupRepository, sr *repository.SessionRepository, ur *repository.UserRepository, nr *repository.NotificationRepository) *GroupHandler {
+func NewGroupHandler(gr *repository.GroupRepository, sr *repository.SessionRepository, ur *repository.UserRepository, nr *repository.NotificationRepository, cf *services.ContentFilterService, pr *repository.PostRepository, ps *services.PostService, ch *CommentHandler, gs *services.GroupService) *GroupHandler {
 	return &GroupHandler{
 		GroupRepository:        gr,
 		SessionRepository:      sr,
 		UserRepository:         ur,
 		NotificationRepository: nr,
+		ContentFilter:          cf,
+		PostRepository:         pr,
+		PostService:            ps,
+		Comments:               ch,
+		GroupService:           gs,
 	}
 }
 
 // Request DTOs
 type CreateGroupRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Visibility  string   `json:"visibility"` // "public", "private" or "secret", private by default
+	Category    string   `json:"category"`
+	CoverPath   string   `json:"cover_path"`
+	Tags        []string `json:"tags"`
 }
 
 // Response DTOs
 type GroupResponse struct {
-	ID          int64  `json:"id"`
-	CreatorID   int64  `json:"creator_id"`
-	CreatorName string `json:"creator_name"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	ID          int64    `json:"id"`
+	CreatorID   int64    `json:"creator_id"`
+	CreatorName string   `json:"creator_name"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Visibility  string   `json:"visibility"`
+	Category    string   `json:"category"`
+	CoverPath   string   `json:"cover_path"`
+	Tags        []string `json:"tags,omitempty"`
+	ArchivedAt  string   `json:"archived_at,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
 }
 
 // Helper function to get username by userID
@@ -87,30 +108,41 @@ func (h *GroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Title is required", http.StatusBadRequest)
 		return
 	}
+	if req.Visibility == "" {
+		req.Visibility = services.GroupPrivate
+	}
+	if !services.ValidGroupVisibility(req.Visibility) {
+		http.Error(w, services.ErrInvalidGroupVisibility.Error(), http.StatusBadRequest)
+		return
+	}
+	if !services.ValidGroupCategory(req.Category) {
+		http.Error(w, services.ErrInvalidGroupCategory.Error(), http.StatusBadRequest)
+		return
+	}
+	tags, err := services.NormalizeGroupTags(req.Tags)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	group := &models.Group{
 		CreatorID:   userID,
 		CreatorName: userName,
 		Title:       req.Title,
 		Description: &req.Description,
+		Visibility:  req.Visibility,
+		Category:    req.Category,
+		CoverPath:   req.CoverPath,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	id, err := h.GroupRepository.Create(group)
-	if err != nil {
+	profile := services.GroupProfile{Category: req.Category, CoverPath: req.CoverPath, Tags: tags}
+	if err := h.GroupService.CreateGroup(group, profile); err != nil {
 		http.Error(w, "Failed to create group: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = h.GroupRepository.AddMember(group.ID, userID, userName, true, time.Now())
-	if err != nil {
-		http.Error(w, "Failed to add group creator as member: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	group.ID = id
-
 	response := GroupResponse{
 		ID:          group.ID,
 		CreatorID:   group.CreatorID,
@@ -122,9 +154,13 @@ func (h *GroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
 			}
 			return ""
 		}(),
-		CreatedAt: group.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: group.UpdatedAt.Format(time.RFC3339),
+		Visibility: group.Visibility,
+		Category:   group.Category,
+		CoverPath:  group.CoverPath,
+		CreatedAt:  group.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  group.UpdatedAt.Format(time.RFC3339),
 	}
+	response.Tags = tags
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -156,8 +192,11 @@ func (h *GroupHandler) GetGroupsByUserID(w http.ResponseWriter, r *http.Request)
 				}
 				return ""
 			}(),
-			CreatedAt: group.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: group.UpdatedAt.Format(time.RFC3339),
+			Visibility: group.Visibility,
+			Category:   group.Category,
+			CoverPath:  group.CoverPath,
+			CreatedAt:  group.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:  group.UpdatedAt.Format(time.RFC3339),
 		})
 	}
 
@@ -179,12 +218,18 @@ func (h *GroupHandler) GetGroupByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
 	group, err := h.GroupRepository.GetGroupByID(groupID)
 	if err != nil {
 		http.Error(w, "Failed to retrieve group: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	if group == nil {
+	if group == nil || !h.GroupService.CanSee(groupID, userID) {
 		http.Error(w, "Group not found", http.StatusNotFound)
 		return
 	}
@@ -200,8 +245,18 @@ func (h *GroupHandler) GetGroupByID(w http.ResponseWriter, r *http.Request) {
 			}
 			return ""
 		}(),
-		CreatedAt: group.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: group.UpdatedAt.Format(time.RFC3339),
+		Visibility: group.Visibility,
+		Category:   group.Category,
+		CoverPath:  group.CoverPath,
+		CreatedAt:  group.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  group.UpdatedAt.Format(time.RFC3339),
+	}
+	if group.ArchivedAt != nil {
+		response.ArchivedAt = group.ArchivedAt.Format(time.RFC3339)
+	}
+	if response.Tags, err = h.GroupService.Tags(groupID); err != nil {
+		http.Error(w, "Failed to retrieve group tags", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -222,6 +277,15 @@ func (h *GroupHandler) GetMembersByGroupID(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+	if !groupAccess(h.GroupService, w, groupID, userID, false) {
+		return
+	}
+
 	members, err := h.GroupRepository.GetMembersByGroupID(groupID)
 	if err != nil {
 		http.Error(w, "Failed to retrieve group members: "+err.Error(), http.StatusInternalServerError)
@@ -256,7 +320,27 @@ func (h *GroupHandler) AddMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = h.GroupRepository.CreateGroupInvitation(groupID, payload.CurrentUserID, payload.UserID)
+	inviterID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+	if !h.GroupService.Can(groupID, inviterID, services.PermInvite) {
+		http.Error(w, "Not allowed to invite members", http.StatusForbidden)
+		return
+	}
+
+	// Un utilisateur bloqué ne peut pas être invité, ni inviter
+	if h.PostService.Blocks.IsBlocked(inviterID, payload.UserID) {
+		http.Error(w, "Cannot invite this user", http.StatusForbidden)
+		return
+	}
+	if h.GroupService.IsBanned(groupID, payload.UserID) {
+		http.Error(w, services.ErrGroupBanned.Error(), http.StatusForbidden)
+		return
+	}
+
+	_, err = h.GroupRepository.CreateGroupInvitation(groupID, inviterID, payload.UserID)
 	if err != nil {
 		http.Error(w, "Failed to add member: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -285,6 +369,14 @@ func (h *GroupHandler) CreateGroupMessage(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if !groupAccess(h.GroupService, w, groupID, userID, true) {
+		return
+	}
+	if !h.GroupService.HasAcceptedRules(groupID, userID) {
+		writeGroupError(w, services.ErrGroupRulesNotAccepted)
+		return
+	}
+
 	userName, err := h.getUsernameByID(userID)
 	if err != nil {
 		http.Error(w, "Failed to get user information: "+err.Error(), http.StatusInternalServerError)
@@ -297,9 +389,16 @@ func (h *GroupHandler) CreateGroupMessage(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	outcome, err := h.ContentFilter.Run(userID, services.ContentGroupMessage, message.Content)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
 	message.GroupID = groupID
 	message.UserID = userID
 	message.Username = userName
+	message.Content = outcome.Content
 	message.CreatedAt = time.Now()
 	message.UpdatedAt = time.Now()
 
@@ -309,8 +408,9 @@ func (h *GroupHandler) CreateGroupMessage(w http.ResponseWriter, r *http.Request
 		return
 	}
 	message.ID = id
+	h.ContentFilter.FlagForReview(services.ContentGroupMessage, id, outcome)
 
-	go BroadcastToGroupClients(message.GroupID, message)
+	websocket.GlobalHub.BroadcastToGroup(message.GroupID, "group_message", message)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(message)
@@ -326,6 +426,15 @@ func (h *GroupHandler) GetGroupMessages(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+	if !groupAccess(h.GroupService, w, groupID, userID, false) {
+		return
+	}
+
 	messages, err := h.GroupRepository.GetMessagesByGroupID(groupID)
 	if err != nil {
 		http.Error(w, "Failed to get messages: "+err.Error(), http.StatusInternalServerError)
@@ -336,11 +445,19 @@ func (h *GroupHandler) GetGroupMessages(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(messages)
 }
 
-var groupClients = make(map[int64]map[*websocket.Conn]bool)
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+type createGroupPostRequest struct {
+	Content        string  `json:"content"`
+	ImagePath      *string `json:"image_path,omitempty"`
+	IsAnnouncement bool    `json:"is_announcement"`
 }
 
+type createGroupCommentRequest struct {
+	Content   string  `json:"content"`
+	ParentID  *int64  `json:"parent_id,omitempty"`
+	ImagePath *string `json:"image_path,omitempty"`
+}
+
+// CreateGroupPost publishes a post whose audience is the group members.
 func (h *GroupHandler) CreateGroupPost(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	groupIDStr, ok := vars["id"]
@@ -361,36 +478,77 @@ func (h *GroupHandler) CreateGroupPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userName, err := h.getUsernameByID(userID)
-	if err != nil {
-		http.Error(w, "Failed to get user information: "+err.Error(), http.StatusInternalServerError)
+	if !groupAccess(h.GroupService, w, groupID, userID, true) {
+		return
+	}
+	if !h.GroupService.HasAcceptedRules(groupID, userID) {
+		writeGroupError(w, services.ErrGroupRulesNotAccepted)
 		return
 	}
 
-	var post models.GroupPost
-	if err := json.NewDecoder(r.Body).Decode(&post); err != nil {
+	var req createGroupPostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if req.IsAnnouncement && !h.GroupService.Can(groupID, userID, services.PermAnnounce) {
+		http.Error(w, "Not allowed to post announcements", http.StatusForbidden)
+		return
+	}
+
+	outcome, err := h.ContentFilter.Run(userID, services.ContentPost, req.Content)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
 
-	post.GroupID = groupID
-	post.UserID = userID
-	post.Username = userName
-	post.CreatedAt = time.Now()
-	post.UpdatedAt = time.Now()
-	post.CommentsCount = 0
+	now := time.Now()
+	post := &models.Post{
+		UserID:      userID,
+		Content:     outcome.Content,
+		ImagePath:   req.ImagePath,
+		PrivacyType: 2, // l'audience est portée par GroupID
+		GroupID:     &groupID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
 
-	id, err := h.GroupRepository.CreateGroupPost(&post)
+	id, err := h.PostRepository.Create(post)
 	if err != nil {
 		http.Error(w, "Failed to create group post: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	post.ID = id
+	h.ContentFilter.FlagForReview(services.ContentPost, id, outcome)
+
+	// Les aperçus de liens sont récupérés en arrière-plan
+	go h.PostService.LinkPreviews.Unfurl(post.Content)
+
+	if req.IsAnnouncement {
+		if _, err := h.GroupService.SetAnnouncement(groupID, userID, id, true); err != nil {
+			http.Error(w, "Failed to mark the post as an announcement", http.StatusInternalServerError)
+			return
+		}
+		post.IsAnnouncement = true
+		h.notifyAnnouncement(groupID, post)
+	}
+
+	user, _ := h.PostService.GetPostAuthor(post)
+
+	websocket.GlobalHub.BroadcastToGroup(groupID, "group_post_created", map[string]any{
+		"post": post,
+		"user": user,
+	})
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(post)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"post": post,
+		"user": user,
+	})
 }
 
+// GetPostsByGroupID lists the group posts, through the same visibility rules
+// as the main feed.
 func (h *GroupHandler) GetPostsByGroupID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	groupIDStr, ok := vars["id"]
@@ -405,7 +563,23 @@ func (h *GroupHandler) GetPostsByGroupID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	posts, err := h.GroupRepository.GetPostsByGroupID(groupID)
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	if !groupAccess(h.GroupService, w, groupID, userID, false) {
+		return
+	}
+
+	user, err := h.UserRepository.GetByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to get user information: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	posts, err := h.PostRepository.GetGroupPosts(groupID, h.PostService, user)
 	if err != nil {
 		http.Error(w, "Failed to retrieve group posts: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -415,142 +589,127 @@ func (h *GroupHandler) GetPostsByGroupID(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(posts)
 }
 
-func (h *GroupHandler) CreateGroupComment(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	groupPostIDStr, ok := vars["postID"]
+// groupPost loads the post of the path, checking it belongs to the group of
+// the path and that the user can see it.
+func (h *GroupHandler) groupPost(w http.ResponseWriter, r *http.Request) (*models.Post, int64, bool) {
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
 	if !ok {
-		http.Error(w, "Missing group post ID in path", http.StatusBadRequest)
-		return
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return nil, 0, false
 	}
 
-	groupPostID, err := strconv.ParseInt(groupPostIDStr, 10, 64)
+	groupID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, 0, false
+	}
+	postID, err := parseIDVar(r, "postID")
 	if err != nil {
 		http.Error(w, "Invalid group post ID", http.StatusBadRequest)
-		return
+		return nil, 0, false
 	}
 
-	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	post, err := h.PostRepository.GetPostById(postID)
+	if err != nil || post == nil || post.GroupID == nil || *post.GroupID != groupID || !h.PostService.CanView(post, userID) {
+		http.Error(w, "Group post not found", http.StatusNotFound)
+		return nil, 0, false
+	}
+	return post, userID, true
+}
+
+// groupComment loads the comment of the path, checking it belongs to the post
+// and that its author did not block the user, nor the reverse.
+func (h *GroupHandler) groupComment(w http.ResponseWriter, r *http.Request, post *models.Post, userID int64) (*models.Comment, bool) {
+	commentID, err := parseIDVar(r, "commentID")
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	comment, err := h.Comments.CommentRepository.GetByID(commentID)
+	if err != nil || comment.PostID != post.ID || h.Comments.isBlocked(comment.UserID, userID) {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return nil, false
+	}
+	return comment, true
+}
+
+// CreateGroupComment comments a group post, see CommentHandler.CreateComment.
+func (h *GroupHandler) CreateGroupComment(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPost(w, r)
 	if !ok {
-		http.Error(w, "User not authenticated", http.StatusUnauthorized)
 		return
 	}
 
-	userName, err := h.getUsernameByID(userID)
+	user, err := h.UserRepository.GetByID(userID)
 	if err != nil {
 		http.Error(w, "Failed to get user information: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	var comment models.GroupComment
-	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+	var req createGroupCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	comment.GroupPostID = groupPostID
-	comment.UserID = userID
-	comment.Username = userName
-	comment.CreatedAt = time.Now()
-	comment.UpdatedAt = time.Now()
-
-	id, err := h.GroupRepository.CreateGroupComment(&comment)
-	if err != nil {
-		http.Error(w, "Failed to create group comment: "+err.Error(), http.StatusInternalServerError)
-		return
+	if comment := h.Comments.addComment(w, user, post.ID, req.ParentID, req.Content, req.ImagePath); comment != nil {
+		websocket.GlobalHub.BroadcastToGroup(*post.GroupID, "group_comment_created", comment)
 	}
-	comment.ID = id
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(comment)
 }
 
+// GetCommentsByGroupPostID returns a page of the comments of a group post.
 func (h *GroupHandler) GetCommentsByGroupPostID(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	groupPostIDStr, ok := vars["postID"]
+	post, userID, ok := h.groupPost(w, r)
 	if !ok {
-		http.Error(w, "Missing group post ID in path", http.StatusBadRequest)
 		return
 	}
 
-	groupPostID, err := strconv.ParseInt(groupPostIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid group post ID", http.StatusBadRequest)
-		return
-	}
+	h.Comments.writeCommentPage(w, post.ID, commentPageFromQuery(r, userID))
+}
 
-	comments, err := h.GroupRepository.GetCommentsByPostID(groupPostID)
-	if err != nil {
-		http.Error(w, "Failed to retrieve group comments: "+err.Error(), http.StatusInternalServerError)
+// CountGroupComments returns the number of visible comments of a group post.
+func (h *GroupHandler) CountGroupComments(w http.ResponseWriter, r *http.Request) {
+	post, _, ok := h.groupPost(w, r)
+	if !ok {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(comments)
+	h.Comments.writeCommentCount(w, post.ID)
 }
 
-func HandleGroupWebSocket(w http.ResponseWriter, r *http.Request) {
-	groupIDStr := r.URL.Query().Get("groupId")
-	groupID, err := strconv.ParseInt(groupIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+// LikeGroupComment adds or removes the like of the current user on a group comment.
+func (h *GroupHandler) LikeGroupComment(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPost(w, r)
+	if !ok {
 		return
 	}
-
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		fmt.Println("Error upgrade WebSocket :", err)
+	comment, ok := h.groupComment(w, r, post, userID)
+	if !ok {
 		return
 	}
 
-	if groupClients[groupID] == nil {
-		groupClients[groupID] = make(map[*websocket.Conn]bool)
-	}
-	groupClients[groupID][conn] = true
-
-	go func() {
-		defer func() {
-			conn.Close()
-			delete(groupClients[groupID], conn)
-		}()
-
-		for {
-			var msg map[string]interface{}
-			err := conn.ReadJSON(&msg)
-			if err != nil {
-				fmt.Println("Error read WS:", err)
-				break
-			}
-
-			for c := range groupClients[groupID] {
-				if c != conn {
-					c.WriteJSON(msg)
-				}
-			}
-		}
-	}()
+	h.Comments.toggleLike(w, comment, userID)
 }
 
-func BroadcastToGroupClients(groupID int64, message interface{}) {
-	clients, ok := groupClients[groupID]
+// GetGroupCommentReplies returns a page of the replies of a group comment.
+func (h *GroupHandler) GetGroupCommentReplies(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPost(w, r)
 	if !ok {
 		return
 	}
-
-	for c := range clients {
-		err := c.WriteJSON(message)
-		if err != nil {
-			fmt.Println("Error broadcast WebSocket:", err)
-			c.Close()
-			delete(clients, c)
-		}
+	parent, ok := h.groupComment(w, r, post, userID)
+	if !ok {
+		return
 	}
+
+	h.Comments.writeReplies(w, r, parent, userID)
 }
 
+// AcceptGroupInvitation makes the current user join a group they were invited to.
 func (h *GroupHandler) AcceptGroupInvitation(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		GroupID       int64  `json:"group_id"`
-		UserID        int64  `json:"current_user"`
-		ReferenceType string `json:"reference_type"`
+		GroupID int64 `json:"group_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
@@ -558,55 +717,58 @@ func (h *GroupHandler) AcceptGroupInvitation(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	fmt.Println("Accepting group invitation for user:", payload.UserID, "to group:", payload.GroupID)
-
-	userName, err := h.getUsernameByID(payload.UserID)
-	if err != nil {
-		http.Error(w, "Failed to get user information: "+err.Error(), http.StatusInternalServerError)
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
 		return
 	}
 
-	err = h.GroupRepository.AddMember(payload.GroupID, payload.UserID, userName, true, time.Now())
+	invited, err := h.GroupRepository.HasInvitation(userID, payload.GroupID)
 	if err != nil {
-		http.Error(w, "Failed to add group member: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to check group invitation: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !invited {
+		http.Error(w, "Invitation not found", http.StatusNotFound)
+		return
+	}
+	if h.GroupService.IsBanned(payload.GroupID, userID) {
+		http.Error(w, services.ErrGroupBanned.Error(), http.StatusForbidden)
 		return
 	}
 
-	err = h.GroupRepository.DeleteInvitation(payload.UserID, payload.GroupID)
+	userName, err := h.getUsernameByID(userID)
 	if err != nil {
-		http.Error(w, "Failed to delete group invitation: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to get user information: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = h.NotificationRepository.DeleteGroupInvitationRequest(payload.UserID, payload.GroupID)
-	if err != nil {
-		http.Error(w, "Failed to delete group invitation: "+err.Error(), http.StatusInternalServerError)
+	if err := h.GroupService.AcceptInvitation(payload.GroupID, userID, userName); err != nil {
+		http.Error(w, "Failed to accept group invitation: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// DeclineGroupInvitation removes the invitation of the current user to a group.
 func (h *GroupHandler) DeclineGroupInvitation(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		GroupID       int64  `json:"group_id"`
-		UserID        int64  `json:"current_user"`
-		ReferenceType string `json:"reference_type"`
+		GroupID int64 `json:"group_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
 		return
 	}
 
-	err := h.GroupRepository.DeleteInvitation(payload.UserID, payload.GroupID)
-	if err != nil {
-		http.Error(w, "Failed to decline group invitation: "+err.Error(), http.StatusInternalServerError)
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
 		return
 	}
 
-	err = h.NotificationRepository.DeleteGroupInvitationRequest(payload.UserID, payload.GroupID)
-	if err != nil {
-		http.Error(w, "Failed to delete group invitation: "+err.Error(), http.StatusInternalServerError)
+	if err := h.GroupService.DeclineInvitation(payload.GroupID, userID); err != nil {
+		http.Error(w, "Failed to decline group invitation: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -633,6 +795,11 @@ func (h *GroupHandler) CheckMembership(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.GroupService.CanSee(groupID, userID) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
 	isMember, err := h.GroupRepository.IsMember(groupID, userID)
 	if err != nil {
 		http.Error(w, "Failed to check membership: "+err.Error(), http.StatusInternalServerError)
@@ -646,8 +813,16 @@ func (h *GroupHandler) CheckMembership(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// CheckInvitationStatus checks if a user has a pending group invitation
+// CheckInvitationStatus checks if a user has a pending group invitation. The
+// current user by default; asking about someone else takes the right to read
+// the group, like its member list.
 func (h *GroupHandler) CheckInvitationStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
 	var req struct {
 		GroupID int64 `json:"group_id"`
 		UserID  int64 `json:"user_id"`
@@ -656,6 +831,17 @@ func (h *GroupHandler) CheckInvitationStatus(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if req.UserID == 0 {
+		req.UserID = userID
+	}
+	if req.UserID == userID {
+		if !h.GroupService.CanSee(req.GroupID, userID) {
+			http.Error(w, "Group not found", http.StatusNotFound)
+			return
+		}
+	} else if !groupAccess(h.GroupService, w, req.GroupID, userID, false) {
+		return
+	}
 
 	hasPendingInvitation, err := h.NotificationRepository.HasPendingGroupInvitation(req.UserID, req.GroupID)
 	if err != nil {
@@ -674,3 +860,1051 @@ func (h *GroupHandler) CheckInvitationStatus(w http.ResponseWriter, r *http.Requ
 		"isMember":             isMember,
 	})
 }
+
+type updateGroupRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility,omitempty"` // unchanged when empty
+}
+
+type setGroupRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type transferOwnershipRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+// writeGroupError answers a failed role or permission change.
+func writeGroupError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInvalidGroupRole, services.ErrInvalidGroupQuestions, services.ErrMissingAnswers:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case services.ErrNotGroupMember:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case services.ErrGroupForbidden, services.ErrLastGroupOwner, services.ErrGroupBanned, services.ErrGroupArchived,
+		services.ErrGroupRulesNotAccepted:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case services.ErrTooManyPinnedPosts, services.ErrGroupRulesChanged:
+		http.Error(w, err.Error(), http.StatusConflict)
+	case services.ErrGroupRestoreExpired:
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		http.Error(w, "Failed to update group", http.StatusInternalServerError)
+	}
+}
+
+// groupAccess checks the user can read the content of the group, or post to
+// it when write is set, and answers the request otherwise. Groups the user
+// cannot see answer like missing ones.
+func groupAccess(gs *services.GroupService, w http.ResponseWriter, groupID, userID int64, write bool) bool {
+	if !gs.CanSee(groupID, userID) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return false
+	}
+	if (write && !gs.IsMember(groupID, userID)) || (!write && !gs.CanRead(groupID, userID)) {
+		http.Error(w, "You are not a member of this group", http.StatusForbidden)
+		return false
+	}
+	if write && gs.IsArchived(groupID) {
+		http.Error(w, services.ErrGroupArchived.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// groupActor returns the group of the path and the current user.
+func groupActor(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return 0, 0, false
+	}
+	groupID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return groupID, userID, true
+}
+
+// UpdateGroup edits the title and description of a group.
+func (h *GroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+	if !h.GroupService.Can(groupID, userID, services.PermEditSettings) {
+		http.Error(w, "Not allowed to edit this group", http.StatusForbidden)
+		return
+	}
+
+	var req updateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Title == "" {
+		http.Error(w, "Title is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Visibility == "" {
+		req.Visibility = h.GroupService.GetVisibility(groupID)
+	}
+
+	if err := h.GroupService.UpdateSettings(groupID, userID, req.Title, req.Description, req.Visibility); err != nil {
+		if err == services.ErrInvalidGroupVisibility {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to update group: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.GetGroupByID(w, r)
+}
+
+// SetMemberRole promotes or demotes a member of the group.
+func (h *GroupHandler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+	targetID, err := parseIDVar(r, "userID")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req setGroupRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.GroupService.SetRole(groupID, userID, targetID, req.Role); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": targetID,
+		"role":    req.Role,
+	})
+}
+
+// TransferOwnership hands the group over to another member.
+func (h *GroupHandler) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req transferOwnershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.GroupService.TransferOwnership(groupID, userID, req.UserID); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ArchiveGroup makes the group read-only.
+func (h *GroupHandler) ArchiveGroup(w http.ResponseWriter, r *http.Request) {
+	h.setGroupArchived(w, r, true)
+}
+
+// UnarchiveGroup opens an archived group again.
+func (h *GroupHandler) UnarchiveGroup(w http.ResponseWriter, r *http.Request) {
+	h.setGroupArchived(w, r, false)
+}
+
+func (h *GroupHandler) setGroupArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	if archived {
+		_, err = h.GroupService.Archive(groupID, userID)
+	} else {
+		_, err = h.GroupService.Unarchive(groupID, userID)
+	}
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteGroup deletes the group. It stays restorable by its owner until the
+// returned restore_until, then it is purged with all its content.
+func (h *GroupHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	restoreUntil, err := h.GroupService.Delete(groupID, userID)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"group_id":      groupID,
+		"restore_until": restoreUntil.Format(time.RFC3339),
+	})
+}
+
+// RestoreGroup brings back a deleted group within its restore window.
+func (h *GroupHandler) RestoreGroup(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.GroupService.Restore(groupID, userID); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeletedGroups lists the deleted groups the current user can restore.
+func (h *GroupHandler) GetDeletedGroups(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	groups, err := h.GroupService.ListDeleted(userID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve deleted groups", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
+
+// DeleteGroupPost removes a group post, by its author or a moderator.
+func (h *GroupHandler) DeleteGroupPost(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPost(w, r)
+	if !ok {
+		return
+	}
+	if post.UserID != userID && !h.GroupService.Can(*post.GroupID, userID, services.PermDeleteContent) {
+		http.Error(w, "Not allowed to delete this post", http.StatusForbidden)
+		return
+	}
+
+	if err := h.GroupService.DeletePost(*post.GroupID, userID, post); err != nil {
+		http.Error(w, "Failed to delete post", http.StatusInternalServerError)
+		return
+	}
+	websocket.GlobalHub.BroadcastToGroup(*post.GroupID, "group_post_deleted", map[string]int64{
+		"post_id": post.ID,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteGroupComment removes a comment of a group post, by its author or a moderator.
+func (h *GroupHandler) DeleteGroupComment(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPost(w, r)
+	if !ok {
+		return
+	}
+	comment, ok := h.groupComment(w, r, post, userID)
+	if !ok {
+		return
+	}
+	if comment.UserID != userID && !h.GroupService.Can(*post.GroupID, userID, services.PermDeleteContent) {
+		http.Error(w, "Not allowed to delete this comment", http.StatusForbidden)
+		return
+	}
+
+	if err := h.GroupService.DeleteComment(*post.GroupID, userID, comment); err != nil {
+		http.Error(w, "Failed to delete comment", http.StatusInternalServerError)
+		return
+	}
+	websocket.GlobalHub.BroadcastToGroup(*post.GroupID, "group_comment_deleted", map[string]int64{
+		"post_id":    post.ID,
+		"comment_id": comment.ID,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type createJoinRequestRequest struct {
+	Message string `json:"message"`
+	Answers []struct {
+		QuestionID int64  `json:"question_id"`
+		Answer     string `json:"answer"`
+	} `json:"answers"`
+}
+
+// CreateJoinRequest asks to join a group. The members allowed to approve it
+// are notified, except in public groups where the request is approved at
+// once. Secret groups can only be joined through an invitation.
+func (h *GroupHandler) CreateJoinRequest(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req createJoinRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	group, err := h.GroupRepository.GetGroupByID(groupID)
+	if err != nil || group == nil || !h.GroupService.CanSee(groupID, userID) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+	if group.Visibility == services.GroupSecret {
+		http.Error(w, "This group is invite-only", http.StatusForbidden)
+		return
+	}
+	if h.GroupService.IsBanned(groupID, userID) {
+		http.Error(w, services.ErrGroupBanned.Error(), http.StatusForbidden)
+		return
+	}
+
+	isMember, err := h.GroupRepository.IsMember(groupID, userID)
+	if err != nil {
+		http.Error(w, "Failed to check membership: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if isMember || h.GroupRepository.IsRequestPending(groupID, userID) != 0 {
+		http.Error(w, "Already a member or request pending", http.StatusConflict)
+		return
+	}
+
+	answers := map[int64]string{}
+	for _, a := range req.Answers {
+		answers[a.QuestionID] = a.Answer
+	}
+	checked, err := h.GroupService.CheckAnswers(groupID, answers)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	userName, err := h.getUsernameByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to get user information: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id, err := h.GroupService.CreateJoinRequest(groupID, userID, req.Message, checked)
+	if err != nil {
+		http.Error(w, "Failed to create join request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	request, err := h.GroupRepository.GetJoinRequest(id)
+	if err != nil {
+		http.Error(w, "Failed to retrieve join request", http.StatusInternalServerError)
+		return
+	}
+
+	if group.Visibility == services.GroupPublic {
+		if _, err := h.GroupRepository.ApproveJoinRequest(request, userID); err != nil {
+			http.Error(w, "Failed to join group: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		request, err = h.GroupRepository.GetJoinRequest(id)
+		if err != nil {
+			http.Error(w, "Failed to retrieve join request", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		approvers, err := h.GroupService.MembersWith(groupID, services.PermApproveJoin)
+		if err != nil {
+			fmt.Println("Failed to list group approvers:", err)
+		}
+		content := fmt.Sprintf("%s demande à rejoindre le groupe \"%s\".", userName, group.Title)
+		for _, approverID := range approvers {
+			notifyUser(h.NotificationRepository, approverID, "group_request", content, id, "group_join_request")
+		}
+	}
+
+	request.Answers = checked
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(request)
+}
+
+// GetJoinRequests lists the pending join requests of a group.
+func (h *GroupHandler) GetJoinRequests(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+	if !h.GroupService.Can(groupID, userID, services.PermApproveJoin) {
+		http.Error(w, "Not allowed to review join requests", http.StatusForbidden)
+		return
+	}
+
+	requests, err := h.GroupRepository.GetPendingJoinRequests(groupID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve join requests", http.StatusInternalServerError)
+		return
+	}
+	for _, request := range requests {
+		if request.Answers, err = h.GroupService.Answers(request.ID); err != nil {
+			http.Error(w, "Failed to retrieve join request answers", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+// joinRequestDecision loads the join request of the path for a member allowed
+// to approve it.
+func (h *GroupHandler) joinRequestDecision(w http.ResponseWriter, r *http.Request) (*models.GroupJoinRequest, int64, bool) {
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return nil, 0, false
+	}
+	requestID, err := parseIDVar(r, "requestID")
+	if err != nil {
+		http.Error(w, "Invalid request ID", http.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	request, err := h.GroupRepository.GetJoinRequest(requestID)
+	if err != nil || request.Status != "pending" {
+		http.Error(w, "Join request not found", http.StatusNotFound)
+		return nil, 0, false
+	}
+	if !h.GroupService.Can(request.GroupID, userID, services.PermApproveJoin) {
+		http.Error(w, "Not allowed to review join requests", http.StatusForbidden)
+		return nil, 0, false
+	}
+	return request, userID, true
+}
+
+// closeJoinRequest removes the notifications of a request that is no longer
+// pending and tells the requester about the decision.
+func (h *GroupHandler) closeJoinRequest(request *models.GroupJoinRequest, notifType, content string) {
+	if err := h.NotificationRepository.DeleteByReference("group_request", "group_join_request", request.ID); err != nil {
+		fmt.Println("Failed to delete join request notifications:", err)
+	}
+	if notifType != "" {
+		notifyUser(h.NotificationRepository, request.RequesterID, notifType, content, request.GroupID, "group")
+	}
+}
+
+// ApproveJoinRequest adds the requester to the group.
+func (h *GroupHandler) ApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	request, userID, ok := h.joinRequestDecision(w, r)
+	if !ok {
+		return
+	}
+
+	approved, err := h.GroupRepository.ApproveJoinRequest(request, userID)
+	if err != nil {
+		http.Error(w, "Failed to approve join request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !approved {
+		http.Error(w, "Join request not found", http.StatusNotFound)
+		return
+	}
+
+	_, title, _ := h.GroupRepository.GetGroupInfos(request.GroupID)
+	h.closeJoinRequest(request, "group_request_approved",
+		fmt.Sprintf("Votre demande pour rejoindre le groupe \"%s\" a été acceptée.", title))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RejectJoinRequest declines a join request.
+func (h *GroupHandler) RejectJoinRequest(w http.ResponseWriter, r *http.Request) {
+	request, userID, ok := h.joinRequestDecision(w, r)
+	if !ok {
+		return
+	}
+
+	rejected, err := h.GroupRepository.RejectJoinRequest(request, userID)
+	if err != nil {
+		http.Error(w, "Failed to reject join request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !rejected {
+		http.Error(w, "Join request not found", http.StatusNotFound)
+		return
+	}
+
+	_, title, _ := h.GroupRepository.GetGroupInfos(request.GroupID)
+	h.closeJoinRequest(request, "group_request_rejected",
+		fmt.Sprintf("Votre demande pour rejoindre le groupe \"%s\" a été refusée.", title))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WithdrawJoinRequest cancels a pending request of the current user.
+func (h *GroupHandler) WithdrawJoinRequest(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middlewares.UserIDKey).(int64)
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+	requestID, err := parseIDVar(r, "requestID")
+	if err != nil {
+		http.Error(w, "Invalid request ID", http.StatusBadRequest)
+		return
+	}
+
+	request, err := h.GroupRepository.GetJoinRequest(requestID)
+	if err != nil {
+		http.Error(w, "Join request not found", http.StatusNotFound)
+		return
+	}
+
+	withdrawn, err := h.GroupRepository.WithdrawJoinRequest(requestID, userID)
+	if err != nil {
+		http.Error(w, "Failed to withdraw join request: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !withdrawn {
+		http.Error(w, "Join request not found", http.StatusNotFound)
+		return
+	}
+
+	h.closeJoinRequest(request, "", "")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type removeMemberRequest struct {
+	Reason string `json:"reason"`
+}
+
+type banMemberRequest struct {
+	UserID int64  `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// notifyRemoval tells a user they were removed or banned from a group.
+func (h *GroupHandler) notifyRemoval(groupID, userID int64, notifType, format, reason string) {
+	_, title, err := h.GroupRepository.GetGroupInfos(groupID)
+	if err != nil {
+		return
+	}
+	content := fmt.Sprintf(format, title)
+	if reason != "" {
+		content += " Raison : " + reason
+	}
+	notifyUser(h.NotificationRepository, userID, notifType, content, groupID, "group")
+}
+
+// notifyPromotions tells the users who got the seats of a removed member.
+func (h *GroupHandler) notifyPromotions(promotions []*services.WaitlistPromotion) {
+	for _, promotion := range promotions {
+		notifyPromotion(h.NotificationRepository, promotion)
+	}
+}
+
+// LeaveGroup removes the current user from the group.
+func (h *GroupHandler) LeaveGroup(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	promotions, err := h.GroupService.Leave(groupID, userID)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	h.notifyPromotions(promotions)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember kicks a member out of the group, with an optional reason.
+func (h *GroupHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+	targetID, err := parseIDVar(r, "userID")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req removeMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	promotions, err := h.GroupService.RemoveMember(groupID, userID, targetID, req.Reason)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	h.notifyPromotions(promotions)
+
+	h.notifyRemoval(groupID, targetID, "group_removed", "Vous avez été retiré du groupe \"%s\".", req.Reason)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBans lists the users banned from the group.
+func (h *GroupHandler) GetBans(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+	if !h.GroupService.Can(groupID, userID, services.PermRemoveMember) {
+		http.Error(w, "Not allowed to manage bans", http.StatusForbidden)
+		return
+	}
+
+	bans, err := h.GroupService.ListBans(groupID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve bans", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bans)
+}
+
+// BanMember bans a user from the group, removing them if they are a member.
+func (h *GroupHandler) BanMember(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req banMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.UserRepository.GetByID(req.UserID); err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	promotions, err := h.GroupService.Ban(groupID, userID, req.UserID, req.Reason)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	h.notifyPromotions(promotions)
+
+	h.notifyRemoval(groupID, req.UserID, "group_banned", "Vous avez été banni du groupe \"%s\".", req.Reason)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnbanMember lifts a ban.
+func (h *GroupHandler) UnbanMember(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+	targetID, err := parseIDVar(r, "userID")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	unbanned, err := h.GroupService.Unban(groupID, userID, targetID)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	if !unbanned {
+		http.Error(w, "Ban not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notifyAnnouncement tells every member but the author about an announcement.
+// Announcements go through even when the member muted the author.
+func (h *GroupHandler) notifyAnnouncement(groupID int64, post *models.Post) {
+	_, title, err := h.GroupRepository.GetGroupInfos(groupID)
+	if err != nil {
+		return
+	}
+	memberIDs, err := h.GroupService.MemberIDs(groupID)
+	if err != nil {
+		fmt.Println("Failed to list group members:", err)
+		return
+	}
+
+	content := fmt.Sprintf("Nouvelle annonce dans le groupe \"%s\".", title)
+	for _, memberID := range memberIDs {
+		if memberID != post.UserID {
+			notifyUser(h.NotificationRepository, memberID, "group_announcement", content, post.ID, "post")
+		}
+	}
+}
+
+// groupPostManager loads the group post of the path, if the current user's
+// role grants the permission.
+func (h *GroupHandler) groupPostManager(w http.ResponseWriter, r *http.Request, perm services.GroupPermission) (*models.Post, int64, bool) {
+	post, userID, ok := h.groupPost(w, r)
+	if !ok {
+		return nil, 0, false
+	}
+	if !h.GroupService.Can(*post.GroupID, userID, perm) {
+		http.Error(w, "Not allowed in this group", http.StatusForbidden)
+		return nil, 0, false
+	}
+	return post, userID, true
+}
+
+// broadcastPostUpdate pushes the pin and announcement state of a post to the group.
+func broadcastPostUpdate(post *models.Post, pinned, announcement bool) {
+	websocket.GlobalHub.BroadcastToGroup(*post.GroupID, "group_post_updated", map[string]any{
+		"post_id":         post.ID,
+		"pinned":          pinned,
+		"is_announcement": announcement,
+	})
+}
+
+// PinGroupPost pins a post on top of the group posts.
+func (h *GroupHandler) PinGroupPost(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPostManager(w, r, services.PermPinPosts)
+	if !ok {
+		return
+	}
+
+	if err := h.GroupService.PinPost(*post.GroupID, userID, post.ID); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	broadcastPostUpdate(post, true, post.IsAnnouncement)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnpinGroupPost puts a pinned post back in its place.
+func (h *GroupHandler) UnpinGroupPost(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPostManager(w, r, services.PermPinPosts)
+	if !ok {
+		return
+	}
+
+	if err := h.GroupService.UnpinPost(*post.GroupID, userID, post.ID); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	broadcastPostUpdate(post, false, post.IsAnnouncement)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkGroupAnnouncement turns a post into an announcement and notifies every member.
+func (h *GroupHandler) MarkGroupAnnouncement(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPostManager(w, r, services.PermAnnounce)
+	if !ok {
+		return
+	}
+
+	changed, err := h.GroupService.SetAnnouncement(*post.GroupID, userID, post.ID, true)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	if changed {
+		h.notifyAnnouncement(*post.GroupID, post)
+		broadcastPostUpdate(post, post.PinnedAt != nil, true)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnmarkGroupAnnouncement turns an announcement back into a regular post.
+func (h *GroupHandler) UnmarkGroupAnnouncement(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPostManager(w, r, services.PermAnnounce)
+	if !ok {
+		return
+	}
+
+	changed, err := h.GroupService.SetAnnouncement(*post.GroupID, userID, post.ID, false)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	if changed {
+		broadcastPostUpdate(post, post.PinnedAt != nil, false)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AcknowledgeAnnouncement records that the current member has read an announcement.
+func (h *GroupHandler) AcknowledgeAnnouncement(w http.ResponseWriter, r *http.Request) {
+	post, userID, ok := h.groupPost(w, r)
+	if !ok {
+		return
+	}
+	// Lire une annonce ne modifie pas le groupe, même archivé, mais seuls ses
+	// membres en accusent réception
+	if !groupAccess(h.GroupService, w, *post.GroupID, userID, false) {
+		return
+	}
+	if !h.GroupService.IsMember(*post.GroupID, userID) {
+		http.Error(w, "You are not a member of this group", http.StatusForbidden)
+		return
+	}
+	if !post.IsAnnouncement {
+		http.Error(w, "This post is not an announcement", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.GroupService.Acknowledge(post.ID, userID); err != nil {
+		http.Error(w, "Failed to acknowledge announcement", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAnnouncementAcknowledgements lists who has read an announcement.
+func (h *GroupHandler) GetAnnouncementAcknowledgements(w http.ResponseWriter, r *http.Request) {
+	post, _, ok := h.groupPost(w, r)
+	if !ok {
+		return
+	}
+	if !post.IsAnnouncement {
+		http.Error(w, "This post is not an announcement", http.StatusBadRequest)
+		return
+	}
+
+	acks, err := h.GroupService.Acknowledgements(post.ID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve acknowledgements", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(acks)
+}
+
+// GetAuditLog returns a page of the audit log of the group, filtered by the
+// action, actor_id, target_type, target_id, since and until query parameters.
+func (h *GroupHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+	if !h.GroupService.Can(groupID, userID, services.PermViewAudit) {
+		http.Error(w, "Not allowed to view the audit log", http.StatusForbidden)
+		return
+	}
+
+	params := r.URL.Query()
+	query := services.AuditLogQuery{
+		Action:     params.Get("action"),
+		TargetType: params.Get("target_type"),
+	}
+	query.Limit, query.Offset = pageParams(r, 50, 200)
+
+	for name, dest := range map[string]*int64{"actor_id": &query.ActorID, "target_id": &query.TargetID} {
+		if value := params.Get(name); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				http.Error(w, "Invalid "+name, http.StatusBadRequest)
+				return
+			}
+			*dest = id
+		}
+	}
+	for name, dest := range map[string]**time.Time{"since": &query.Since, "until": &query.Until} {
+		if value := params.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, "Invalid "+name+", expected an RFC 3339 date", http.StatusBadRequest)
+				return
+			}
+			*dest = &t
+		}
+	}
+
+	page, err := h.GroupService.AuditLog(groupID, query)
+	if err != nil {
+		http.Error(w, "Failed to retrieve the audit log", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+type setGroupQuestionsRequest struct {
+	Questions []*models.GroupQuestion `json:"questions"`
+}
+
+// GetGroupQuestions lists the questions asked to the users requesting to
+// join the group.
+func (h *GroupHandler) GetGroupQuestions(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+	if !h.GroupService.CanSee(groupID, userID) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	questions, err := h.GroupService.Questions(groupID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve group questions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(questions)
+}
+
+// SetGroupQuestions replaces the membership questions of the group.
+func (h *GroupHandler) SetGroupQuestions(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req setGroupQuestionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.GroupService.SetQuestions(groupID, userID, req.Questions); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	h.GetGroupQuestions(w, r)
+}
+
+type setGroupRulesRequest struct {
+	Rules string `json:"rules"`
+}
+
+type acceptGroupRulesRequest struct {
+	Version int `json:"version"`
+}
+
+// GetGroupRules returns the rules of the group and whether the current user
+// has to accept them.
+func (h *GroupHandler) GetGroupRules(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+	if !h.GroupService.CanSee(groupID, userID) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	rules, err := h.GroupService.Rules(groupID, userID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve group rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// SetGroupRules changes the rules of the group. The other members are
+// notified that they must accept the new version.
+func (h *GroupHandler) SetGroupRules(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req setGroupRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	previous, err := h.GroupService.Rules(groupID, userID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve group rules", http.StatusInternalServerError)
+		return
+	}
+	if _, err := h.GroupService.SetRules(groupID, userID, req.Rules); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+	rules, err := h.GroupService.Rules(groupID, userID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve group rules", http.StatusInternalServerError)
+		return
+	}
+
+	if rules.Version != previous.Version && rules.Rules != "" {
+		_, title, err := h.GroupRepository.GetGroupInfos(groupID)
+		if err != nil {
+			fmt.Println("Failed to get group title:", err)
+		}
+		members, err := h.GroupService.MemberIDs(groupID)
+		if err != nil {
+			fmt.Println("Failed to list group members:", err)
+		}
+		content := fmt.Sprintf("Les règles du groupe \"%s\" ont changé, merci de les accepter à nouveau.", title)
+		for _, memberID := range members {
+			if memberID != userID {
+				notifyUser(h.NotificationRepository, memberID, "group_rules_updated", content, groupID, "group")
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// AcceptGroupRules records that the current member accepted the rules.
+func (h *GroupHandler) AcceptGroupRules(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req acceptGroupRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.GroupService.AcceptRules(groupID, userID, req.Version); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	h.GetGroupRules(w, r)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"social-network/backend/app/services"
+	repository "social-network/backend/database/repositories"
+	"social-network/backend/server/middlewares"
+
+	"github.com/gorilla/mux"
+)
+
+// InviteLinkHandler handles the shareable invite links of the groups.
+type InviteLinkHandler struct {
+	InviteLinkService *services.InviteLinkService
+	UserRepository    *repository.UserRepository
+}
+
+// NewInviteLinkHandler creates a new InviteLinkHandler.
+func NewInviteLinkHandler(ils *services.InviteLinkService, ur *repository.UserRepository) *InviteLinkHandler {
+	return &InviteLinkHandler{
+		InviteLinkService: ils,
+		UserRepository:    ur,
+	}
+}
+
+type createInviteLinkRequest struct {
+	Role      string     `json:"role"` // "member" by default
+	MaxUses   *int64     `json:"max_uses,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// writeInviteLinkError answers a failed invite link operation.
+func writeInviteLinkError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrInviteLinkNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case services.ErrInviteLinkExpired:
+		http.Error(w, err.Error(), http.StatusGone)
+	case services.ErrAlreadyGroupMember:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeGroupError(w, err)
+	}
+}
+
+// linkManager returns the group of the path and the current user, if they
+// can manage its invite links.
+func (h *InviteLinkHandler) linkManager(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+	if !h.InviteLinkService.Groups.Can(groupID, userID, services.PermManageLinks) {
+		http.Error(w, "Not allowed to manage invite links", http.StatusForbidden)
+		return 0, 0, false
+	}
+	return groupID, userID, true
+}
+
+// CreateInviteLink generates a new invite link to the group.
+func (h *InviteLinkHandler) CreateInviteLink(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := groupActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req createInviteLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.InviteLinkService.Create(groupID, userID, req.Role, req.MaxUses, req.ExpiresAt)
+	if err == services.ErrInviteLinkExpired {
+		http.Error(w, "max_uses must be positive and expires_at in the future", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeInviteLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(link)
+}
+
+// GetInviteLinks lists the invite links of the group, revoked ones included.
+func (h *InviteLinkHandler) GetInviteLinks(w http.ResponseWriter, r *http.Request) {
+	groupID, _, ok := h.linkManager(w, r)
+	if !ok {
+		return
+	}
+
+	links, err := h.InviteLinkService.List(groupID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve invite links", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+// RevokeInviteLink disables an invite link of the group.
+func (h *InviteLinkHandler) RevokeInviteLink(w http.ResponseWriter, r *http.Request) {
+	groupID, userID, ok := h.linkManager(w, r)
+	if !ok {
+		return
+	}
+	linkID, err := parseIDVar(r, "linkID")
+	if err != nil {
+		http.Error(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := h.InviteLinkService.Revoke(groupID, userID, linkID)
+	if err != nil {
+		http.Error(w, "Failed to revoke invite link", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, services.ErrInviteLinkNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetInviteLinkUses lists who joined the group through an invite link.
+func (h *InviteLinkHandler) GetInviteLinkUses(w http.ResponseWriter, r *http.Request) {
+	groupID, _, ok := h.linkManager(w, r)
+	if !ok {
+		return
+	}
+	linkID, err := parseIDVar(r, "linkID")
+	if err != nil {
+		http.Error(w, "Invalid link ID", http.StatusBadRequest)
+		return
+	}
+
+	uses, err := h.InviteLinkService.Uses(groupID, linkID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve invite link uses", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uses)
+}
+
+// PreviewInviteLink describes the group an invite link leads to.
+func (h *InviteLinkHandler) PreviewInviteLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	preview, err := h.InviteLinkService.Preview(mux.Vars(r)["token"], userID)
+	if err != nil {
+		writeInviteLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// JoinByInviteLink adds the current user to the group of an invite link.
+func (h *InviteLinkHandler) JoinByInviteLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.UserRepository.GetByID(userID)
+	if err != nil {
+		http.Error(w, "Failed to get user information", http.StatusInternalServerError)
+		return
+	}
+
+	groupID, err := h.InviteLinkService.Join(mux.Vars(r)["token"], userID, user.Username)
+	if err != nil {
+		writeInviteLinkError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"group_id": groupID,
+		"role":     h.InviteLinkService.Groups.GetRole(groupID, userID),
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 	repository "social-network/backend/database/repositories"
 	"social-network/backend/server/middlewares"
@@ -14,17 +15,33 @@ type MessageHandler struct {
 	MessageRepository             *repository.MessageRepository
 	ConversationRepository        *repository.ConversationRepository
 	ConversationMembersRepository *repository.ConversationMembersRepository
+	LinkPreviewService            *services.LinkPreviewService
+	ContentFilter                 *services.ContentFilterService
+	BlockService                  *services.BlockService
 }
 
 func NewMessageHandler(
 	mr *repository.MessageRepository,
 	cr *repository.ConversationRepository,
 	cmr *repository.ConversationMembersRepository,
+	lps *services.LinkPreviewService,
+	cf *services.ContentFilterService,
+	bs *services.BlockService,
 ) *MessageHandler {
 	return &MessageHandler{
 		MessageRepository:             mr,
 		ConversationRepository:        cr,
 		ConversationMembersRepository: cmr,
+		LinkPreviewService:            lps,
+		ContentFilter:                 cf,
+		BlockService:                  bs,
+	}
+}
+
+// attachLinkPreviews fills the cached link previews of each message.
+func (h *MessageHandler) attachLinkPreviews(messages []*models.Message) {
+	for _, message := range messages {
+		message.LinkPreviews = h.LinkPreviewService.GetPreviews(message.Content)
 	}
 }
 
@@ -71,12 +88,23 @@ func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.BlockService.IsBlocked(req.SenderID, req.ReceiverID) {
+		http.Error(w, "User blocked", http.StatusForbidden)
+		return
+	}
+
+	outcome, err := h.ContentFilter.Run(req.SenderID, services.ContentMessage, req.Content)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
 	message := &models.Message{
 		ConversationID: req.ConversationID,
 		SenderID:       req.SenderID,
 		ReceiverID:     req.ReceiverID,
 		GroupID:        req.GroupID,
-		Content:        req.Content,
+		Content:        outcome.Content,
 		CreatedAt:      time.Now(),
 	}
 
@@ -87,6 +115,10 @@ func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	message.ID = id
+	h.ContentFilter.FlagForReview(services.ContentMessage, id, outcome)
+
+	go h.LinkPreviewService.Unfurl(message.Content)
+
 	json.NewEncoder(w).Encode(message)
 }
 
@@ -103,6 +135,7 @@ func (h *MessageHandler) GetMessageByID(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Message not found", http.StatusNotFound)
 		return
 	}
+	message.LinkPreviews = h.LinkPreviewService.GetPreviews(message.Content)
 
 	json.NewEncoder(w).Encode(message)
 }
@@ -120,6 +153,7 @@ func (h *MessageHandler) GetMessagesBetweenUsers(w http.ResponseWriter, r *http.
 		http.Error(w, "Failed to retrieve messages", http.StatusInternalServerError)
 		return
 	}
+	h.attachLinkPreviews(messages)
 
 	json.NewEncoder(w).Encode(messages)
 }
@@ -184,6 +218,7 @@ func (h *MessageHandler) GetMessagesByConversationID(w http.ResponseWriter, r *h
 		http.Error(w, "Error fetching messages", http.StatusInternalServerError)
 		return
 	}
+	h.attachLinkPreviews(messages)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(messages)
@@ -200,6 +235,9 @@ func (h *MessageHandler) GetUserConversation(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
+	for _, conversation := range conversations {
+		h.attachLinkPreviews(conversation.Messages)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(conversations)
 }
@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"social-network/backend/app/services"
+	"social-network/backend/database/models"
+	repository "social-network/backend/database/repositories"
+	"social-network/backend/server/middlewares"
+
+	"github.com/gorilla/mux"
+)
+
+// ModerationHandler handles content reports and the moderation queue.
+type ModerationHandler struct {
+	ReportRepository  *repository.ReportRepository
+	ModerationService *services.ModerationService
+}
+
+// NewModerationHandler creates a new ModerationHandler.
+func NewModerationHandler(rr *repository.ReportRepository, ms *services.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		ReportRepository:  rr,
+		ModerationService: ms,
+	}
+}
+
+type createReportRequest struct {
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details"`
+}
+
+type resolveReportRequest struct {
+	Status     string `json:"status"` // "resolved" or "dismissed"
+	Resolution string `json:"resolution"`
+}
+
+type hideContentRequest struct {
+	Hidden     bool   `json:"hidden"`
+	Resolution string `json:"resolution"`
+}
+
+type suspendUserRequest struct {
+	Hours      int    `json:"hours"` // 0 suspends indefinitely
+	Resolution string `json:"resolution"`
+}
+
+type setRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// requireModerator returns the current user ID when they are a moderator or an admin.
+func (h *ModerationHandler) requireModerator(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return 0, false
+	}
+	if !h.ModerationService.IsModerator(userID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return 0, false
+	}
+	return userID, true
+}
+
+func parseIDVar(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+}
+
+// CreateReport lets any user flag a piece of content or another user.
+func (h *ModerationHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req createReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !services.IsValidTargetType(req.TargetType) {
+		http.Error(w, "Invalid target type", http.StatusBadRequest)
+		return
+	}
+	if !services.ReportReasons[req.Reason] {
+		http.Error(w, "Invalid reason", http.StatusBadRequest)
+		return
+	}
+	if len(req.Details) > 1000 {
+		http.Error(w, "Details too long", http.StatusBadRequest)
+		return
+	}
+
+	target, err := h.ModerationService.GetTarget(req.TargetType, req.TargetID)
+	if err != nil {
+		http.Error(w, "Target not found", http.StatusNotFound)
+		return
+	}
+	if target.AuthorID == userID {
+		http.Error(w, "You cannot report yourself", http.StatusBadRequest)
+		return
+	}
+
+	report := &models.Report{
+		ReporterID: userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+		CreatedAt:  time.Now(),
+	}
+	if details := strings.TrimSpace(req.Details); details != "" {
+		report.Details = &details
+	}
+
+	if _, err := h.ReportRepository.Create(report); err != nil {
+		if errors.Is(err, repository.ErrAlreadyReported) {
+			http.Error(w, "Already reported", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to create report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetReports returns the moderation queue, pending reports by default.
+func (h *ModerationHandler) GetReports(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireModerator(w, r); !ok {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "pending"
+	}
+	if status != "pending" && status != "resolved" && status != "dismissed" {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	reports, err := h.ReportRepository.GetByStatus(status)
+	if err != nil {
+		http.Error(w, "Failed to retrieve reports", http.StatusInternalServerError)
+		return
+	}
+
+	// Le contenu signalé est joint pour que le modérateur n'ait pas à le chercher
+	for _, report := range reports {
+		report.Target, _ = h.ModerationService.GetTarget(report.TargetType, report.TargetID)
+	}
+	if reports == nil {
+		reports = []*models.Report{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// ResolveReport closes a pending report.
+func (h *ModerationHandler) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := h.requireModerator(w, r)
+	if !ok {
+		return
+	}
+
+	reportID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid report ID", http.StatusBadRequest)
+		return
+	}
+
+	var req resolveReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Status != "resolved" && req.Status != "dismissed" {
+		http.Error(w, "Status must be 'resolved' or 'dismissed'", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ReportRepository.Resolve(reportID, moderatorID, req.Status, req.Resolution); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Pending report not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to resolve report", http.StatusInternalServerError)
+		return
+	}
+
+	report, err := h.ReportRepository.GetByID(reportID)
+	if err != nil {
+		http.Error(w, "Failed to retrieve report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// SetContentHidden hides or restores a post, comment, group post, group comment,
+// message or group message.
+func (h *ModerationHandler) SetContentHidden(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := h.requireModerator(w, r)
+	if !ok {
+		return
+	}
+
+	targetType := mux.Vars(r)["type"]
+	targetID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		return
+	}
+
+	var req hideContentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ModerationService.SetHidden(targetType, targetID, req.Hidden); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTargetType):
+			http.Error(w, "Invalid content type", http.StatusBadRequest)
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "Content not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Failed to update content", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if req.Hidden {
+		if err := h.ReportRepository.ResolveForTarget(targetType, targetID, moderatorID, withDefault(req.Resolution, "content hidden")); err != nil {
+			http.Error(w, "Failed to resolve reports", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"target_type": targetType,
+		"target_id":   targetID,
+		"hidden":      req.Hidden,
+	})
+}
+
+// SuspendUser suspends a user for a number of hours, or indefinitely.
+func (h *ModerationHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := h.requireModerator(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if !h.canActOn(w, moderatorID, userID) {
+		return
+	}
+
+	var req suspendUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Hours < 0 {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var until *time.Time
+	if req.Hours > 0 {
+		end := time.Now().Add(time.Duration(req.Hours) * time.Hour)
+		until = &end
+	}
+
+	if err := h.ModerationService.Suspend(userID, until); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to suspend user", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.ReportRepository.ResolveForTarget(services.TargetUser, userID, moderatorID, withDefault(req.Resolution, "user suspended")); err != nil {
+		http.Error(w, "Failed to resolve reports", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"user_id":         userID,
+		"suspended":       true,
+		"suspended_until": until,
+	})
+}
+
+// UnsuspendUser lifts the suspension of a user.
+func (h *ModerationHandler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := h.requireModerator(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	// Comme pour la suspension, seul un admin agit sur un membre de l'équipe
+	if h.ModerationService.GetRole(userID) != services.RoleUser && !h.ModerationService.IsAdmin(moderatorID) {
+		http.Error(w, "Only an admin can lift the suspension of a moderator or an admin", http.StatusForbidden)
+		return
+	}
+
+	if err := h.ModerationService.Unsuspend(userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to lift suspension", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"user_id":   userID,
+		"suspended": false,
+	})
+}
+
+// SetUserRole changes the role of a user. Admins only.
+func (h *ModerationHandler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !h.ModerationService.IsAdmin(adminID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	userID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if userID == adminID {
+		http.Error(w, "You cannot change your own role", http.StatusBadRequest)
+		return
+	}
+
+	var req setRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ModerationService.SetRole(userID, req.Role); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRole):
+			http.Error(w, "Invalid role", http.StatusBadRequest)
+		case errors.Is(err, sql.ErrNoRows):
+			http.Error(w, "User not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Failed to update role", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"user_id": userID,
+		"role":    req.Role,
+	})
+}
+
+// canActOn prevents moderators from suspending themselves or other staff.
+// Only an admin can suspend a moderator, and admins cannot be suspended.
+func (h *ModerationHandler) canActOn(w http.ResponseWriter, moderatorID, userID int64) bool {
+	if moderatorID == userID {
+		http.Error(w, "You cannot suspend yourself", http.StatusBadRequest)
+		return false
+	}
+	switch h.ModerationService.GetRole(userID) {
+	case services.RoleAdmin:
+		http.Error(w, "Admins cannot be suspended", http.StatusForbidden)
+		return false
+	case services.RoleModerator:
+		if !h.ModerationService.IsAdmin(moderatorID) {
+			http.Error(w, "Only an admin can suspend a moderator", http.StatusForbidden)
+			return false
+		}
+	}
+	return true
+}
+
+// writeFilterError answers a content refused by the filter pipeline.
+func writeFilterError(w http.ResponseWriter, err error) {
+	var rejected *services.ContentRejectedError
+	if !errors.As(err, &rejected) {
+		http.Error(w, "Failed to check content", http.StatusInternalServerError)
+		return
+	}
+	if rejected.Filter == "velocity" {
+		http.Error(w, rejected.Reason, http.StatusTooManyRequests)
+		return
+	}
+	http.Error(w, "Content rejected: "+rejected.Reason, http.StatusUnprocessableEntity)
+}
+
+func withDefault(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
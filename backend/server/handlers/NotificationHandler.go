@@ -29,6 +29,30 @@ func NewNotificationHandler(nr *repository.NotificationRepository, fr *repositor
 	}
 }
 
+// notifyUser stores a notification and pushes it in real time to the user.
+// Failures are only logged, the notification must not break the request.
+func notifyUser(nr *repository.NotificationRepository, userID int64, notifType, content string, refID int64, refType string) {
+	id, err := nr.CreateNotification(userID, notifType, content, &refID, &refType)
+	if err != nil {
+		fmt.Printf("Failed to create %s notification for user %d: %v\n", notifType, userID, err)
+		return
+	}
+
+	// Envoyer la notification en temps réel via WebSocket
+	if websocket.GlobalHub != nil {
+		websocket.GlobalHub.SendNotificationToUser(userID, &models.Notification{
+			ID:            id,
+			UserID:        userID,
+			Type:          notifType,
+			Content:       content,
+			Read:          false,
+			ReferenceID:   &refID,
+			ReferenceType: &refType,
+			CreatedAt:     time.Now(),
+		})
+	}
+}
+
 // Request DTOs
 
 type createNotificationRequest struct {
@@ -80,6 +104,11 @@ func (h *NotificationHandler) CreateNotification(w http.ResponseWriter, r *http.
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
+	// Les demandes d'adhésion sont notifiées par /api/groups/{id}/join-requests
+	if req.Type == "group_request" {
+		http.Error(w, "Join requests are created through the group join requests endpoint", http.StatusBadRequest)
+		return
+	}
 	if req.Type == "post_created" || (strings.Contains(req.Type, "group") && !strings.Contains(req.Type, "comment") && !strings.Contains(req.Type, "invitation") && !strings.Contains(req.Type, "request")) {
 		fmt.Println("Creating notification to broadcast...")
 		h.BroadcastNotifToUsers(w, r, req)
@@ -97,16 +126,6 @@ func (h *NotificationHandler) CreateNotification(w http.ResponseWriter, r *http.
 	}
 
 	receiver_id := req.UserID
-	if req.Type == "group_request" {
-		id, title, err := h.GroupRepository.GetGroupInfos(req.ReferenceID)
-		if err != nil {
-			http.Error(w, "Failed to create notification", http.StatusInternalServerError)
-			return
-		}
-		notification.Content += fmt.Sprintf(" \"%s\".;%d", title, receiver_id)
-		receiver_id = id
-		notification.UserID = id
-	}
 
 	id, err := h.NotificationRepository.Create(notification)
 	if err != nil {
@@ -11,6 +11,8 @@ import (
 	"social-network/backend/database/models"
 	repository "social-network/backend/database/repositories"
 	"social-network/backend/server/middlewares"
+
+	"github.com/gorilla/mux"
 )
 
 type PostHandler struct {
@@ -18,14 +20,16 @@ type PostHandler struct {
 	PostRepository    *repository.PostRepository
 	SessionRepository *repository.SessionRepository
 	UserRepository    *repository.UserRepository
+	ContentFilter     *services.ContentFilterService
 }
 
-func NewPostHandler(ps *services.PostService, pr *repository.PostRepository, sr *repository.SessionRepository, ur *repository.UserRepository) *PostHandler {
+func NewPostHandler(ps *services.PostService, pr *repository.PostRepository, sr *repository.SessionRepository, ur *repository.UserRepository, cf *services.ContentFilterService) *PostHandler {
 	return &PostHandler{
 		PostService:       ps,
 		PostRepository:    pr,
 		SessionRepository: sr,
 		UserRepository:    ur,
+		ContentFilter:     cf,
 	}
 }
 
@@ -57,10 +61,16 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	outcome, err := h.ContentFilter.Run(session.UserID, services.ContentPost, req.Content)
+	if err != nil {
+		writeFilterError(w, err)
+		return
+	}
+
 	now := time.Now()
 	post := &models.Post{
 		UserID:      session.UserID,
-		Content:     req.Content,
+		Content:     outcome.Content,
 		ImagePath:   req.ImagePath,
 		PrivacyType: req.PrivacyType,
 		CreatedAt:   now,
@@ -81,7 +91,10 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	post.ID = id
+	h.ContentFilter.FlagForReview(services.ContentPost, id, outcome)
 
+	// Les aperçus de liens sont récupérés en arrière-plan
+	go h.PostService.LinkPreviews.Unfurl(post.Content)
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]any{
@@ -332,6 +345,12 @@ func (h *PostHandler) GetLikedPostsByUserId(w http.ResponseWriter, r *http.Reque
 		CommentsCount int          `json:"comments_count"`
 	}
 
+	// Le visiteur est optionnel : sans cookie seuls les posts publics sont renvoyés
+	var viewerID int64
+	if token, err := r.Cookie("jwt"); err == nil {
+		viewerID = middlewares.CheckJWT(token.Value)
+	}
+
 	var likedPosts []PostWithDetails
 
 	for _, postID := range likedPostIDs {
@@ -340,7 +359,8 @@ func (h *PostHandler) GetLikedPostsByUserId(w http.ResponseWriter, r *http.Reque
 			http.Error(w, "Error retrieving post data", http.StatusInternalServerError)
 			return
 		}
-		if post != nil {
+		if post != nil && h.PostService.CanView(post, viewerID) {
+			h.PostService.AttachLinkPreviews(post)
 			user, err := h.UserRepository.GetByID(post.UserID)
 			if err != nil {
 				http.Error(w, "Error retrieving user data", http.StatusInternalServerError)
@@ -376,3 +396,75 @@ func (h *PostHandler) GetLikedPostsByUserId(w http.ResponseWriter, r *http.Reque
 		return
 	}
 }
+
+// getOwnedPost loads the post from the {id} path variable and checks that the
+// authenticated user is its author.
+func (h *PostHandler) getOwnedPost(w http.ResponseWriter, r *http.Request) (*models.Post, bool) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	postID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	post, err := h.PostRepository.GetPostById(postID)
+	if err != nil {
+		http.Error(w, "Error retrieving post data", http.StatusInternalServerError)
+		return nil, false
+	}
+	if post == nil {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return nil, false
+	}
+	if post.UserID != userID {
+		http.Error(w, "Only the author can inspect the audience of a post", http.StatusForbidden)
+		return nil, false
+	}
+
+	return post, true
+}
+
+// GetPostAudience explains who can see a post. Only its author may call it.
+func (h *PostHandler) GetPostAudience(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.getOwnedPost(w, r)
+	if !ok {
+		return
+	}
+
+	audience, err := h.PostService.GetAudience(post)
+	if err != nil {
+		http.Error(w, "Failed to retrieve post audience", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(audience)
+}
+
+// CheckPostVisibility answers "can user X see post Y" with a reason.
+func (h *PostHandler) CheckPostVisibility(w http.ResponseWriter, r *http.Request) {
+	post, ok := h.getOwnedPost(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseInt(mux.Vars(r)["userID"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	// L'auteur ne doit pas apprendre qui l'a bloqué
+	decision := h.PostService.EvaluateVisibility(post, userID)
+	if decision.Reason == services.VisibilityBlocked {
+		decision.Reason = services.VisibilityNotVisible
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(decision)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"social-network/backend/app/services"
+	repository "social-network/backend/database/repositories"
+	"social-network/backend/server/middlewares"
+)
+
+// SuggestionHandler serves the "people you may know" suggestions.
+type SuggestionHandler struct {
+	SuggestionService *services.SuggestionService
+	UserRepository    *repository.UserRepository
+}
+
+// NewSuggestionHandler creates a new SuggestionHandler.
+func NewSuggestionHandler(ss *services.SuggestionService, ur *repository.UserRepository) *SuggestionHandler {
+	return &SuggestionHandler{
+		SuggestionService: ss,
+		UserRepository:    ur,
+	}
+}
+
+// GetSuggestions returns the ranked follow suggestions of the current user.
+func (h *SuggestionHandler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > services.MaxSuggestionsLimit {
+		limit = services.DefaultSuggestionsLimit
+	}
+
+	suggestions, err := h.SuggestionService.GetSuggestions(userID, limit)
+	if err != nil {
+		http.Error(w, "Failed to retrieve suggestions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+// DismissSuggestion removes a user from the suggestions of the current user.
+func (h *SuggestionHandler) DismissSuggestion(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	dismissedID, err := parseIDVar(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.UserRepository.GetByID(dismissedID); err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.SuggestionService.Dismiss(userID, dismissedID); err != nil {
+		http.Error(w, "Failed to dismiss suggestion", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -20,14 +20,18 @@ type UserHandler struct {
 	UserService       *services.UserService
 	UserRepository    *repository.UserRepository
 	SessionRepository *repository.SessionRepository
+	BlockService      *services.BlockService
+	ProfileService    *services.ProfileService
 }
 
 // NewUserHandler creates a new UserHandler.
-func NewUserHandler(us *services.UserService, ur *repository.UserRepository, sr *repository.SessionRepository) *UserHandler {
+func NewUserHandler(us *services.UserService, ur *repository.UserRepository, sr *repository.SessionRepository, bs *services.BlockService, ps *services.ProfileService) *UserHandler {
 	return &UserHandler{
 		UserService:       us,
 		UserRepository:    ur,
 		SessionRepository: sr,
+		BlockService:      bs,
+		ProfileService:    ps,
 	}
 }
 
@@ -104,6 +108,11 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if services.IsSuspensionActive(user) {
+		http.Error(w, "Account suspended", http.StatusForbidden)
+		return
+	}
+
 	// Génère le JWT
 	token, err := h.UserService.GenerateJWT(user.ID)
 	if err != nil {
@@ -322,19 +331,76 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	_, err := h.SessionRepository.GetBySessionToken(req.JWT)
+	session, err := h.SessionRepository.GetBySessionToken(req.JWT)
 	if err != nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 	user, err := h.UserRepository.GetByUserName(username)
-	if err != nil {
+	if err != nil || h.BlockService.IsBlocked(user.ID, session.UserID) {
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
 	}
 
+	// Seuls les champs autorisés pour ce visiteur sont renvoyés
+	profile, err := h.ProfileService.Project(user, session.UserID)
+	if err != nil {
+		http.Error(w, "Failed to load profile", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	json.NewEncoder(w).Encode(profile)
+}
+
+// GetProfilePrivacy returns the field visibility settings of the current user.
+func (h *UserHandler) GetProfilePrivacy(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	privacy, err := h.ProfileService.GetPrivacy(userID)
+	if err != nil {
+		http.Error(w, "Failed to load privacy settings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(privacy)
+}
+
+// UpdateProfilePrivacy changes the field visibility settings of the current user.
+func (h *UserHandler) UpdateProfilePrivacy(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserID(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Les champs absents gardent leur réglage actuel
+	privacy, err := h.ProfileService.GetPrivacy(userID)
+	if err != nil {
+		http.Error(w, "Failed to load privacy settings", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(privacy); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.ProfileService.SetPrivacy(userID, privacy); err != nil {
+		if err == services.ErrInvalidFieldVisibility {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to update privacy settings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(privacy)
 }
 
 // GetUser retrieves a user by ID from JSON body.
@@ -13,6 +13,9 @@ type contextKey string
 
 const UserIDKey contextKey = "userID"
 
+// IsSuspended is set at startup so the tokens of suspended users are refused.
+var IsSuspended func(userID int64) bool
+
 func JWTMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var tokenString string
@@ -43,6 +46,11 @@ func JWTMiddleware(next http.Handler) http.Handler {
 		claims := token.Claims.(jwt.MapClaims)
 		userID := int64(claims["user_id"].(float64))
 
+		if IsSuspended != nil && IsSuspended(userID) {
+			http.Error(w, "Compte suspendu", http.StatusForbidden)
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), UserIDKey, userID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -62,5 +70,9 @@ func CheckJWT(tokenString string) int64 {
 	}
 
 	claims := token.Claims.(jwt.MapClaims)
-	return int64(claims["user_id"].(float64))
+	userID := int64(claims["user_id"].(float64))
+	if IsSuspended != nil && IsSuspended(userID) {
+		return 0
+	}
+	return userID
 }
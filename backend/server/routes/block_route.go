@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"net/http"
+
+	"social-network/backend/server/handlers"
+	"social-network/backend/server/middlewares"
+
+	"github.com/gorilla/mux"
+)
+
+// BlockRoutes
+func BlockRoutes(r *mux.Router, blockHandler *handlers.BlockHandler) {
+	r.Handle("/api/users/{id:[0-9]+}/block", middlewares.JWTMiddleware(http.HandlerFunc(blockHandler.BlockUser))).Methods("POST", "OPTIONS")
+	r.Handle("/api/users/{id:[0-9]+}/block", middlewares.JWTMiddleware(http.HandlerFunc(blockHandler.UnblockUser))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/users/{id:[0-9]+}/mute", middlewares.JWTMiddleware(http.HandlerFunc(blockHandler.MuteUser))).Methods("POST", "OPTIONS")
+	r.Handle("/api/users/{id:[0-9]+}/mute", middlewares.JWTMiddleware(http.HandlerFunc(blockHandler.UnmuteUser))).Methods("DELETE", "OPTIONS")
+
+	r.Handle("/api/blocks", middlewares.JWTMiddleware(http.HandlerFunc(blockHandler.GetBlockedUsers))).Methods("GET", "OPTIONS")
+	r.Handle("/api/mutes", middlewares.JWTMiddleware(http.HandlerFunc(blockHandler.GetMutedUsers))).Methods("GET", "OPTIONS")
+}
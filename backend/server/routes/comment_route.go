@@ -1,7 +1,10 @@
 package routes
 
 import (
+	"net/http"
+
 	"social-network/backend/server/handlers"
+	"social-network/backend/server/middlewares"
 
 	"github.com/gorilla/mux"
 )
@@ -10,6 +13,9 @@ import (
 func CommentsRoutes(r *mux.Router, commentHandler *handlers.CommentHandler) {
 	r.HandleFunc("/api/comments", commentHandler.CreateComment).Methods("POST")
 	r.HandleFunc("/api/comments/{id}", commentHandler.GetComment).Methods("GET")
+	r.HandleFunc("/api/comments/{id:[0-9]+}/replies", commentHandler.GetCommentReplies).Methods("GET")
+	r.Handle("/api/comments/{id:[0-9]+}/like", middlewares.JWTMiddleware(http.HandlerFunc(commentHandler.LikeComment))).Methods("POST", "OPTIONS")
+	r.HandleFunc("/api/posts/{id:[0-9]+}/comments/count", commentHandler.CountComments).Methods("GET")
 	r.HandleFunc("/api/comments_user", commentHandler.GetCommentsFromUserByID).Methods("POST")
 	r.HandleFunc("/api/comments/{id}", commentHandler.GetCommentsByPost).Methods("POST")
 	r.HandleFunc("/api/comments/{id}", commentHandler.UpdateComment).Methods("PUT")
@@ -13,7 +13,11 @@ func EventsRoutes(r *mux.Router, eventHandler *handlers.EventHandler) {
 	r.Handle("/api/groups/{id:[0-9]+}/events", middlewares.JWTMiddleware(http.HandlerFunc(eventHandler.CreateEvent))).Methods("POST", "OPTIONS")
 	r.Handle("/api/events/{eventID:[0-9]+}/response", middlewares.JWTMiddleware(http.HandlerFunc(eventHandler.SetEventResponse))).Methods("POST", "OPTIONS")
 	r.Handle("/api/groups/{id:[0-9]+}/events", middlewares.JWTMiddleware(http.HandlerFunc(eventHandler.GetEventsByGroupID))).Methods("GET", "OPTIONS")
-	r.Handle("/api/events/{eventID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(eventHandler.DeleteEvent))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/events/{eventID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(eventHandler.UpdateEvent))).Methods("PUT", "OPTIONS")
+	r.Handle("/api/events/{eventID:[0-9]+}/cancel", middlewares.JWTMiddleware(http.HandlerFunc(eventHandler.CancelEvent))).Methods("POST", "OPTIONS")
+	r.Handle("/api/events/{eventID:[0-9]+}/history", middlewares.JWTMiddleware(http.HandlerFunc(eventHandler.GetEventHistory))).Methods("GET", "OPTIONS")
+	// Supprimer un événement l'annule : l'historique et les réponses sont conservés
+	r.Handle("/api/events/{eventID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(eventHandler.CancelEvent))).Methods("DELETE", "OPTIONS")
 }
 
 
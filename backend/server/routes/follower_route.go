@@ -13,10 +13,16 @@ func FollowersRoutes(r *mux.Router, followerHandler *handlers.FollowerHandler) {
 	r.HandleFunc("/api/followers/{id}", followerHandler.DeleteFollower).Methods("DELETE")
 	r.HandleFunc("/api/followers/check", followerHandler.CheckIfFollowing).Methods("POST")
 	r.HandleFunc("/api/followersDetails", followerHandler.GetFollowersHandler).Methods("GET")
-	r.HandleFunc("/api/followers/accept", followerHandler.AcceptFollower).Methods("POST")
-	r.HandleFunc("/api/followers/decline", followerHandler.DeclineFollower).Methods("POST")
+	r.Handle("/api/followers/accept", middlewares.JWTMiddleware(http.HandlerFunc(followerHandler.AcceptFollower))).Methods("POST", "OPTIONS")
+	r.Handle("/api/followers/decline", middlewares.JWTMiddleware(http.HandlerFunc(followerHandler.DeclineFollower))).Methods("POST", "OPTIONS")
     r.HandleFunc("/api/followingDetails", followerHandler.GetFollowingHandler).Methods("GET")
 
 	r.HandleFunc("/api/friendsDetails", followerHandler.GetFriendsHandler).Methods("GET")
 
+	r.Handle("/api/follow-requests/incoming", middlewares.JWTMiddleware(http.HandlerFunc(followerHandler.GetIncomingFollowRequests))).Methods("GET", "OPTIONS")
+	r.Handle("/api/follow-requests/outgoing", middlewares.JWTMiddleware(http.HandlerFunc(followerHandler.GetOutgoingFollowRequests))).Methods("GET", "OPTIONS")
+	r.Handle("/api/follow-requests/accept", middlewares.JWTMiddleware(http.HandlerFunc(followerHandler.AcceptFollowRequests))).Methods("POST", "OPTIONS")
+	r.Handle("/api/follow-requests/decline", middlewares.JWTMiddleware(http.HandlerFunc(followerHandler.DeclineFollowRequests))).Methods("POST", "OPTIONS")
+	r.Handle("/api/follow-requests/{id:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(followerHandler.CancelFollowRequest))).Methods("DELETE", "OPTIONS")
+
 }
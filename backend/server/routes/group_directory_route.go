@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"net/http"
+
+	"social-network/backend/server/handlers"
+	"social-network/backend/server/middlewares"
+
+	"github.com/gorilla/mux"
+)
+
+// GroupDirectoryRoutes
+func GroupDirectoryRoutes(r *mux.Router, groupDirectoryHandler *handlers.GroupDirectoryHandler) {
+	r.Handle("/api/groups/discover", middlewares.JWTMiddleware(http.HandlerFunc(groupDirectoryHandler.DiscoverGroups))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/recommended", middlewares.JWTMiddleware(http.HandlerFunc(groupDirectoryHandler.RecommendGroups))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/categories", middlewares.JWTMiddleware(http.HandlerFunc(groupDirectoryHandler.GetGroupCategories))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/profile", middlewares.JWTMiddleware(http.HandlerFunc(groupDirectoryHandler.UpdateGroupProfile))).Methods("PUT", "OPTIONS")
+}
@@ -12,8 +12,8 @@ import (
 func GroupRoutes(r *mux.Router, groupHandler *handlers.GroupHandler) {
 	r.Handle("/api/groups", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.CreateGroup))).Methods("POST", "OPTIONS")
 	r.Handle("/api/groups", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetGroupsByUserID))).Methods("GET")
-	r.Handle("/api/groups/accept-invitation", (http.HandlerFunc(groupHandler.AcceptGroupInvitation))).Methods("POST", "OPTIONS")
-	r.Handle("/api/groups/decline-invitation", (http.HandlerFunc(groupHandler.DeclineGroupInvitation))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/accept-invitation", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.AcceptGroupInvitation))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/decline-invitation", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.DeclineGroupInvitation))).Methods("POST", "OPTIONS")
 	r.Handle("/api/groups/check-invitation", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.CheckInvitationStatus))).Methods("POST", "OPTIONS")
 	r.Handle("/api/groups/{id:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetGroupByID))).Methods("GET", "OPTIONS")
 	r.Handle("/api/groups/{id:[0-9]+}/members", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetMembersByGroupID))).Methods("GET", "OPTIONS")
@@ -24,5 +24,40 @@ func GroupRoutes(r *mux.Router, groupHandler *handlers.GroupHandler) {
 	r.Handle("/api/groups/{id:[0-9]+}/posts", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetPostsByGroupID))).Methods("GET", "OPTIONS")
 	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/comments", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.CreateGroupComment))).Methods("POST", "OPTIONS")
 	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/comments", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetCommentsByGroupPostID))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/comments/{commentID:[0-9]+}/replies", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetGroupCommentReplies))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/comments/count", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.CountGroupComments))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/comments/{commentID:[0-9]+}/like", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.LikeGroupComment))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.UpdateGroup))).Methods("PUT", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/members/{userID:[0-9]+}/role", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.SetMemberRole))).Methods("PUT", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/transfer", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.TransferOwnership))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/archive", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.ArchiveGroup))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/archive", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.UnarchiveGroup))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.DeleteGroup))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/restore", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.RestoreGroup))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/deleted", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetDeletedGroups))).Methods("GET")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.DeleteGroupPost))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/comments/{commentID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.DeleteGroupComment))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/join-requests", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.CreateJoinRequest))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/join-requests", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetJoinRequests))).Methods("GET")
+	r.Handle("/api/group-join-requests/{requestID:[0-9]+}/approve", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.ApproveJoinRequest))).Methods("POST", "OPTIONS")
+	r.Handle("/api/group-join-requests/{requestID:[0-9]+}/reject", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.RejectJoinRequest))).Methods("POST", "OPTIONS")
+	r.Handle("/api/group-join-requests/{requestID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.WithdrawJoinRequest))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/leave", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.LeaveGroup))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/members/{userID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.RemoveMember))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/bans", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetBans))).Methods("GET")
+	r.Handle("/api/groups/{id:[0-9]+}/bans", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.BanMember))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/bans/{userID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.UnbanMember))).Methods("DELETE", "OPTIONS")
 	r.Handle("/api/groups/{id:[0-9]+}/membership-status", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.CheckMembership))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/pin", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.PinGroupPost))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/pin", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.UnpinGroupPost))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/announcement", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.MarkGroupAnnouncement))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/announcement", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.UnmarkGroupAnnouncement))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/acknowledge", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.AcknowledgeAnnouncement))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/posts/{postID:[0-9]+}/acknowledgements", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetAnnouncementAcknowledgements))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/audit-log", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetAuditLog))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/questions", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetGroupQuestions))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/questions", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.SetGroupQuestions))).Methods("PUT", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/rules", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.GetGroupRules))).Methods("GET", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/rules", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.SetGroupRules))).Methods("PUT", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/rules/accept", middlewares.JWTMiddleware(http.HandlerFunc(groupHandler.AcceptGroupRules))).Methods("POST", "OPTIONS")
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"net/http"
+
+	"social-network/backend/server/handlers"
+	"social-network/backend/server/middlewares"
+
+	"github.com/gorilla/mux"
+)
+
+// InviteLinkRoutes
+func InviteLinkRoutes(r *mux.Router, inviteLinkHandler *handlers.InviteLinkHandler) {
+	r.Handle("/api/groups/{id:[0-9]+}/invite-links", middlewares.JWTMiddleware(http.HandlerFunc(inviteLinkHandler.CreateInviteLink))).Methods("POST", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/invite-links", middlewares.JWTMiddleware(http.HandlerFunc(inviteLinkHandler.GetInviteLinks))).Methods("GET")
+	r.Handle("/api/groups/{id:[0-9]+}/invite-links/{linkID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(inviteLinkHandler.RevokeInviteLink))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/groups/{id:[0-9]+}/invite-links/{linkID:[0-9]+}/uses", middlewares.JWTMiddleware(http.HandlerFunc(inviteLinkHandler.GetInviteLinkUses))).Methods("GET", "OPTIONS")
+
+	r.Handle("/api/invite-links/{token}", middlewares.JWTMiddleware(http.HandlerFunc(inviteLinkHandler.PreviewInviteLink))).Methods("GET", "OPTIONS")
+	r.Handle("/api/invite-links/{token}/join", middlewares.JWTMiddleware(http.HandlerFunc(inviteLinkHandler.JoinByInviteLink))).Methods("POST", "OPTIONS")
+}
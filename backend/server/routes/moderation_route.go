@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"net/http"
+
+	"social-network/backend/server/handlers"
+	"social-network/backend/server/middlewares"
+
+	"github.com/gorilla/mux"
+)
+
+// ModerationRoutes
+func ModerationRoutes(r *mux.Router, moderationHandler *handlers.ModerationHandler) {
+	r.Handle("/api/reports", middlewares.JWTMiddleware(http.HandlerFunc(moderationHandler.CreateReport))).Methods("POST", "OPTIONS")
+
+	r.Handle("/api/moderation/reports", middlewares.JWTMiddleware(http.HandlerFunc(moderationHandler.GetReports))).Methods("GET", "OPTIONS")
+	r.Handle("/api/moderation/reports/{id:[0-9]+}/resolve", middlewares.JWTMiddleware(http.HandlerFunc(moderationHandler.ResolveReport))).Methods("POST", "OPTIONS")
+	r.Handle("/api/moderation/content/{type}/{id:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(moderationHandler.SetContentHidden))).Methods("PUT", "OPTIONS")
+	r.Handle("/api/moderation/users/{id:[0-9]+}/suspend", middlewares.JWTMiddleware(http.HandlerFunc(moderationHandler.SuspendUser))).Methods("POST", "OPTIONS")
+	r.Handle("/api/moderation/users/{id:[0-9]+}/suspend", middlewares.JWTMiddleware(http.HandlerFunc(moderationHandler.UnsuspendUser))).Methods("DELETE", "OPTIONS")
+	r.Handle("/api/moderation/users/{id:[0-9]+}/role", middlewares.JWTMiddleware(http.HandlerFunc(moderationHandler.SetUserRole))).Methods("PUT", "OPTIONS")
+}
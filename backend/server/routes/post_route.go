@@ -1,7 +1,10 @@
 package routes
 
 import (
+	"net/http"
+
 	"social-network/backend/server/handlers"
+	"social-network/backend/server/middlewares"
 
 	"github.com/gorilla/mux"
 )
@@ -16,5 +19,7 @@ func PostRoutes(r *mux.Router, postHandler *handlers.PostHandler) {
 	// r.HandleFunc("/api/posts/{id}", postHandler.UpdatePost).Methods("PUT")
 	r.HandleFunc("/api/posts/{id}", postHandler.DeletePost).Methods("DELETE")
 	r.HandleFunc("/api/liked_posts", postHandler.GetLikedPostsByUserId).Methods("POST")
+	r.Handle("/api/posts/{id:[0-9]+}/audience", middlewares.JWTMiddleware(http.HandlerFunc(postHandler.GetPostAudience))).Methods("GET", "OPTIONS")
+	r.Handle("/api/posts/{id:[0-9]+}/audience/{userID:[0-9]+}", middlewares.JWTMiddleware(http.HandlerFunc(postHandler.CheckPostVisibility))).Methods("GET", "OPTIONS")
 
 }
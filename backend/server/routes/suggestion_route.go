@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"net/http"
+
+	"social-network/backend/server/handlers"
+	"social-network/backend/server/middlewares"
+
+	"github.com/gorilla/mux"
+)
+
+// SuggestionRoutes
+func SuggestionRoutes(r *mux.Router, suggestionHandler *handlers.SuggestionHandler) {
+	r.Handle("/api/suggestions", middlewares.JWTMiddleware(http.HandlerFunc(suggestionHandler.GetSuggestions))).Methods("GET", "OPTIONS")
+	r.Handle("/api/suggestions/{id:[0-9]+}/dismiss", middlewares.JWTMiddleware(http.HandlerFunc(suggestionHandler.DismissSuggestion))).Methods("POST", "OPTIONS")
+}
@@ -25,4 +25,6 @@ func UserRoutes(r *mux.Router, userHandler *handlers.UserHandler) {
 	r.Handle("/api/users/friends", middlewares.CORSMiddleware(middlewares.JWTMiddleware(http.HandlerFunc(userHandler.GetUserFriends)))).Methods("GET")
 	// get user by JWT
 	r.Handle("/api/users/me", middlewares.JWTMiddleware(http.HandlerFunc(userHandler.GetCurrentUser))).Methods("GET")
+	r.Handle("/api/users/me/privacy", middlewares.JWTMiddleware(http.HandlerFunc(userHandler.GetProfilePrivacy))).Methods("GET", "OPTIONS")
+	r.Handle("/api/users/me/privacy", middlewares.JWTMiddleware(http.HandlerFunc(userHandler.UpdateProfilePrivacy))).Methods("PUT", "OPTIONS")
 }
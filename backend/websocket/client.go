@@ -51,6 +51,9 @@ type Client struct {
 
 	// User ID associated with this client
 	UserID int64
+
+	// Rooms the client is subscribed to, guarded by the hub lock
+	rooms map[string]bool
 }
 
 // readPump pumps messages from the websocket connection to the hub
@@ -89,6 +92,16 @@ func (c *Client) readPump() {
 			continue
 		}
 
+		// Room subscriptions belong to this connection, not to the user
+		switch wsMsg.Type {
+		case "room_join":
+			c.hub.subscribe <- subscription{client: c, room: wsMsg.Room}
+			continue
+		case "room_leave":
+			c.hub.unsubscribe <- subscription{client: c, room: wsMsg.Room}
+			continue
+		}
+
 		// Set sender ID from client
 		wsMsg.SenderID = c.UserID
 		wsMsg.Timestamp = time.Now()
@@ -161,6 +174,7 @@ func ServeWS(hub *Hub, w http.ResponseWriter, r *http.Request, userID int64) {
 		conn:   conn,
 		send:   make(chan []byte, 256),
 		UserID: userID,
+		rooms:  make(map[string]bool),
 	}
 
 	hub.register <- client
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"social-network/backend/app/services"
 	repository "social-network/backend/database/repositories"
 	"social-network/backend/server/middlewares"
 	"strconv"
@@ -28,8 +29,12 @@ func NewWebSocketHandler(
 	conversationRepo repository.ConversationRepositoryInterface,
 	conversationMembersRepo repository.ConversationMemberRepositoryInterface,
 	notificationRepo repository.NotificationRepositoryInterface,
+	linkPreviews *services.LinkPreviewService,
+	contentFilter *services.ContentFilterService,
+	blocks *services.BlockService,
+	groups *services.GroupService,
 ) *WebSocketHandler {
-	hub := NewHub(messageRepo, conversationRepo, conversationMembersRepo, notificationRepo)
+	hub := NewHub(messageRepo, conversationRepo, conversationMembersRepo, notificationRepo, linkPreviews, contentFilter, blocks, groups)
 	go hub.Run()
 
 	// Assigner le hub à la variable globale
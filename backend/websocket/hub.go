@@ -2,9 +2,12 @@ package websocket
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"social-network/backend/app/services"
 	"social-network/backend/database/models"
 	repository "social-network/backend/database/repositories"
+	"social-network/backend/server/middlewares"
 	"sync"
 	"time"
 )
@@ -14,8 +17,12 @@ type Hub struct {
 	// Registered clients
 	clients map[*Client]bool
 
-	// Clients by user ID for direct messaging
-	userClients map[int64]*Client
+	// Clients by user ID for direct messaging, a user may be connected from
+	// several tabs
+	userClients map[int64]map[*Client]bool
+
+	// Clients subscribed to each room
+	rooms map[string]map[*Client]bool
 
 	// Inbound messages from the clients
 	broadcast chan []byte
@@ -26,20 +33,43 @@ type Hub struct {
 	// Unregister requests from clients
 	unregister chan *Client
 
+	// Room subscriptions from the clients, handled after their registration
+	subscribe   chan subscription
+	unsubscribe chan subscription
+
 	// Repository interfaces
 	messageRepo             repository.MessageRepositoryInterface
 	conversationRepo        repository.ConversationRepositoryInterface
 	conversationMembersRepo repository.ConversationMemberRepositoryInterface
 	notificationRepo        repository.NotificationRepositoryInterface
 
+	// Link preview unfurling for message content
+	linkPreviews *services.LinkPreviewService
+
+	// Filters every message before it is stored
+	contentFilter *services.ContentFilterService
+
+	// Refuses messages between users who blocked each other
+	blocks *services.BlockService
+
+	// Restricts the group rooms to the accepted members
+	groups *services.GroupService
+
 	// Mutex for thread-safe operations
 	mutex sync.RWMutex
 }
 
+// subscription is a client joining or leaving a room
+type subscription struct {
+	client *Client
+	room   string
+}
+
 // WSMessage represents a WebSocket message
 type WSMessage struct {
 	Type           string      `json:"type"`
 	ConversationID int64       `json:"conversation_id,omitempty"`
+	Room           string      `json:"room,omitempty"`
 	Content        string      `json:"content,omitempty"`
 	SenderID       int64       `json:"sender_id,omitempty"`
 	ReceiverID     int64       `json:"receiver_id,omitempty"`
@@ -55,20 +85,45 @@ func NewHub(
 	conversationRepo repository.ConversationRepositoryInterface,
 	conversationMembersRepo repository.ConversationMemberRepositoryInterface,
 	notificationRepo repository.NotificationRepositoryInterface,
+	linkPreviews *services.LinkPreviewService,
+	contentFilter *services.ContentFilterService,
+	blocks *services.BlockService,
+	groups *services.GroupService,
 ) *Hub {
 	return &Hub{
 		clients:                 make(map[*Client]bool),
-		userClients:             make(map[int64]*Client),
+		userClients:             make(map[int64]map[*Client]bool),
+		rooms:                   make(map[string]map[*Client]bool),
 		broadcast:               make(chan []byte),
 		register:                make(chan *Client),
 		unregister:              make(chan *Client),
+		subscribe:               make(chan subscription),
+		unsubscribe:             make(chan subscription),
 		messageRepo:             messageRepo,
 		conversationRepo:        conversationRepo,
 		conversationMembersRepo: conversationMembersRepo,
 		notificationRepo:        notificationRepo,
+		linkPreviews:            linkPreviews,
+		contentFilter:           contentFilter,
+		blocks:                  blocks,
+		groups:                  groups,
 	}
 }
 
+// GroupRoom is the room of the real-time updates of a group.
+func GroupRoom(groupID int64) string {
+	return fmt.Sprintf("group:%d", groupID)
+}
+
+// parseGroupRoom returns the group of a group room.
+func parseGroupRoom(room string) (int64, bool) {
+	var groupID int64
+	if _, err := fmt.Sscanf(room, "group:%d", &groupID); err != nil || GroupRoom(groupID) != room {
+		return 0, false
+	}
+	return groupID, true
+}
+
 // Run starts the hub
 func (h *Hub) Run() {
 	for {
@@ -81,6 +136,12 @@ func (h *Hub) Run() {
 
 		case message := <-h.broadcast:
 			h.handleBroadcast(message)
+
+		case sub := <-h.subscribe:
+			h.joinRoom(sub.client, sub.room)
+
+		case sub := <-h.unsubscribe:
+			h.leaveRoom(sub.client, sub.room)
 		}
 	}
 }
@@ -88,10 +149,12 @@ func (h *Hub) Run() {
 // registerClient registers a new client
 func (h *Hub) registerClient(client *Client) {
 	h.mutex.Lock()
-	defer h.mutex.Unlock()
-
 	h.clients[client] = true
-	h.userClients[client.UserID] = client
+	if h.userClients[client.UserID] == nil {
+		h.userClients[client.UserID] = make(map[*Client]bool)
+	}
+	h.userClients[client.UserID][client] = true
+	h.mutex.Unlock()
 
 	log.Printf("Client registered: UserID %d", client.UserID)
 
@@ -110,7 +173,13 @@ func (h *Hub) unregisterClient(client *Client) {
 
 	if _, ok := h.clients[client]; ok {
 		delete(h.clients, client)
-		delete(h.userClients, client.UserID)
+		delete(h.userClients[client.UserID], client)
+		if len(h.userClients[client.UserID]) == 0 {
+			delete(h.userClients, client.UserID)
+		}
+		for room := range client.rooms {
+			h.removeFromRoom(client, room)
+		}
 		close(client.send)
 
 		log.Printf("Client unregistered: UserID %d", client.UserID)
@@ -135,6 +204,39 @@ func (h *Hub) handleBroadcast(message []byte) {
 
 // handleMessageSend processes new message sending between two users
 func (h *Hub) handleMessageSend(wsMsg WSMessage) {
+	// Les comptes suspendus ne peuvent ni envoyer ni recevoir de messages
+	if middlewares.IsSuspended != nil && (middlewares.IsSuspended(wsMsg.SenderID) || middlewares.IsSuspended(wsMsg.ReceiverID)) {
+		h.sendToUser(wsMsg.SenderID, WSMessage{
+			Type:       "message_rejected",
+			ReceiverID: wsMsg.ReceiverID,
+			Error:      "user suspended",
+			Timestamp:  time.Now(),
+		})
+		return
+	}
+
+	if h.blocks != nil && h.blocks.IsBlocked(wsMsg.SenderID, wsMsg.ReceiverID) {
+		h.sendToUser(wsMsg.SenderID, WSMessage{
+			Type:       "message_rejected",
+			ReceiverID: wsMsg.ReceiverID,
+			Error:      "user blocked",
+			Timestamp:  time.Now(),
+		})
+		return
+	}
+
+	outcome, err := h.contentFilter.Run(wsMsg.SenderID, services.ContentMessage, wsMsg.Content)
+	if err != nil {
+		h.sendToUser(wsMsg.SenderID, WSMessage{
+			Type:       "message_rejected",
+			ReceiverID: wsMsg.ReceiverID,
+			Error:      err.Error(),
+			Timestamp:  time.Now(),
+		})
+		return
+	}
+	wsMsg.Content = outcome.Content
+
 	// Find or create conversation between two users
 	conversation, err := h.conversationRepo.CreateOrGetPrivateConversation(wsMsg.SenderID, wsMsg.ReceiverID)
 	if err != nil {
@@ -157,6 +259,8 @@ func (h *Hub) handleMessageSend(wsMsg WSMessage) {
 		return
 	}
 
+	h.contentFilter.FlagForReview(services.ContentMessage, messageID, outcome)
+
 	// Update conversation timestamp
 	h.conversationRepo.UpdatedAt(conversation.ID)
 
@@ -174,23 +278,53 @@ func (h *Hub) handleMessageSend(wsMsg WSMessage) {
 	// Send to both sender and receiver
 	h.sendToUser(wsMsg.SenderID, response)
 	h.sendToUser(wsMsg.ReceiverID, response)
+
+	if h.linkPreviews != nil && len(services.ExtractURLs(message.Content)) > 0 {
+		go h.unfurlMessage(message)
+	}
 }
 
-// sendToUser sends a message to a specific user
+// unfurlMessage fetches the link previews of a message and pushes them to
+// both participants once they are ready
+func (h *Hub) unfurlMessage(message *models.Message) {
+	previews := h.linkPreviews.Unfurl(message.Content)
+	if len(previews) == 0 {
+		return
+	}
+
+	update := WSMessage{
+		Type:           "link_preview",
+		ConversationID: message.ConversationID,
+		MessageID:      message.ID,
+		Data:           previews,
+		Timestamp:      time.Now(),
+	}
+
+	h.sendToUser(message.SenderID, update)
+	h.sendToUser(message.ReceiverID, update)
+}
+
+// sendToUser sends a message to every connection of a specific user
 func (h *Hub) sendToUser(userID int64, message WSMessage) {
 	h.mutex.RLock()
-	client, exists := h.userClients[userID]
+	clients := make([]*Client, 0, len(h.userClients[userID]))
+	for client := range h.userClients[userID] {
+		clients = append(clients, client)
+	}
 	h.mutex.RUnlock()
 
-	if !exists {
+	if len(clients) == 0 {
 		log.Printf("User %d is not connected", userID)
 		return
 	}
 
-	h.sendToClient(client, message)
+	for _, client := range clients {
+		h.sendToClient(client, message)
+	}
 }
 
-// sendToClient sends a message to a specific client
+// sendToClient sends a message to a specific client. The hub lock is held
+// while sending, so the channel cannot be closed by an unregistration.
 func (h *Hub) sendToClient(client *Client, message WSMessage) {
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
@@ -198,14 +332,115 @@ func (h *Hub) sendToClient(client *Client, message WSMessage) {
 		return
 	}
 
-	select {
-	case client.send <- messageBytes:
-	default:
+	full := false
+	h.mutex.RLock()
+	if h.clients[client] {
+		select {
+		case client.send <- messageBytes:
+		default:
+			full = true
+		}
+	}
+	h.mutex.RUnlock()
+
+	if full {
 		// Client's send channel is full, close it
 		h.unregisterClient(client)
 	}
 }
 
+// joinRoom subscribes a client to a room. Group rooms are restricted to the
+// accepted members of the group.
+func (h *Hub) joinRoom(client *Client, room string) {
+	groupID, ok := parseGroupRoom(room)
+	if !ok || h.groups == nil || !h.groups.IsMember(groupID, client.UserID) {
+		h.sendToClient(client, WSMessage{
+			Type:      "room_rejected",
+			Room:      room,
+			Error:     "not a member of this group",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	h.mutex.Lock()
+	if !h.clients[client] {
+		h.mutex.Unlock()
+		return
+	}
+	if h.rooms[room] == nil {
+		h.rooms[room] = make(map[*Client]bool)
+	}
+	h.rooms[room][client] = true
+	client.rooms[room] = true
+	h.mutex.Unlock()
+
+	h.sendToClient(client, WSMessage{
+		Type:      "room_joined",
+		Room:      room,
+		Timestamp: time.Now(),
+	})
+}
+
+// leaveRoom unsubscribes a client from a room.
+func (h *Hub) leaveRoom(client *Client, room string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.removeFromRoom(client, room)
+}
+
+// removeFromRoom unsubscribes a client from a room, with the lock held.
+func (h *Hub) removeFromRoom(client *Client, room string) {
+	delete(client.rooms, room)
+	delete(h.rooms[room], client)
+	if len(h.rooms[room]) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+// BroadcastToGroup pushes a typed update to the clients subscribed to the
+// room of a group. Only accepted members receive it: the clients of users who
+// left or were removed are dropped from the room.
+func (h *Hub) BroadcastToGroup(groupID int64, eventType string, data interface{}) {
+	room := GroupRoom(groupID)
+
+	h.mutex.RLock()
+	subscribers := make([]*Client, 0, len(h.rooms[room]))
+	for client := range h.rooms[room] {
+		subscribers = append(subscribers, client)
+	}
+	h.mutex.RUnlock()
+
+	if len(subscribers) == 0 || h.groups == nil {
+		return
+	}
+
+	memberIDs, err := h.groups.MemberIDs(groupID)
+	if err != nil {
+		log.Printf("Error getting members of group %d: %v", groupID, err)
+		return
+	}
+	members := make(map[int64]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		members[id] = true
+	}
+
+	message := WSMessage{
+		Type:      eventType,
+		Room:      room,
+		Data:      data,
+		Timestamp: time.Now(),
+	}
+	for _, client := range subscribers {
+		if members[client.UserID] {
+			h.sendToClient(client, message)
+		} else {
+			h.leaveRoom(client, room)
+		}
+	}
+}
+
 // SendNotificationToUser sends a notification to a specific user via WebSocket
 func (h *Hub) SendNotificationToUser(userID int64, notification interface{}) {
 	message := WSMessage{
@@ -216,3 +451,12 @@ func (h *Hub) SendNotificationToUser(userID int64, notification interface{}) {
 
 	h.sendToUser(userID, message)
 }
+
+// SendEventToUser pushes a typed real-time update to a specific user
+func (h *Hub) SendEventToUser(userID int64, eventType string, data interface{}) {
+	h.sendToUser(userID, WSMessage{
+		Type:      eventType,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"net/http"
+	"social-network/backend/app/services"
 	repository "social-network/backend/database/repositories"
 	"social-network/backend/server/middlewares"
 
@@ -15,9 +16,13 @@ func SetupWebSocketRoutes(
 	conversationRepo repository.ConversationRepositoryInterface,
 	conversationMembersRepo repository.ConversationMemberRepositoryInterface,
 	notificationRepo repository.NotificationRepositoryInterface,
+	linkPreviews *services.LinkPreviewService,
+	contentFilter *services.ContentFilterService,
+	blocks *services.BlockService,
+	groups *services.GroupService,
 ) {
 	// Create WebSocket handler
-	wsHandler := NewWebSocketHandler(messageRepo, conversationRepo, conversationMembersRepo, notificationRepo)
+	wsHandler := NewWebSocketHandler(messageRepo, conversationRepo, conversationMembersRepo, notificationRepo, linkPreviews, contentFilter, blocks, groups)
 
 	// WebSocket endpoint
 	router.Handle("/ws", middlewares.JWTMiddleware(http.HandlerFunc(wsHandler.HandleWebSocket))).Methods("GET")
@@ -6,7 +6,7 @@ import { useCookies } from "next-client-cookies";
 import { Button } from "@/components/ui/button";
 import SearchBar from "./SearchBar";
 import { Bell, MessageCircle, User, Users, Home } from "lucide-react";
-import { acceptFollowRequestNotif, declineFollowRequestNotif, acceptGroupJoinRequest, declineGroupJoinRequest } from "../../services/notifications";
+import { acceptFollowRequestNotif, declineFollowRequestNotif, acceptGroupInvitation, declineGroupInvitation, approveGroupJoinRequest, rejectGroupJoinRequest } from "../../services/notifications";
 import { getUserIdFromToken } from "../../services/user";
 import { useWebSocketClear } from "../context/WebSocketContext";
 
@@ -99,9 +99,9 @@ export default function Header({
 		}
 	};
 
-	const handleAcceptGroupRequest = async (referenceId: number, reqId:string, referenceType: string) => {
+	const handleAcceptGroupRequest = async (requestId: number) => {
 		try {
-			await acceptGroupJoinRequest(referenceId, parseInt(reqId), referenceType);
+			await approveGroupJoinRequest(requestId);
 			
 			// Refresh the page to update notifications
 			window.location.reload();
@@ -111,9 +111,9 @@ export default function Header({
 		}
 	};
 
-	const handleDeclineGroupRequest = async (referenceId: number, reqId:string, referenceType: string) => {
+	const handleDeclineGroupRequest = async (requestId: number) => {
 		try {
-			await declineGroupJoinRequest(referenceId, parseInt(reqId), referenceType);
+			await rejectGroupJoinRequest(requestId);
 			
 			// Refresh the page to update notifications
 			window.location.reload();
@@ -123,14 +123,9 @@ export default function Header({
 		}
 	};
 
-	const handleAcceptGroupInvitation = async (referenceId: number, referenceType: string) => {
+	const handleAcceptGroupInvitation = async (referenceId: number) => {
 		try {
-			const jwt = cookies.get("jwt");
-			const userId = await getUserIdFromToken(jwt);
-			
-			if (!userId) throw new Error("User not authenticated");
-			
-			await acceptGroupJoinRequest(referenceId, parseInt(userId), referenceType);
+			await acceptGroupInvitation(referenceId);
 			
 			// Refresh the page to update notifications
 			window.location.reload();
@@ -140,14 +135,9 @@ export default function Header({
 		}
 	};
 
-	const handleDeclineGroupInvitation = async (referenceId: number, referenceType: string) => {
+	const handleDeclineGroupInvitation = async (referenceId: number) => {
 		try {
-			const jwt = cookies.get("jwt");
-			const userId = await getUserIdFromToken(jwt);
-			
-			if (!userId) throw new Error("User not authenticated");
-			
-			await declineGroupJoinRequest(referenceId, parseInt(userId), referenceType);
+			await declineGroupInvitation(referenceId);
 			
 			// Refresh the page to update notifications
 			window.location.reload();
@@ -296,7 +286,7 @@ export default function Header({
 							<div className="space-y-3">
 								{notifications.map((notification) => (
 									<div key={notification.id} className="p-3 bg-zinc-800 rounded-lg border border-zinc-700">
-										{notification.type === 'group_request' ? <p className="text-sm text-zinc-300 mb-2">{notification.content.split(';')[0]}</p> : <p className="text-sm text-zinc-300 mb-2">{notification.content}</p>}
+										<p className="text-sm text-zinc-300 mb-2">{notification.content}</p>
 										
 										{/* Follow request buttons */}
 										{notification.type === 'follow_request' && notification.reference_id && (
@@ -323,14 +313,14 @@ export default function Header({
 											<div className="flex space-x-2 mt-2">
 												<Button
 													size="sm"
-													onClick={() => handleAcceptGroupInvitation(notification.reference_id!, notification.reference_type!)}
+													onClick={() => handleAcceptGroupInvitation(notification.reference_id!)}
 													className="bg-green-600 hover:bg-green-700 text-white text-xs px-3 py-1"
 												>
 													Accept
 												</Button>
 												<Button
 													size="sm"
-													onClick={() => handleDeclineGroupInvitation(notification.reference_id!, notification.reference_type!)}
+													onClick={() => handleDeclineGroupInvitation(notification.reference_id!)}
 													className="bg-red-600 hover:bg-red-700 text-white text-xs px-3 py-1"
 												>
 													Decline
@@ -342,14 +332,14 @@ export default function Header({
                                 <div className="mt-2">
                                    <Button
 													size="sm"
-													onClick={() => handleAcceptGroupRequest(notification.reference_id!,notification.content.split(";")[1]!, notification.reference_type!)}
+													onClick={() => handleAcceptGroupRequest(notification.reference_id!)}
 													className="bg-green-600 hover:bg-green-700 text-white text-xs px-3 py-1"
 												>
 													Accept
 												</Button>
 												<Button
 													size="sm"
-													onClick={() => handleDeclineGroupRequest(notification.reference_id!,notification.content.split(";")[1]!, notification.reference_type!)}
+													onClick={() => handleDeclineGroupRequest(notification.reference_id!)}
 													className="bg-red-600 hover:bg-red-700 text-white text-xs px-3 py-1"
 												>
 													Decline
@@ -1,4 +1,4 @@
-import { acceptFollowRequestNotif, declineFollowRequestNotif, acceptGroupJoinRequest, declineGroupJoinRequest } from "../../services/notifications";
+import { acceptFollowRequestNotif, declineFollowRequestNotif, acceptGroupInvitation, declineGroupInvitation, approveGroupJoinRequest, rejectGroupJoinRequest } from "../../services/notifications";
 
 export default function Notifications({ notifications }: { notifications: any[] }) {
     return (
@@ -34,13 +34,13 @@ export default function Notifications({ notifications }: { notifications: any[]
                                 <p>{notif.content}</p>
                                 <div className="mt-2">
                                     <button
-                                        onClick={() => acceptGroupJoinRequest(notif.reference_id, notif.user_id, notif.reference_type)}
+                                        onClick={() => acceptGroupInvitation(notif.reference_id)}
                                         className="px-2 py-1 text-xs bg-blue-500 text-white rounded hover:bg-blue-600"
                                     >
                                         Rejoindre le groupe
                                     </button>
                                     <button
-                                        onClick={() => declineGroupJoinRequest(notif.reference_id, notif.user_id, notif.reference_type)}
+                                        onClick={() => declineGroupInvitation(notif.reference_id)}
                                         className="ml-2 px-2 py-1 text-xs bg-gray-300 text-gray-800 rounded hover:bg-gray-400"
                                     >
                                         Refuser l'invitation
@@ -51,16 +51,16 @@ export default function Notifications({ notifications }: { notifications: any[]
                             )}
                             {notif.type === "group_request" && (
                                 <>
-                                <p>{notif.content.split(";")[0]}</p>
+                                <p>{notif.content}</p>
                                 <div className="mt-2">
                                     <button
-                                        onClick={() => acceptGroupJoinRequest(notif.reference_id, notif.content.split(";")[1], notif.reference_type)}
+                                        onClick={() => approveGroupJoinRequest(notif.reference_id)}
                                         className="px-2 py-1 text-xs bg-blue-500 text-white rounded hover:bg-blue-600"
                                     >
                                         Accepter la demande
                                     </button>
                                     <button
-                                        onClick={() => declineGroupJoinRequest(notif.reference_id, notif.content.split(";")[1], notif.reference_type)}
+                                        onClick={() => rejectGroupJoinRequest(notif.reference_id)}
                                         className="ml-2 px-2 py-1 text-xs bg-gray-300 text-gray-800 rounded hover:bg-gray-400"
                                     >
                                         Refuser la demande
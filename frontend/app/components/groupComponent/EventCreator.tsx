@@ -3,7 +3,7 @@
 import { useState } from "react";
 
 interface EventCreatorProps {
-	onCreateEvent: (event: { title: string; description: string; event_date: string }) => Promise<void>;
+	onCreateEvent: (event: { title: string; description: string; event_date: string; recurrence: string }) => Promise<void>;
 }
 
 export default function EventCreator({ onCreateEvent }: EventCreatorProps) {
@@ -11,13 +11,14 @@ export default function EventCreator({ onCreateEvent }: EventCreatorProps) {
 		title: "",
 		description: "",
 		event_date: "",
+		recurrence: "",
 	});
 
 	const handleCreate = async () => {
 		if (!newEvent.title.trim() || !newEvent.event_date.trim()) return;
 
 		await onCreateEvent(newEvent);
-		setNewEvent({ title: "", description: "", event_date: "" });
+		setNewEvent({ title: "", description: "", event_date: "", recurrence: "" });
 	};
 
 	return (
@@ -43,6 +44,17 @@ export default function EventCreator({ onCreateEvent }: EventCreatorProps) {
 				onChange={(e) => setNewEvent({ ...newEvent, event_date: e.target.value })}
 				className="w-full bg-zinc-900 border border-zinc-600 p-3 rounded-lg text-white focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent"
 			/>
+			{/* Une série hebdomadaire se répète le jour de la première date */}
+			<select
+				value={newEvent.recurrence}
+				onChange={(e) => setNewEvent({ ...newEvent, recurrence: e.target.value })}
+				className="w-full bg-zinc-900 border border-zinc-600 p-3 rounded-lg text-white focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-transparent"
+			>
+				<option value="">Ne se répète pas</option>
+				<option value="FREQ=DAILY">Tous les jours</option>
+				<option value="FREQ=WEEKLY">Toutes les semaines</option>
+				<option value="FREQ=MONTHLY">Tous les mois</option>
+			</select>
 			<button
 				onClick={handleCreate}
 				className="w-full bg-blue-600 text-white px-4 py-3 rounded-lg hover:bg-blue-700 font-medium transition-colors"
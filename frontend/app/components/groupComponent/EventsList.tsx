@@ -5,8 +5,8 @@ import { EventWithResponses, User } from "../../types/group";
 interface EventsListProps {
 	events: EventWithResponses[];
 	currentUser: User | null;
-	onEventResponse: (eventId: number, status: string) => Promise<void>;
-	onDeleteEvent: (eventId: number) => Promise<void>;
+	onEventResponse: (event: EventWithResponses, status: string) => Promise<void>;
+	onDeleteEvent: (event: EventWithResponses) => Promise<void>;
 }
 
 export default function EventsList({
@@ -32,25 +32,56 @@ export default function EventsList({
 				<p className="text-zinc-400">Aucun événement pour le moment.</p>
 			) : (
 				events.map((event) => (
-					<div key={event.id} className="border border-zinc-700 p-4 rounded-lg bg-zinc-800">
+					<div key={`${event.id}-${event.occurrence_date ?? ""}`} className="border border-zinc-700 p-4 rounded-lg bg-zinc-800">
 						<h4 className="font-semibold text-blue-400 mb-2">{event.title}</h4>
-						<p className="text-sm text-zinc-300 mb-1">📅 {formatDate(event.event_date)}</p>
+						<p className="text-sm text-zinc-300 mb-1">
+							📅 {formatDate(event.event_date)}
+							{event.series_id && <span className="ml-2 text-xs text-zinc-400">🔁 Événement récurrent</span>}
+						</p>
+						{event.location && <p className="text-sm text-zinc-300 mb-1">📍 {event.location}</p>}
+						{event.capacity && (
+							<p className="text-sm text-zinc-300 mb-1">
+								👥 {event.attendee_count ?? 0} / {event.capacity} places
+								{(event.waitlist_count ?? 0) > 0 && ` · ${event.waitlist_count} en liste d'attente`}
+							</p>
+						)}
 						<p className="text-zinc-100 mb-3">{event.description}</p>
+
+						{event.cancelled_at && (
+							<p className="text-sm text-red-300 bg-red-900/20 border border-red-800/50 rounded p-2 mb-4">
+								Événement annulé{event.cancel_reason ? ` : ${event.cancel_reason}` : ""}
+							</p>
+						)}
 						
 						{/* Boutons de réponse */}
+						{!event.cancelled_at && (
 						<div className="flex flex-wrap gap-2 items-center mb-4">
 							<button
-								onClick={() => onEventResponse(event.id, 'going')}
+								onClick={() => onEventResponse(event, 'going')}
 								className={`px-3 py-1.5 rounded text-sm font-medium transition-colors flex-shrink-0 ${
 									event.user_response_status === 'going'
 										? 'bg-green-700 text-white border-2 border-green-400'
 										: 'bg-green-600 text-white hover:bg-green-700'
 								}`}
 							>
-								{event.user_response_status === 'going' ? '✓ Je participe' : 'Participer'}
+								{event.user_response_status === 'going'
+									? '✓ Je participe'
+									: event.user_response_status === 'waitlisted'
+										? "⏳ En liste d'attente"
+										: 'Participer'}
+							</button>
+							<button
+								onClick={() => onEventResponse(event, 'maybe')}
+								className={`px-3 py-1.5 rounded text-sm font-medium transition-colors flex-shrink-0 ${
+									event.user_response_status === 'maybe'
+										? 'bg-yellow-700 text-white border-2 border-yellow-400'
+										: 'bg-yellow-600 text-white hover:bg-yellow-700'
+								}`}
+							>
+								{event.user_response_status === 'maybe' ? '? Peut-être' : 'Peut-être'}
 							</button>
 							<button
-								onClick={() => onEventResponse(event.id, 'not_going')}
+								onClick={() => onEventResponse(event, 'not_going')}
 								className={`px-3 py-1.5 rounded text-sm font-medium transition-colors flex-shrink-0 ${
 									event.user_response_status === 'not_going'
 										? 'bg-red-700 text-white border-2 border-red-400'
@@ -61,13 +92,14 @@ export default function EventsList({
 							</button>
 							{currentUser?.id === event.creator_id && (
 								<button
-									onClick={() => onDeleteEvent(event.id)}
+									onClick={() => onDeleteEvent(event)}
 									className="bg-zinc-600 text-zinc-200 px-3 py-1.5 rounded text-sm hover:bg-zinc-500 font-medium transition-colors flex-shrink-0"
 								>
-									Supprimer
+									Annuler
 								</button>
 							)}
 						</div>
+						)}
 
 						{/* Affichage des participants */}
 						<div className="space-y-3">
@@ -107,7 +139,43 @@ export default function EventsList({
 								</div>
 							)}
 
-							{(!event.participants || event.participants.length === 0) && (!event.non_participants || event.non_participants.length === 0) && (
+							{event.maybe_participants && event.maybe_participants.length > 0 && (
+								<div className="bg-yellow-900/20 p-3 rounded-lg border border-yellow-800/50 transition-all duration-300">
+									<h5 className="text-yellow-400 font-medium mb-2">
+										🟡 Peut-être ({event.maybe_participants.length})
+									</h5>
+									<div className="flex flex-wrap gap-2">
+										{event.maybe_participants.map((participant) => (
+											<span
+												key={participant.user_id}
+												className="bg-yellow-800/50 text-yellow-200 px-2 py-1 rounded-full text-xs animate-in fade-in-0 duration-300"
+											>
+												{participant.username}
+											</span>
+										))}
+									</div>
+								</div>
+							)}
+
+							{event.waitlist && event.waitlist.length > 0 && (
+								<div className="bg-zinc-900/50 p-3 rounded-lg border border-zinc-700/50 transition-all duration-300">
+									<h5 className="text-zinc-300 font-medium mb-2">
+										⏳ Liste d'attente ({event.waitlist.length})
+									</h5>
+									<div className="flex flex-wrap gap-2">
+										{event.waitlist.map((participant, index) => (
+											<span
+												key={participant.user_id}
+												className="bg-zinc-700/50 text-zinc-200 px-2 py-1 rounded-full text-xs animate-in fade-in-0 duration-300"
+											>
+												{index + 1}. {participant.username}
+											</span>
+										))}
+									</div>
+								</div>
+							)}
+
+							{(!event.participants || event.participants.length === 0) && (!event.non_participants || event.non_participants.length === 0) && (!event.maybe_participants || event.maybe_participants.length === 0) && (!event.waitlist || event.waitlist.length === 0) && (
 								<div className="bg-zinc-900/50 p-3 rounded-lg border border-zinc-700/50">
 									<p className="text-zinc-400 text-sm">Aucuns participants ou refus de participation</p>
 								</div>
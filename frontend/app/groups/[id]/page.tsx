@@ -14,7 +14,9 @@ import PostsList from "../../components/groupComponent/PostsList";
 import TabNavigation from "../../components/groupComponent/TabNavigation";
 import { useGroupData } from "../../hooks/useGroupData";
 import { useGroupWebSocket } from "../../hooks/useGroupWebSocket";
-import { createNotification, DeleteNotifications } from "../../../services/notifications";
+import { createNotification } from "../../../services/notifications";
+import { createGroupJoinRequest, withdrawGroupJoinRequest } from "../../../services/group";
+import { eventPath, isSameEvent } from "../../../services/utils";
 import { Users, Lock, Clock } from "lucide-react";
 import {
 	Group,
@@ -24,6 +26,7 @@ import {
 	GroupPost,
 	GroupComment,
 	EventWithResponses,
+	EventResponseStatus,
 	User
 } from "../../types/group";
 import { Button } from "@/components/ui/button";
@@ -81,7 +84,7 @@ export default function GroupPage() {
 		setError
 	});
 
-	useGroupWebSocket(id as string, setMessages, setEvents);
+	useGroupWebSocket(id as string, setMessages, setEvents, fetchEvents);
 
 	// Récupération de l'utilisateur actuel
 	useEffect(() => {
@@ -232,15 +235,10 @@ export default function GroupPage() {
 	const joinRequest = async () => {
 			try {
 				if (!currentUser) return;
-				const notif = await createNotification({
-					userId: currentUser.id,
-					type: "group_request",
-					content: `${currentUser?.username} demande à rejoindre le groupe`,
-					referenceId: Number(id),
-					referenceType: "group",
-				});
-				console.log(notif.id)
-				setRequestPending(notif.id)
+				const request = await createGroupJoinRequest(Number(id));
+				// Les groupes publics acceptent la demande immédiatement
+				if (request.status === "approved") setIsMember(true)
+				else setRequestPending(request.id)
 			} catch (err: any) {
 				alert(`Erreur lors de la création de la notification : ${err.message}`);
 			}
@@ -250,7 +248,7 @@ export default function GroupPage() {
 	const deleteRequest = async () => {
 			try {
 				if (!currentUser) return;
-				if (await DeleteNotifications(RequestPending)) setRequestPending(null)
+				if (RequestPending && await withdrawGroupJoinRequest(RequestPending)) setRequestPending(null)
 			} catch (err: any) {
 				alert(`Erreur lors de la création de la notification : ${err.message}`);
 			}
@@ -355,11 +353,13 @@ export default function GroupPage() {
 	};
 
 	// Actions pour les événements
-	const createEvent = async (eventData: { title: string; description: string; event_date: string }) => {
+	const createEvent = async (eventData: { title: string; description: string; event_date: string; recurrence: string }) => {
 		try {
 			const formattedEvent = {
 				...eventData,
-				event_date: new Date(eventData.event_date).toISOString()
+				event_date: new Date(eventData.event_date).toISOString(),
+				// Une série se répète à la même heure locale, changements d'heure compris
+				time_zone: Intl.DateTimeFormat().resolvedOptions().timeZone
 			};
 
 			const res = await fetch(`http://localhost:8080/api/groups/${id}/events`, {
@@ -389,9 +389,9 @@ export default function GroupPage() {
 		}
 	};
 
-	const handleEventResponse = async (eventId: number, status: string) => {
+	const handleEventResponse = async (target: EventWithResponses, status: string) => {
 		try {
-			const res = await fetch(`http://localhost:8080/api/events/${eventId}/response`, {
+			const res = await fetch(eventPath(target, "/response"), {
 				method: "POST",
 				headers: { "Content-Type": "application/json" },
 				credentials: "include",
@@ -399,10 +399,14 @@ export default function GroupPage() {
 			});
 			if (!res.ok) throw new Error(await res.text());
 
+			// Le serveur renvoie "waitlisted" si l'événement est complet
+			// Une occurrence reçoit son propre identifiant à la première réponse
+			const saved: { event_id: number; status: EventResponseStatus } = await res.json();
+
 			// Update local state immediately for the current user
 			setEvents((prev) => prev.map(event => 
-				event.id === eventId 
-					? { ...event, user_response_status: status as "going" | "not_going" }
+				isSameEvent(event, target)
+					? { ...event, id: saved.event_id, user_response_status: saved.status }
 					: event
 			));
 
@@ -413,19 +417,21 @@ export default function GroupPage() {
 		}
 	};
 
-	const deleteEvent = async (eventId: number) => {
+	const deleteEvent = async (target: EventWithResponses) => {
 		try {
-			const res = await fetch(`http://localhost:8080/api/events/${eventId}`, {
+			const res = await fetch(eventPath(target), {
 				method: "DELETE",
 				credentials: "include",
 			});
 			if (!res.ok) throw new Error(await res.text());
 
-			setEvents((prev) => prev.filter((event) => event.id !== eventId));
-			console.log("Événement supprimé avec succès !");
+			// L'événement est annulé, pas supprimé : il reste affiché
+			const cancelled = await res.json();
+			setEvents((prev) => prev.map((event) => (isSameEvent(event, target) ? { ...event, ...cancelled } : event)));
+			console.log("Événement annulé avec succès !");
 		} catch (err: any) {
-			console.error("Error deleting event:", err.message);
-			alert(`Erreur lors de la suppression de l'événement : ${err.message}`);
+			console.error("Error cancelling event:", err.message);
+			alert(`Erreur lors de l'annulation de l'événement : ${err.message}`);
 		}
 	};
 
@@ -117,7 +117,11 @@ export const useGroupData = (groupId: string, currentUser: CurrentUser | null, s
 			});
 			if (!res.ok) throw new Error(await res.text());
 			const data = await res.json();
-			setPosts(Array.isArray(data) ? data : []);
+			// Les posts de groupe ont le même format que le fil principal
+			const posts = Array.isArray(data)
+				? data.map((item: any) => ({ ...item.post, username: item.user?.username }))
+				: [];
+			setPosts(posts);
 		} catch (err: any) {
 			console.error("Error fetching posts:", err.message);
 		} finally {
@@ -152,7 +156,7 @@ export const useGroupData = (groupId: string, currentUser: CurrentUser | null, s
 			const data = await res.json();
 			setCommentsByPost((prev) => ({
 				...prev,
-				[postId]: Array.isArray(data) ? data : [],
+				[postId]: Array.isArray(data?.comments) ? data.comments : [],
 			}));
 		} catch (err: any) {
 			console.error("Error fetching comments:", err.message);
@@ -1,10 +1,12 @@
 import { useEffect } from "react";
 import { GroupMessage, EventWithResponses } from "../types/group";
+import { isSameEvent } from "../../services/utils";
 
 export const useGroupWebSocket = (
 	groupId: string,
 	setMessages: (messages: GroupMessage[] | ((prev: GroupMessage[]) => GroupMessage[])) => void,
-	setEvents?: (events: EventWithResponses[] | ((prev: EventWithResponses[]) => EventWithResponses[])) => void
+	setEvents?: (events: EventWithResponses[] | ((prev: EventWithResponses[]) => EventWithResponses[])) => void,
+	refreshEvents?: () => void
 ) => {
 	useEffect(() => {
 		if (!groupId) return;
@@ -12,10 +14,12 @@ export const useGroupWebSocket = (
 		let socket: WebSocket;
 
 		const connectWebSocket = () => {
-			socket = new WebSocket(`ws://localhost:8080/ws/groups?groupId=${groupId}`);
+			socket = new WebSocket(`ws://localhost:8080/ws`);
 
 			socket.onopen = () => {
 				console.log("WebSocket group connected ✅");
+				// Subscribe to the updates of the group, refused to non-members
+				socket.send(JSON.stringify({ type: "room_join", room: `group:${groupId}` }));
 			};
 
 			socket.onmessage = (event) => {
@@ -24,18 +28,18 @@ export const useGroupWebSocket = (
 					console.log("WebSocket message received:", data);
 					
 					// Handle different message types
-					if (data.type === "event_response_update" && setEvents) {
-						console.log("Updating event:", data.event);
+					if ((data.type === "event_response_update" || data.type === "event_updated" || data.type === "event_cancelled") && setEvents) {
+						console.log("Updating event:", data.data);
 						// Update the specific event with new participant data
 						// Preserve each user's own response status
 						setEvents((prev) => {
 							const safeEvents = Array.isArray(prev) ? prev : [];
 							const updated = safeEvents.map(existingEvent => {
-								if (existingEvent.id === data.event.id) {
+								if (isSameEvent(existingEvent, data.data)) {
 									// Preserve the current user's response status
 									const currentUserResponseStatus = existingEvent.user_response_status;
 									return {
-										...data.event,
+										...data.data,
 										user_response_status: currentUserResponseStatus
 									};
 								}
@@ -44,9 +48,12 @@ export const useGroupWebSocket = (
 							console.log("Events updated:", updated);
 							return updated;
 						});
-					} else {
+					} else if (data.type === "event_series_updated" && refreshEvents) {
+						// Les occurrences d'une série sont recalculées par le serveur
+						refreshEvents();
+					} else if (data.type === "group_message") {
 						// Handle regular group messages
-						const newMsg = data;
+						const newMsg: GroupMessage = data.data;
 						setMessages((prev) => {
 							const safePrev = Array.isArray(prev) ? prev : [];
 							if (safePrev.some((msg) => msg.id === newMsg.id)) {
@@ -53,7 +53,7 @@ export type GroupPost = {
 
 export type GroupComment = {
 	id: number;
-	group_post_id: number;
+	post_id: number;
 	user_id: number;
 	username: string;
 	content: string;
@@ -68,12 +68,35 @@ export type Event = {
 	title: string;
 	description: string;
 	event_date: string;
+	location?: string;
+	capacity?: number | null;
 	created_at: string;
 	updated_at: string;
+	cancelled_at?: string | null;
+	cancelled_by?: number | null;
+	cancel_reason?: string;
+	recurrence?: string;
+	series_id?: number | null;
+	occurrence_date?: string | null;
+	time_zone?: string;
 };
 
+export type EventChange = {
+	id: number;
+	event_id: number;
+	changed_by: number | null;
+	username?: string;
+	field: string;
+	old_value: string;
+	new_value: string;
+	reason?: string;
+	changed_at: string;
+};
+
+export type EventResponseStatus = "going" | "maybe" | "not_going" | "waitlisted";
+
 export interface EventWithResponse extends Event {
-	response_status?: "going" | "not_going" | null;
+	response_status?: EventResponseStatus | null;
 }
 
 export type EventParticipant = {
@@ -88,11 +111,24 @@ export type EventWithResponses = {
 	title: string;
 	description: string;
 	event_date: string;
+	location?: string;
+	capacity?: number | null;
 	created_at: string;
 	updated_at: string;
-	user_response_status?: "going" | "not_going" | null;
+	cancelled_at?: string | null;
+	cancelled_by?: number | null;
+	cancel_reason?: string;
+	recurrence?: string;
+	series_id?: number | null;
+	occurrence_date?: string | null;
+	time_zone?: string;
+	user_response_status?: EventResponseStatus | null;
 	participants: EventParticipant[] | null;
 	non_participants: EventParticipant[] | null;
+	maybe_participants?: EventParticipant[] | null;
+	waitlist?: EventParticipant[] | null;
+	attendee_count?: number;
+	waitlist_count?: number;
 };
 
 export type User = {
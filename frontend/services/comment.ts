@@ -11,25 +11,36 @@ export interface Comment {
     author:any;
 }
 
+// Taille maximale d'une page de commentaires acceptée par l'API
+const commentsPageSize = 100;
+
 export async function getComments(postId: number, jwt?: string): Promise<Comment[]> {
     let comments: Comment[] = [];
 
     try {
-        const resp = await fetch(`${url}/comments/${postId}`, {
-            method: "POST",
-            body: JSON.stringify({
-                jwt: jwt,
-                post_id: postId
-            })
-        });
+        // Les commentaires sont paginés : on suit le curseur jusqu'à la dernière page
+        let cursor: string | undefined;
+        do {
+            const resp = await fetch(`${url}/comments/${postId}`, {
+                method: "POST",
+                body: JSON.stringify({
+                    jwt: jwt,
+                    post_id: postId,
+                    cursor: cursor,
+                    limit: commentsPageSize
+                })
+            });
+            if (!resp.ok) {
+                break;
+            }
 
-        if (resp.ok) {
-            const commentsData = await resp.json();
+            const page = await resp.json();
+            const commentsData = page?.comments;
             if (!commentsData) {
-                return comments
+                break;
             }
-            
-            comments = commentsData.map((comment: any) => ({
+
+            comments = comments.concat(commentsData.map((comment: any) => ({
                 id: comment.id,
                 postId: comment.post_id,
                 userId: comment.user_id,
@@ -38,8 +49,9 @@ export async function getComments(postId: number, jwt?: string): Promise<Comment
                 content: comment.content,
                 imageUrl: comment.image_path,
                 createdAt: new Date(Date.parse(comment.created_at))
-            }));
-        }
+            })));
+            cursor = page.has_more ? page.next_cursor : undefined;
+        } while (cursor);
     } catch (error) {
         console.error("Failed to fetch comments:", error);
     }
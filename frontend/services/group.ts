@@ -18,3 +18,443 @@ export async function checkGroupInvitationStatus(groupId: number, userId: number
 
   return await res.json();
 }
+
+// Ask to join a group, returns the pending request
+export async function createGroupJoinRequest(
+  groupId: number,
+  message = "",
+  answers: { question_id: number; answer: string }[] = []
+) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/join-requests`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    credentials: "include",
+    body: JSON.stringify({ message, answers }),
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Withdraw a pending join request
+export async function withdrawGroupJoinRequest(requestId: number) {
+  const res = await fetch(`http://localhost:8080/api/group-join-requests/${requestId}`, {
+    method: "DELETE",
+    credentials: "include",
+  });
+  return res.ok;
+}
+
+// Generate a shareable invite link, optionally limited in uses and time
+export async function createGroupInviteLink(
+  groupId: number,
+  options: { role?: "admin" | "moderator" | "member"; max_uses?: number; expires_at?: string } = {}
+) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/invite-links`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    credentials: "include",
+    body: JSON.stringify(options),
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// List the invite links of a group, revoked ones included
+export async function getGroupInviteLinks(groupId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/invite-links`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Revoke an invite link
+export async function revokeGroupInviteLink(groupId: number, linkId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/invite-links/${linkId}`, {
+    method: "DELETE",
+    credentials: "include",
+  });
+  return res.ok;
+}
+
+// List who joined through an invite link
+export async function getGroupInviteLinkUses(groupId: number, linkId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/invite-links/${linkId}/uses`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Preview the group an invite link leads to
+export async function previewGroupInviteLink(token: string) {
+  const res = await fetch(`http://localhost:8080/api/invite-links/${encodeURIComponent(token)}`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Join a group through an invite link, returns the group and the role granted
+export async function joinGroupByInviteLink(token: string) {
+  const res = await fetch(`http://localhost:8080/api/invite-links/${encodeURIComponent(token)}/join`, {
+    method: "POST",
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Pin or unpin a post on top of the group posts
+export async function setGroupPostPinned(groupId: number, postId: number, pinned: boolean) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/posts/${postId}/pin`, {
+    method: pinned ? "POST" : "DELETE",
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+}
+
+// Mark or unmark a post as an announcement, members are notified when marked
+export async function setGroupPostAnnouncement(groupId: number, postId: number, announcement: boolean) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/posts/${postId}/announcement`, {
+    method: announcement ? "POST" : "DELETE",
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+}
+
+// Record that the current user has read an announcement
+export async function acknowledgeGroupAnnouncement(groupId: number, postId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/posts/${postId}/acknowledge`, {
+    method: "POST",
+    credentials: "include",
+  });
+  return res.ok;
+}
+
+// List who has read an announcement
+export async function getGroupAnnouncementAcknowledgements(groupId: number, postId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/posts/${postId}/acknowledgements`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Read a page of the group audit log, for owners and admins
+export async function getGroupAuditLog(
+  groupId: number,
+  filters: {
+    action?: string;
+    actor_id?: number;
+    target_type?: string;
+    target_id?: number;
+    since?: string;
+    until?: string;
+    limit?: number;
+    offset?: number;
+  } = {}
+) {
+  const params = new URLSearchParams();
+  Object.entries(filters).forEach(([key, value]) => {
+    if (value !== undefined && value !== "") params.set(key, String(value));
+  });
+
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/audit-log?${params}`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Transfer the group to another member, the current owner becomes an admin
+export async function transferGroupOwnership(groupId: number, userId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/transfer`, {
+    method: "POST",
+    credentials: "include",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ user_id: userId }),
+  });
+  return res.ok;
+}
+
+// Archive the group, making it read-only, or open it again
+export async function setGroupArchived(groupId: number, archived: boolean) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/archive`, {
+    method: archived ? "POST" : "DELETE",
+    credentials: "include",
+  });
+  return res.ok;
+}
+
+// Delete the group, it can be restored until the returned restore_until
+export async function deleteGroup(groupId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}`, {
+    method: "DELETE",
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Restore a deleted group within its restore window
+export async function restoreGroup(groupId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/restore`, {
+    method: "POST",
+    credentials: "include",
+  });
+  return res.ok;
+}
+
+// List the deleted groups the current user can still restore
+export async function getDeletedGroups() {
+  const res = await fetch(`http://localhost:8080/api/groups/deleted`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Browse the group directory, filtered by search, category and tag
+export async function discoverGroups(
+  filters: {
+    q?: string;
+    category?: string;
+    tag?: string;
+    sort?: "activity" | "size" | "newest";
+    limit?: number;
+    offset?: number;
+  } = {}
+) {
+  const params = new URLSearchParams();
+  Object.entries(filters).forEach(([key, value]) => {
+    if (value !== undefined && value !== "") params.set(key, String(value));
+  });
+
+  const res = await fetch(`http://localhost:8080/api/groups/discover?${params}`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Groups the friends of the current user belong to
+export async function getRecommendedGroups(limit = 10) {
+  const res = await fetch(`http://localhost:8080/api/groups/recommended?limit=${limit}`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// List the categories a group can have
+export async function getGroupCategories(): Promise<string[]> {
+  const res = await fetch(`http://localhost:8080/api/groups/categories`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Change the category, cover image and tags of a group
+export async function updateGroupProfile(
+  groupId: number,
+  profile: { category: string; cover_path: string; tags: string[] }
+) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/profile`, {
+    method: "PUT",
+    credentials: "include",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(profile),
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// List the questions asked to the users requesting to join the group
+export async function getGroupQuestions(groupId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/questions`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Replace the membership questions of the group
+export async function setGroupQuestions(groupId: number, questions: { question: string; required: boolean }[]) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/questions`, {
+    method: "PUT",
+    credentials: "include",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ questions }),
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Read the group rules, must_accept tells whether the current user has to accept them
+export async function getGroupRules(groupId: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/rules`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Change the group rules, members are asked to accept the new version
+export async function setGroupRules(groupId: number, rules: string) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/rules`, {
+    method: "PUT",
+    credentials: "include",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ rules }),
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Accept the version of the rules the member has read
+export async function acceptGroupRules(groupId: number, version: number) {
+  const res = await fetch(`http://localhost:8080/api/groups/${groupId}/rules/accept`, {
+    method: "POST",
+    credentials: "include",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ version }),
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Edit the title, description, date or location of an event
+export async function updateEvent(
+  eventId: number,
+  changes: { title?: string; description?: string; event_date?: string; location?: string; reason?: string }
+) {
+  const res = await fetch(`http://localhost:8080/api/events/${eventId}`, {
+    method: "PUT",
+    credentials: "include",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(changes),
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Cancel an event; attendees are notified with the reason
+export async function cancelEvent(eventId: number, reason = "") {
+  const res = await fetch(`http://localhost:8080/api/events/${eventId}/cancel`, {
+    method: "POST",
+    credentials: "include",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ reason }),
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
+
+// Change history of an event, oldest first
+export async function getEventHistory(eventId: number) {
+  const res = await fetch(`http://localhost:8080/api/events/${eventId}/history`, {
+    credentials: "include",
+  });
+
+  if (!res.ok) {
+    throw new Error(await res.text());
+  }
+
+  return await res.json();
+}
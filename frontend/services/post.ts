@@ -250,6 +250,7 @@ export async function getLikedPostsByUserID(userID: number): Promise<Post[]> {
     const resp = await fetch(url + "/liked_posts", {
       method: "POST",
       headers: { "Content-Type": "application/json" },
+      credentials: "include",
       body: JSON.stringify({
         user_id: userID
       })
@@ -18,6 +18,8 @@ export interface UserProfile {
     about_me: string;
     is_public: boolean;
     avatar_path: string;
+    // Private profile seen by a non-follower: the personal fields are absent
+    restricted?: boolean;
     friends: Friend[];
 }
 
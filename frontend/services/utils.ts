@@ -14,4 +14,20 @@ export const formatRelativeTime = (date: Date, now: Date = new Date()) => {
     if (diffInDays < 30) return `${diffInDays}d ago`;
 
     return date.toLocaleDateString();
-};
\ No newline at end of file
+};
+
+type EventRef = { id: number; series_id?: number | null; occurrence_date?: string | null };
+
+// API path of an event; occurrences of a series go through the series
+export const eventPath = (event: EventRef, action = "") => {
+    const base = `http://localhost:8080/api/events/${event.series_id ?? event.id}${action}`;
+    return event.series_id && event.occurrence_date
+        ? `${base}?occurrence=${encodeURIComponent(event.occurrence_date)}`
+        : base;
+};
+
+// Occurrences not stored yet share the ID of their series
+export const isSameEvent = (a: EventRef, b: EventRef) =>
+    a.series_id && b.series_id
+        ? a.series_id === b.series_id && a.occurrence_date === b.occurrence_date
+        : a.id === b.id;